@@ -0,0 +1,185 @@
+// This program runs the administrative tasks that cannot be done from the web
+// admin console, like creating the first administrator.
+//
+// Usage:
+//
+//	admin [flags] invite
+//	admin [flags] promote <email>
+//	admin [flags] demote <email>
+//	admin [flags] export <email> <file>
+//	admin [flags] import <email> <file>
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/garnizeH/dimdim/internal/web"
+	"github.com/garnizeH/dimdim/pkg/domain"
+	"github.com/garnizeH/dimdim/service/backup"
+	"github.com/garnizeH/dimdim/service/invite"
+	"github.com/garnizeH/dimdim/storage"
+	"github.com/garnizeH/dimdim/storage/datastore"
+
+	"github.com/ardanlabs/conf/v3"
+)
+
+const prefix = "DIMDIM"
+
+type config struct {
+	Args conf.Args
+	Web  struct {
+		DomainName string `conf:"default:localhost"`
+		Port       string `conf:"default:3000"`
+	}
+	DBApp struct {
+		DSN string `conf:"default:tmp/data/app.db"`
+	}
+	DBSessions struct {
+		DSN string `conf:"default:tmp/data/sessions.db"`
+	}
+	Invite struct {
+		Email    string        `conf:"flag:email"`
+		Lifetime time.Duration `conf:"default:168h,flag:lifetime"`
+	}
+}
+
+func main() {
+	if err := run(context.Background()); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func run(ctx context.Context) error {
+	cfg := config{}
+
+	help, err := conf.Parse(prefix, &cfg)
+	if err != nil {
+		if errors.Is(err, conf.ErrHelpWanted) {
+			fmt.Println(help)
+			return nil
+		}
+		return fmt.Errorf("failed to parse config: %w", err)
+	}
+
+	db, err := storage.NewDB(cfg.DBApp.DSN, datastore.Migrations, datastore.Factory)
+	if err != nil {
+		return fmt.Errorf("failed to connect to the database: %w", err)
+	}
+	defer db.Close()
+
+	switch cmd := cfg.Args.Num(0); cmd {
+	case "invite":
+		return createInvite(ctx, cfg, db)
+	case "promote":
+		return setAdmin(ctx, db, cfg.Args.Num(1), true)
+	case "demote":
+		if err := setAdmin(ctx, db, cfg.Args.Num(1), false); err != nil {
+			return err
+		}
+
+		// The admin pages check the role in the database, and the user is
+		// signed out so the rest of the session data is read again too.
+		if err := web.RevokeUserSessions(ctx, cfg.DBSessions.DSN, cfg.Args.Num(1)); err != nil {
+			return fmt.Errorf("failed to sign the user out: %w", err)
+		}
+
+		return nil
+	case "export":
+		return exportBackup(ctx, db, cfg.Args.Num(1), cfg.Args.Num(2))
+	case "import":
+		return importBackup(ctx, db, cfg.Args.Num(1), cfg.Args.Num(2))
+	default:
+		return fmt.Errorf("unknown command %q, expected invite, promote, demote, export or import", cmd)
+	}
+}
+
+func createInvite(ctx context.Context, cfg config, db *storage.DB[datastore.Queries]) error {
+	inv, err := invite.New(db).CreateInvite(ctx, cfg.Invite.Email, cfg.Invite.Lifetime)
+	if err != nil {
+		return fmt.Errorf("failed to create the invite: %w", err)
+	}
+
+	d := domain.Domain(cfg.Web.DomainName)
+	if d.IsDev() {
+		d = domain.Domain(cfg.Web.DomainName + ":" + cfg.Web.Port)
+	}
+
+	fmt.Println("code:   ", inv.Code)
+	fmt.Println("url:    ", inv.URL(d.URL("")))
+	fmt.Println("expires:", inv.ExpiresAt.Format(time.RFC3339))
+
+	return nil
+}
+
+// setAdmin updates the database directly. The running application enforces the
+// role on the admin pages at once, and shows it elsewhere once the user cache
+// entry expires.
+func setAdmin(ctx context.Context, db *storage.DB[datastore.Queries], email string, admin bool) error {
+	if email == "" {
+		return errors.New("missing the user email")
+	}
+
+	return db.Write(ctx, func(queries *datastore.Queries) error {
+		n, err := queries.SetUserIsAdmin(ctx, datastore.SetUserIsAdminParams{
+			IsAdmin: admin,
+			Email:   email,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to update the user: %w", err)
+		}
+		if n == 0 {
+			return fmt.Errorf("user %q not found", email)
+		}
+
+		return nil
+	})
+}
+
+// exportBackup writes the backup of the user to the file.
+func exportBackup(ctx context.Context, db *storage.DB[datastore.Queries], email, filename string) error {
+	if email == "" || filename == "" {
+		return errors.New("missing the user email or the backup file")
+	}
+
+	var buf bytes.Buffer
+	if err := backup.New(db).Export(ctx, email, &buf); err != nil {
+		return fmt.Errorf("failed to export the backup: %w", err)
+	}
+
+	if err := os.WriteFile(filename, buf.Bytes(), 0o600); err != nil {
+		return fmt.Errorf("failed to write the backup file: %w", err)
+	}
+
+	return nil
+}
+
+// importBackup restores the backup file into the account of the user, which
+// must have no bills or payees. Like setAdmin, the running application picks
+// the imported preferences up once the user cache entry expires.
+func importBackup(ctx context.Context, db *storage.DB[datastore.Queries], email, filename string) error {
+	if email == "" || filename == "" {
+		return errors.New("missing the user email or the backup file")
+	}
+
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return fmt.Errorf("failed to read the backup file: %w", err)
+	}
+
+	summary, err := backup.New(db).Import(ctx, email, bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return fmt.Errorf("failed to import the backup: %w", err)
+	}
+
+	fmt.Println("payees: ", summary.Payees)
+	fmt.Println("aliases:", summary.Aliases)
+	fmt.Println("bills:  ", summary.Bills)
+
+	return nil
+}
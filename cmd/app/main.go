@@ -6,20 +6,22 @@ import (
 	"expvar"
 	"fmt"
 	"net/http"
-	"net/url"
 	"os"
 	"os/signal"
 	"runtime"
+	"sync"
 	"syscall"
 	"time"
 
 	"github.com/garnizeH/dimdim/internal/api/debug"
 	server "github.com/garnizeH/dimdim/internal/web"
 	"github.com/garnizeH/dimdim/pkg/argon2id"
+	"github.com/garnizeH/dimdim/pkg/domain"
 	"github.com/garnizeH/dimdim/pkg/logger"
 	"github.com/garnizeH/dimdim/pkg/mailer"
 	"github.com/garnizeH/dimdim/pkg/web"
 	"github.com/garnizeH/dimdim/service"
+	"github.com/garnizeH/dimdim/service/user"
 	"github.com/garnizeH/dimdim/storage"
 	"github.com/garnizeH/dimdim/storage/datastore"
 
@@ -81,6 +83,7 @@ func run(ctx context.Context, log *logger.Logger) error {
 			IdleTimeout        time.Duration `conf:"default:120s"`
 			ShutdownTimeout    time.Duration `conf:"default:20s"`
 			CORSAllowedOrigins []string      `conf:"default:*,mask"`
+			TrustedProxies     []string
 		}
 		Debug struct {
 			Host string `conf:"default:0.0.0.0:3010"`
@@ -92,10 +95,31 @@ func run(ctx context.Context, log *logger.Logger) error {
 			DSN string `conf:"default:tmp/data/sessions.db"`
 		}
 		Mailer struct {
-			Addr     string `conf:"default:localhost:1025"`
-			Identity string `conf:"default:dimdim@localhost"`
-			Username string `conf:"default:test"`
-			Password string `conf:"default:test"`
+			Transport   string `conf:"default:smtp"` // smtp, maildir or memory
+			Addr        string `conf:"default:localhost:1025"`
+			Identity    string `conf:"default:dimdim@localhost"`
+			Username    string `conf:"default:test"`
+			Password    string `conf:"default:test,mask"`
+			Security    string `conf:"default:auto"` // auto, starttls, tls or none
+			MaildirPath string `conf:"default:tmp/data/mails"`
+		}
+		Outbox struct {
+			Interval    time.Duration `conf:"default:30s"`
+			BatchSize   int           `conf:"default:50"`
+			MaxAttempts int64         `conf:"default:8"`
+			MinBackoff  time.Duration `conf:"default:1m"`
+			MaxBackoff  time.Duration `conf:"default:6h"`
+			Retention   time.Duration `conf:"default:720h"`
+		}
+		Users struct {
+			Registration     string        `conf:"default:open"` // open, invite or closed
+			ExportsDir       string        `conf:"default:tmp/data/exports"`
+			ExportLifetime   time.Duration `conf:"default:168h"`
+			PurgeGracePeriod time.Duration `conf:"default:720h"`
+			PurgeInterval    time.Duration `conf:"default:1h"`
+		}
+		Bills struct {
+			ReminderInterval time.Duration `conf:"default:1h"`
 		}
 		Argon struct {
 			Time    uint32 `conf:"default:4"`
@@ -157,37 +181,141 @@ func run(ctx context.Context, log *logger.Logger) error {
 	argon := argon2id.New(cfg.Argon.Time, cfg.Argon.SaltLen, cfg.Argon.Memory, cfg.Argon.Threads, cfg.Argon.KeyLen)
 
 	// -------------------------------------------------------------------------
-	// SMTP Support
+	// Mailer Support
+
+	log.Info(ctx, "startup", "status", "initializing mailer support", "transport", cfg.Mailer.Transport)
+
+	transport, err := mailer.NewTransport(mailer.TransportConfig{
+		Kind:        cfg.Mailer.Transport,
+		Addr:        cfg.Mailer.Addr,
+		Username:    cfg.Mailer.Username,
+		Password:    cfg.Mailer.Password,
+		Security:    cfg.Mailer.Security,
+		MaildirPath: cfg.Mailer.MaildirPath,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create the mailer transport: %w", err)
+	}
 
-	log.Info(ctx, "startup", "status", "initializing smtp support", "config", cfg.Mailer)
+	mailerClient := mailer.New(cfg.Mailer.Identity, transport)
 
-	u, err := url.Parse(cfg.Mailer.Addr)
+	// -------------------------------------------------------------------------
+	// Service Support
+
+	log.Info(ctx, "startup", "status", "initializing service support")
+
+	registration, err := user.ParseRegistration(cfg.Users.Registration)
 	if err != nil {
-		return fmt.Errorf("failed to parse the mailer address: %w", err)
+		return fmt.Errorf("failed to parse the users config: %w", err)
 	}
 
-	host := u.Hostname()
-	if host == "" {
-		host = u.Scheme
-	}
-	if host == "" {
-		return fmt.Errorf("failed to parse the mailer host: %q", cfg.Mailer.Addr)
+	serviceCfg := service.Config{
+		User: user.Config{
+			Registration:   registration,
+			ExportsDir:     cfg.Users.ExportsDir,
+			ExportLifetime: cfg.Users.ExportLifetime,
+		},
 	}
 
-	mailer := mailer.New(
-		cfg.Mailer.Addr,
-		host,
-		cfg.Mailer.Identity,
-		cfg.Mailer.Username,
-		cfg.Mailer.Password,
-	)
+	service := service.New(log, serviceCfg, argon, mailerClient, db)
 
 	// -------------------------------------------------------------------------
-	// Service Support
+	// Start Background Jobs
 
-	log.Info(ctx, "startup", "status", "initializing service support")
+	// The jobs are canceled and waited for before the database is closed.
+	var jobs sync.WaitGroup
+	jobsCtx, cancelJobs := context.WithCancel(ctx)
+	defer func() {
+		cancelJobs()
+		jobs.Wait()
+	}()
+
+	dispatcherCfg := mailer.DispatcherConfig{
+		Interval:    cfg.Outbox.Interval,
+		BatchSize:   cfg.Outbox.BatchSize,
+		MaxAttempts: cfg.Outbox.MaxAttempts,
+		MinBackoff:  cfg.Outbox.MinBackoff,
+		MaxBackoff:  cfg.Outbox.MaxBackoff,
+	}
+
+	dispatcher := mailer.NewDispatcher(log, dispatcherCfg, mailerClient, service.Outbox())
+
+	jobs.Add(1)
+	go func() {
+		defer jobs.Done()
+
+		log.Info(ctx, "startup", "status", "outbox dispatcher started", "config", cfg.Outbox)
+
+		dispatcher.Run(jobsCtx)
+	}()
+
+	jobs.Add(1)
+	go func() {
+		defer jobs.Done()
 
-	service := service.New(argon, mailer, db)
+		log.Info(ctx, "startup", "status", "purge job started", "interval", cfg.Users.PurgeInterval)
+
+		ticker := time.NewTicker(cfg.Users.PurgeInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-jobsCtx.Done():
+				return
+
+			case <-ticker.C:
+				deletedBefore := time.Now().Add(-cfg.Users.PurgeGracePeriod)
+				if err := service.User().PurgeDeletedUsers(jobsCtx, deletedBefore); err != nil {
+					log.Error(jobsCtx, "purge", "status", "failed to purge deleted users", "error", err)
+				}
+
+				if err := service.User().PurgeExpiredExports(jobsCtx); err != nil {
+					log.Error(jobsCtx, "purge", "status", "failed to purge expired exports", "error", err)
+				}
+
+				if err := service.Outbox().Purge(jobsCtx, time.Now().Add(-cfg.Outbox.Retention)); err != nil {
+					log.Error(jobsCtx, "purge", "status", "failed to purge the outbox", "error", err)
+				}
+			}
+		}
+	}()
+
+	jobs.Add(1)
+	go func() {
+		defer jobs.Done()
+
+		log.Info(ctx, "startup", "status", "bill reminders job started", "interval", cfg.Bills.ReminderInterval)
+
+		d := domain.Domain(cfg.Web.DomainName)
+		if d.IsDev() {
+			d = domain.Domain(cfg.Web.DomainName + ":" + cfg.Web.Port)
+		}
+		baseURL := d.URL("")
+
+		ticker := time.NewTicker(cfg.Bills.ReminderInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-jobsCtx.Done():
+				return
+
+			case <-ticker.C:
+				if err := service.Bill().SendReminders(jobsCtx, baseURL, time.Now()); err != nil {
+					log.Error(jobsCtx, "bills", "status", "failed to send the bill reminders", "error", err)
+				}
+			}
+		}
+	}()
+
+	jobs.Add(1)
+	go func() {
+		defer jobs.Done()
+
+		log.Info(ctx, "startup", "status", "exports job started")
+
+		service.User().RunExports(jobsCtx)
+	}()
 
 	// -------------------------------------------------------------------------
 	// Start Debug Service
@@ -209,6 +337,11 @@ func run(ctx context.Context, log *logger.Logger) error {
 	shutdown := make(chan os.Signal, 1)
 	signal.Notify(shutdown, syscall.SIGINT, syscall.SIGTERM)
 
+	trustedProxies, err := server.ParseTrustedProxies(cfg.Web.TrustedProxies)
+	if err != nil {
+		return fmt.Errorf("failed to parse the web config: %w", err)
+	}
+
 	serverCfg := server.Config{
 		AppName:     cfg.Web.AppName,
 		Domain:      cfg.Web.DomainName,
@@ -222,6 +355,7 @@ func run(ctx context.Context, log *logger.Logger) error {
 		ShutdownTimeout: cfg.Web.ShutdownTimeout,
 
 		CORSAllowedOrigins: cfg.Web.CORSAllowedOrigins,
+		TrustedProxies:     trustedProxies,
 	}
 
 	server := server.NewWebServer(serverCfg, service)
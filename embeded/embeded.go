@@ -6,6 +6,7 @@ import (
 	"html/template"
 	"io"
 	"io/fs"
+	texttemplate "text/template"
 
 	"github.com/labstack/echo/v4"
 )
@@ -26,8 +27,9 @@ func Static() fs.FS {
 var templateFiles embed.FS
 
 type Template struct {
-	views  map[string]*template.Template
-	emails map[string]*template.Template
+	views      map[string]*template.Template
+	emails     map[string]*template.Template
+	textEmails map[string]*texttemplate.Template
 }
 
 func (t *Template) Render(w io.Writer, vName string, data any, c echo.Context) error {
@@ -44,15 +46,29 @@ func (t *Template) Render(w io.Writer, vName string, data any, c echo.Context) e
 	return nil
 }
 
+// RenderEmail returns the errors instead of panicking like Render, since the
+// emails are rendered by the outbox dispatcher out of any request.
 func (t *Template) RenderEmail(w io.Writer, eName string, data any) error {
 	email, ok := t.emails[eName]
 	if !ok {
-		panic(fmt.Sprintf("invalid email name: %q", eName))
+		return fmt.Errorf("invalid email name: %q", eName)
 	}
 
-	err := email.Execute(w, data)
-	if err != nil {
-		panic(fmt.Sprintf("failed to execute email %q: %v", eName, err))
+	if err := email.Execute(w, data); err != nil {
+		return fmt.Errorf("failed to execute email %q: %w", eName, err)
+	}
+
+	return nil
+}
+
+func (t *Template) RenderEmailText(w io.Writer, eName string, data any) error {
+	email, ok := t.textEmails[eName]
+	if !ok {
+		return fmt.Errorf("invalid email name: %q", eName)
+	}
+
+	if err := email.Execute(w, data); err != nil {
+		return fmt.Errorf("failed to execute text email %q: %w", eName, err)
 	}
 
 	return nil
@@ -72,23 +88,41 @@ func (t *Template) NewView(name, base string, partials ...string) {
 	t.views[name] = template.Must(template.New(base).Funcs(
 		template.FuncMap{
 			"safeHTML": safeHTML,
+			"t":        Translate,
+			"money":    formatMoney,
+			"percent":  formatPercent,
+			"date":     formatDate,
+			"datetime": formatDateTime,
 		},
 	).ParseFS(templateFiles, all...))
 }
 
-func (t *Template) NewEmail(name, base string) {
+// NewEmail registers the HTML and the plain text templates of the email.
+func (t *Template) NewEmail(name, html, text string) {
 	if _, ok := t.emails[name]; ok {
 		panic(fmt.Sprintf("email with name %q already registered.", name))
 	}
 
-	email := template.Must(template.New(base).ParseFS(templateFiles, fmt.Sprintf("mails/%s", base)))
+	email := template.Must(template.New(html).Funcs(
+		template.FuncMap{
+			"t": Translate,
+		},
+	).ParseFS(templateFiles, fmt.Sprintf("mails/%s", html)))
 	t.emails[name] = email
+
+	textEmail := texttemplate.Must(texttemplate.New(text).Funcs(
+		texttemplate.FuncMap{
+			"t": Translate,
+		},
+	).ParseFS(templateFiles, fmt.Sprintf("mails/%s", text)))
+	t.textEmails[name] = textEmail
 }
 
 func Templates() *Template {
 	return &Template{
-		views:  map[string]*template.Template{},
-		emails: map[string]*template.Template{},
+		views:      map[string]*template.Template{},
+		emails:     map[string]*template.Template{},
+		textEmails: map[string]*texttemplate.Template{},
 	}
 }
 
@@ -0,0 +1,33 @@
+package embeded
+
+import (
+	"time"
+
+	"github.com/garnizeH/dimdim/pkg/money"
+)
+
+// dateFormatter formats the dates in the time zone and the date format of the user.
+type dateFormatter interface {
+	FormatDate(time.Time) string
+	FormatDateTime(time.Time) string
+}
+
+// formatMoney formats the amount in the conventions of the locale, as in {{money $.Locale .Amount}}.
+func formatMoney(locale string, m money.Money) string {
+	return m.Format(locale)
+}
+
+// formatPercent formats the ratio as a percentage with one decimal, as in {{percent $.Locale .Ratio}}.
+func formatPercent(locale string, ratio float64) string {
+	return money.FormatPercent(locale, ratio, 1)
+}
+
+// formatDate formats the date with the preferences of the user, as in {{date $.Preferences .CreatedAt}}.
+func formatDate(f dateFormatter, t time.Time) string {
+	return f.FormatDate(t)
+}
+
+// formatDateTime formats the date and time with the preferences of the user, as in {{datetime $.Preferences .CreatedAt}}.
+func formatDateTime(f dateFormatter, t time.Time) string {
+	return f.FormatDateTime(t)
+}
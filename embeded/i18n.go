@@ -0,0 +1,120 @@
+package embeded
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"path"
+	"slices"
+	"strings"
+
+	"golang.org/x/text/language"
+)
+
+const (
+	// SourceLocale is the language the templates and the messages are written in.
+	SourceLocale = "en"
+	// DefaultLocale is used when the language of the visitor is unknown.
+	DefaultLocale = "pt-BR"
+)
+
+//go:embed locales
+var localeFiles embed.FS
+
+// catalogs maps the locale to the translations of the messages written in the
+// source locale, loaded from the locales/<locale>.json files.
+var catalogs = loadCatalogs()
+
+var matcher = language.NewMatcher(tags(Locales()))
+
+func loadCatalogs() map[string]map[string]string {
+	entries, err := localeFiles.ReadDir("locales")
+	if err != nil {
+		panic(fmt.Sprintf("failed to read the locales: %v", err))
+	}
+
+	catalogs := map[string]map[string]string{}
+	for _, entry := range entries {
+		data, err := localeFiles.ReadFile(path.Join("locales", entry.Name()))
+		if err != nil {
+			panic(fmt.Sprintf("failed to read the locale %q: %v", entry.Name(), err))
+		}
+
+		catalog := map[string]string{}
+		if err := json.Unmarshal(data, &catalog); err != nil {
+			panic(fmt.Sprintf("failed to parse the locale %q: %v", entry.Name(), err))
+		}
+
+		catalogs[strings.TrimSuffix(entry.Name(), ".json")] = catalog
+	}
+
+	return catalogs
+}
+
+func tags(locales []string) []language.Tag {
+	tags := make([]language.Tag, len(locales))
+	for i, l := range locales {
+		tags[i] = language.MustParse(l)
+	}
+
+	return tags
+}
+
+// Locales returns the supported locales, the default one first.
+func Locales() []string {
+	locales := []string{DefaultLocale}
+	if DefaultLocale != SourceLocale {
+		locales = append(locales, SourceLocale)
+	}
+	others := []string{}
+	for l := range catalogs {
+		if l != DefaultLocale && l != SourceLocale {
+			others = append(others, l)
+		}
+	}
+	slices.Sort(others)
+
+	return append(locales, others...)
+}
+
+// Lookup returns the translation of the key in the locale, the source locale
+// translates every key to itself.
+func Lookup(locale, key string) (string, bool) {
+	if locale == SourceLocale {
+		return key, true
+	}
+
+	msg, ok := catalogs[locale][key]
+	return msg, ok && msg != ""
+}
+
+// Translate returns the translation of the key in the locale, formatted with
+// the args when given. Missing translations fall back to the key itself.
+func Translate(locale, key string, args ...any) string {
+	msg, ok := Lookup(locale, key)
+	if !ok {
+		msg = key
+	}
+
+	if len(args) > 0 {
+		return fmt.Sprintf(msg, args...)
+	}
+
+	return msg
+}
+
+// MatchLocale returns the supported locale that best matches the
+// Accept-Language header, or the default locale.
+func MatchLocale(acceptLanguage string) string {
+	prefs, _, err := language.ParseAcceptLanguage(acceptLanguage)
+	if err != nil || len(prefs) == 0 {
+		return DefaultLocale
+	}
+
+	_, i, confidence := matcher.Match(prefs...)
+	if confidence == language.No {
+		return DefaultLocale
+	}
+
+	return Locales()[i]
+}
@@ -0,0 +1,155 @@
+package embeded_test
+
+import (
+	"io/fs"
+	"os"
+	"regexp"
+	"strconv"
+	"testing"
+
+	"github.com/garnizeH/dimdim/embeded"
+)
+
+// translateCall matches the keys translated with the "t" template function.
+var translateCall = regexp.MustCompile(`\bt \$?\.Locale ("(?:[^"\\]|\\.)*")`)
+
+func TestCatalogsCoverTemplates(t *testing.T) {
+	keys := map[string][]string{}
+	for _, dir := range []string{"layouts", "partials", "mails"} {
+		err := fs.WalkDir(os.DirFS(dir), ".", func(path string, d fs.DirEntry, err error) error {
+			if err != nil || d.IsDir() {
+				return err
+			}
+
+			data, err := fs.ReadFile(os.DirFS(dir), path)
+			if err != nil {
+				return err
+			}
+
+			for _, m := range translateCall.FindAllStringSubmatch(string(data), -1) {
+				key, err := strconv.Unquote(m[1])
+				if err != nil {
+					t.Fatalf("invalid key %s in %s/%s: %v", m[1], dir, path, err)
+				}
+				keys[key] = append(keys[key], dir+"/"+path)
+			}
+
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("failed to read the templates in %q: %v", dir, err)
+		}
+	}
+
+	if len(keys) == 0 {
+		t.Fatal("found no translated keys in the templates")
+	}
+
+	for _, locale := range embeded.Locales() {
+		for key, files := range keys {
+			if _, ok := embeded.Lookup(locale, key); !ok {
+				t.Errorf("locale %s is missing the key %q used in %v", locale, key, files)
+			}
+		}
+	}
+}
+
+func TestTranslate(t *testing.T) {
+	tests := []struct {
+		name   string
+		locale string
+		key    string
+		args   []any
+		want   string
+	}{
+		{
+			name:   "source locale",
+			locale: "en",
+			key:    "Sign in",
+			want:   "Sign in",
+		},
+		{
+			name:   "translated",
+			locale: "pt-BR",
+			key:    "Sign in",
+			want:   "Entrar",
+		},
+		{
+			name:   "translated with args",
+			locale: "pt-BR",
+			key:    "Hello %s",
+			args:   []any{"João"},
+			want:   "Olá João",
+		},
+		{
+			name:   "missing key",
+			locale: "pt-BR",
+			key:    "failed to read the database",
+			want:   "failed to read the database",
+		},
+		{
+			name:   "unknown locale",
+			locale: "fr",
+			key:    "Sign in",
+			want:   "Sign in",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := embeded.Translate(tt.locale, tt.key, tt.args...); got != tt.want {
+				t.Errorf("Translate() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMatchLocale(t *testing.T) {
+	tests := []struct {
+		name           string
+		acceptLanguage string
+		want           string
+	}{
+		{
+			name:           "empty",
+			acceptLanguage: "",
+			want:           "pt-BR",
+		},
+		{
+			name:           "exact",
+			acceptLanguage: "en",
+			want:           "en",
+		},
+		{
+			name:           "region",
+			acceptLanguage: "en-US,en;q=0.9",
+			want:           "en",
+		},
+		{
+			name:           "portuguese",
+			acceptLanguage: "pt",
+			want:           "pt-BR",
+		},
+		{
+			name:           "quality",
+			acceptLanguage: "fr;q=0.9,en;q=0.5,pt-BR;q=0.8",
+			want:           "pt-BR",
+		},
+		{
+			name:           "unsupported",
+			acceptLanguage: "ja",
+			want:           "pt-BR",
+		},
+		{
+			name:           "invalid",
+			acceptLanguage: ";;;",
+			want:           "pt-BR",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := embeded.MatchLocale(tt.acceptLanguage); got != tt.want {
+				t.Errorf("MatchLocale(%q) = %q, want %q", tt.acceptLanguage, got, tt.want)
+			}
+		})
+	}
+}
@@ -14,6 +14,7 @@ require (
 	github.com/pressly/goose/v3 v3.24.1
 	github.com/spazzymoto/echo-scs-session v1.0.0
 	golang.org/x/crypto v0.35.0
+	golang.org/x/text v0.22.0
 )
 
 require (
@@ -31,6 +32,5 @@ require (
 	golang.org/x/net v0.35.0 // indirect
 	golang.org/x/sync v0.11.0 // indirect
 	golang.org/x/sys v0.30.0 // indirect
-	golang.org/x/text v0.22.0 // indirect
 	golang.org/x/time v0.10.0 // indirect
 )
@@ -0,0 +1,347 @@
+package web
+
+import (
+	"errors"
+	"net/mail"
+	"strings"
+	"time"
+
+	"github.com/garnizeH/dimdim/embeded"
+	"github.com/garnizeH/dimdim/service/invite"
+	"github.com/garnizeH/dimdim/service/outbox"
+	"github.com/garnizeH/dimdim/service/user"
+	"github.com/labstack/echo/v4"
+	"github.com/microcosm-cc/bluemonday"
+)
+
+const (
+	maxInviteDays     = 30
+	maxOutboxMessages = 100
+)
+
+var (
+	ErrSelfAdministration = errors.New("you cannot change your own account from the admin console")
+	ErrInvalidInvite      = errors.New("invalid invite")
+	ErrInvalidMessage     = errors.New("invalid message")
+)
+
+func (h *Handler) loadRoutesAdmin(g *echo.Group, templates *embeded.Template) {
+	// users
+	templates.NewView("admin-users", "base.tmpl", "menu.tmpl", "messages.tmpl", "admin/users.tmpl")
+	templates.NewView("admin-user", "base.tmpl", "menu.tmpl", "messages.tmpl", "admin/user.tmpl")
+	g.GET("", h.AdminUsers)
+	g.GET("/users", h.AdminUsers)
+	g.GET("/user", h.AdminUser)
+	g.POST("/user/resend-verification", h.AdminResendVerification)
+	g.POST("/user/reset-password", h.AdminResetPassword)
+	g.POST("/user/disable", h.AdminDisableUser)
+	g.POST("/user/enable", h.AdminEnableUser)
+
+	// invites
+	templates.NewView("admin-invites", "base.tmpl", "menu.tmpl", "messages.tmpl", "admin/invites.tmpl")
+	g.GET("/invites", h.AdminInvites)
+	g.POST("/invites", h.AdminCreateInvite)
+	g.POST("/invites/revoke", h.AdminRevokeInvite)
+
+	// outbox
+	templates.NewView("admin-outbox", "base.tmpl", "menu.tmpl", "messages.tmpl", "admin/outbox.tmpl")
+	g.GET("/outbox", h.AdminOutbox)
+	g.POST("/outbox/retry", h.AdminRetryOutboxMessage)
+}
+
+type adminUsersRequest struct {
+	Search string `query:"q"`
+}
+
+func (r *adminUsersRequest) validate(c echo.Context, input *bluemonday.Policy) error {
+	r.Search = input.Sanitize(strings.TrimSpace(r.Search))
+
+	return nil
+}
+
+func (h *Handler) AdminUsers(c echo.Context) error {
+	r := adminUsersRequest{}
+	if err := h.validateRequest(c, &r); err != nil {
+		return err
+	}
+
+	accounts, err := h.service.User().ListAccounts(c.Request().Context(), r.Search)
+	if err != nil {
+		return h.errMsg(err.Error())
+	}
+
+	setSessionDataFields(c, struct {
+		Search   string
+		Accounts []user.Account
+	}{
+		Search:   r.Search,
+		Accounts: accounts,
+	})
+	return pageRendererWithFlashMsg(c, "admin-users", "")
+}
+
+type adminUserRequest struct {
+	Email string `query:"email" form:"email"`
+}
+
+func (r *adminUserRequest) validate(c echo.Context, input *bluemonday.Policy) error {
+	r.Email = strings.TrimSpace(r.Email)
+	if _, err := mail.ParseAddress(r.Email); err != nil {
+		return ErrInvalidEmail
+	}
+
+	return nil
+}
+
+// setAdminUserFields loads the account details shown in the admin user page.
+func (h *Handler) setAdminUserFields(c echo.Context, email string) error {
+	ctx := c.Request().Context()
+	account, err := h.service.User().GetAccount(ctx, email)
+	if err != nil {
+		return err
+	}
+
+	sessions, err := listSessions(ctx, h.sess, email)
+	if err != nil {
+		return err
+	}
+
+	setSessionDataFields(c, struct {
+		Account  user.Account
+		Sessions int
+	}{
+		Account:  account,
+		Sessions: len(sessions),
+	})
+	return nil
+}
+
+func (h *Handler) AdminUser(c echo.Context) error {
+	r := adminUserRequest{}
+	if err := h.validateRequest(c, &r); err != nil {
+		return err
+	}
+
+	if err := h.setAdminUserFields(c, r.Email); err != nil {
+		return h.errMsg(err.Error())
+	}
+
+	return pageRendererWithFlashMsg(c, "admin-user", "")
+}
+
+// adminUserAction validates the request, runs the action on the selected account and renders the
+// account page with the result.
+func (h *Handler) adminUserAction(c echo.Context, action func(email string) error, msg string) error {
+	r := adminUserRequest{}
+	if err := h.validateRequest(c, &r); err != nil {
+		return err
+	}
+
+	if r.Email == getSessionData(c).Email {
+		return h.errMsg(ErrSelfAdministration.Error())
+	}
+
+	err := action(r.Email)
+	if fieldsErr := h.setAdminUserFields(c, r.Email); fieldsErr != nil {
+		return h.errMsg(fieldsErr.Error())
+	}
+	if err != nil {
+		return h.errTmpl("admin-user", err.Error())
+	}
+
+	return pageRendererWithFlashMsg(c, "admin-user", msg)
+}
+
+func (h *Handler) AdminResendVerification(c echo.Context) error {
+	ctx := c.Request().Context()
+	return h.adminUserAction(c, func(email string) error {
+		return h.service.User().ResendSignupToken(ctx, h.baseURL, email)
+	}, "verification email sent")
+}
+
+func (h *Handler) AdminResetPassword(c echo.Context) error {
+	ctx := c.Request().Context()
+	return h.adminUserAction(c, func(email string) error {
+		if err := h.service.User().ForcePasswordReset(ctx, h.baseURL, email); err != nil {
+			return err
+		}
+
+		return revokeAllSessions(ctx, h.sess, email)
+	}, "password reset, the user was signed out and received the reset password email")
+}
+
+func (h *Handler) AdminDisableUser(c echo.Context) error {
+	ctx := c.Request().Context()
+	return h.adminUserAction(c, func(email string) error {
+		if err := h.service.User().SetDisabled(ctx, email, true); err != nil {
+			return err
+		}
+
+		return revokeAllSessions(ctx, h.sess, email)
+	}, "account disabled")
+}
+
+func (h *Handler) AdminEnableUser(c echo.Context) error {
+	ctx := c.Request().Context()
+	return h.adminUserAction(c, func(email string) error {
+		return h.service.User().SetDisabled(ctx, email, false)
+	}, "account enabled")
+}
+
+type inviteView struct {
+	invite.Invite
+	URL string
+}
+
+// setAdminInvitesFields loads the pending invites shown in the admin invites page.
+func (h *Handler) setAdminInvitesFields(c echo.Context) error {
+	invites, err := h.service.Invite().ListInvites(c.Request().Context())
+	if err != nil {
+		return err
+	}
+
+	views := make([]inviteView, len(invites))
+	for i, inv := range invites {
+		views[i] = inviteView{
+			Invite: inv,
+			URL:    inv.URL(h.baseURL),
+		}
+	}
+
+	setSessionDataFields(c, struct {
+		Invites []inviteView
+	}{
+		Invites: views,
+	})
+	return nil
+}
+
+func (h *Handler) AdminInvites(c echo.Context) error {
+	if err := h.setAdminInvitesFields(c); err != nil {
+		return h.errMsg(err.Error())
+	}
+
+	return pageRendererWithFlashMsg(c, "admin-invites", "")
+}
+
+type createInviteRequest struct {
+	Email string `form:"email"`
+	Days  int    `form:"days"`
+}
+
+func (r *createInviteRequest) validate(c echo.Context, input *bluemonday.Policy) error {
+	r.Email = strings.TrimSpace(r.Email)
+	if r.Email != "" {
+		email, err := mail.ParseAddress(r.Email)
+		if err != nil {
+			return ErrInvalidEmail
+		}
+		r.Email = email.Address
+	}
+
+	if r.Days < 1 || r.Days > maxInviteDays {
+		return ErrInvalidInvite
+	}
+
+	return nil
+}
+
+func (h *Handler) AdminCreateInvite(c echo.Context) error {
+	r := createInviteRequest{}
+	if err := h.validateRequest(c, &r); err != nil {
+		return err
+	}
+
+	ctx := c.Request().Context()
+	_, err := h.service.Invite().CreateInvite(ctx, r.Email, time.Duration(r.Days)*24*time.Hour)
+	if fieldsErr := h.setAdminInvitesFields(c); fieldsErr != nil {
+		return h.errMsg(fieldsErr.Error())
+	}
+	if err != nil {
+		return h.errTmpl("admin-invites", err.Error())
+	}
+
+	return pageRendererWithFlashMsg(c, "admin-invites", "invite created")
+}
+
+type revokeInviteRequest struct {
+	Code string `form:"code"`
+}
+
+func (r *revokeInviteRequest) validate(c echo.Context, input *bluemonday.Policy) error {
+	r.Code = strings.TrimSpace(r.Code)
+	if r.Code == "" {
+		return ErrInvalidInvite
+	}
+
+	return nil
+}
+
+func (h *Handler) AdminRevokeInvite(c echo.Context) error {
+	r := revokeInviteRequest{}
+	if err := h.validateRequest(c, &r); err != nil {
+		return err
+	}
+
+	err := h.service.Invite().RevokeInvite(c.Request().Context(), r.Code)
+	if fieldsErr := h.setAdminInvitesFields(c); fieldsErr != nil {
+		return h.errMsg(fieldsErr.Error())
+	}
+	if err != nil {
+		return h.errTmpl("admin-invites", err.Error())
+	}
+
+	return pageRendererWithFlashMsg(c, "admin-invites", "invite revoked")
+}
+
+// setAdminOutboxFields loads the most recent outbox messages shown in the admin outbox page.
+func (h *Handler) setAdminOutboxFields(c echo.Context) error {
+	entries, err := h.service.Outbox().List(c.Request().Context(), maxOutboxMessages)
+	if err != nil {
+		return err
+	}
+
+	setSessionDataFields(c, struct {
+		Messages []outbox.Entry
+	}{
+		Messages: entries,
+	})
+	return nil
+}
+
+func (h *Handler) AdminOutbox(c echo.Context) error {
+	if err := h.setAdminOutboxFields(c); err != nil {
+		return h.errMsg(err.Error())
+	}
+
+	return pageRendererWithFlashMsg(c, "admin-outbox", "")
+}
+
+type retryOutboxMessageRequest struct {
+	ID int64 `form:"id"`
+}
+
+func (r *retryOutboxMessageRequest) validate(c echo.Context, input *bluemonday.Policy) error {
+	if r.ID <= 0 {
+		return ErrInvalidMessage
+	}
+
+	return nil
+}
+
+func (h *Handler) AdminRetryOutboxMessage(c echo.Context) error {
+	r := retryOutboxMessageRequest{}
+	if err := h.validateRequest(c, &r); err != nil {
+		return err
+	}
+
+	err := h.service.Outbox().Retry(c.Request().Context(), r.ID)
+	if fieldsErr := h.setAdminOutboxFields(c); fieldsErr != nil {
+		return h.errMsg(fieldsErr.Error())
+	}
+	if err != nil {
+		return h.errTmpl("admin-outbox", err.Error())
+	}
+
+	return pageRendererWithFlashMsg(c, "admin-outbox", "message scheduled for delivery")
+}
@@ -0,0 +1,420 @@
+package web
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/garnizeH/dimdim/pkg/boleto"
+	"github.com/garnizeH/dimdim/pkg/money"
+	"github.com/garnizeH/dimdim/pkg/pix"
+	"github.com/garnizeH/dimdim/service/accesstoken"
+	"github.com/garnizeH/dimdim/service/bill"
+	"github.com/garnizeH/dimdim/service/report"
+	"github.com/labstack/echo/v4"
+)
+
+// apiPrefix is the path of the JSON API, versioned so the scripts using it
+// keep working when a new version changes it.
+const apiPrefix = "/api/v1"
+
+// apiBadRequestErrors are the errors of the API caused by the request.
+var apiBadRequestErrors = []error{
+	bill.ErrInvalidBill,
+	bill.ErrInvalidQuery,
+	bill.ErrUnknownFilter,
+	report.ErrInvalidFilter,
+	money.ErrInvalidAmount,
+	money.ErrUnknownCurrency,
+	boleto.ErrInvalidCode,
+	boleto.ErrInvalidCheckDigit,
+	pix.ErrInvalidCode,
+	pix.ErrInvalidChecksum,
+}
+
+// isAPIRequest reports whether the request is to the JSON API, whose errors
+// are JSON instead of pages.
+func isAPIRequest(c echo.Context) bool {
+	return strings.HasPrefix(c.Request().URL.Path, "/api/")
+}
+
+// bearerToken returns the access token of the Authorization header, if any.
+func bearerToken(c echo.Context) string {
+	scheme, token, ok := strings.Cut(c.Request().Header.Get(echo.HeaderAuthorization), " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return ""
+	}
+
+	return strings.TrimSpace(token)
+}
+
+type apiErrorResponse struct {
+	Error apiError `json:"error"`
+}
+
+type apiError struct {
+	Status  int    `json:"status"`
+	Message string `json:"message"`
+}
+
+// apiErrorHandler writes the error as JSON. The messages of the internal
+// errors are not shown, as they may tell about the database.
+func apiErrorHandler(err error, c echo.Context) {
+	code := http.StatusInternalServerError
+	msg := http.StatusText(code)
+	var he *echo.HTTPError
+	if errors.As(err, &he) {
+		code = he.Code
+		msg = http.StatusText(code)
+		if m, ok := he.Message.(string); ok && m != "" {
+			msg = m
+		}
+	}
+
+	if code == http.StatusUnauthorized {
+		c.Response().Header().Set(echo.HeaderWWWAuthenticate, `Bearer realm="dimdim"`)
+	}
+	if err := c.JSON(code, apiErrorResponse{Error: apiError{Status: code, Message: msg}}); err != nil {
+		c.Logger().Error(err)
+	}
+}
+
+// apiErr returns the error of the service with the status it maps to.
+func apiErr(err error) error {
+	switch {
+	case errors.Is(err, bill.ErrBillNotFound):
+		return echo.NewHTTPError(http.StatusNotFound, err.Error())
+	}
+	for _, target := range apiBadRequestErrors {
+		if errors.Is(err, target) {
+			return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		}
+	}
+
+	return err
+}
+
+// apiAuthMiddleware authenticates the requests with the access token of the
+// Authorization header, which must allow the scope. The session cookies are
+// not accepted by the API.
+func (h *Handler) apiAuthMiddleware(scope string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			secret := bearerToken(c)
+			if secret == "" {
+				return echo.NewHTTPError(http.StatusUnauthorized, "missing access token")
+			}
+
+			token, err := h.service.AccessToken().Authenticate(c.Request().Context(), secret, time.Now())
+			if err != nil {
+				if errors.Is(err, accesstoken.ErrInvalidToken) {
+					return echo.NewHTTPError(http.StatusUnauthorized, err.Error())
+				}
+				return err
+			}
+			if !token.Allows(scope) {
+				return echo.NewHTTPError(http.StatusForbidden, "the access token does not allow "+scope)
+			}
+
+			c.Set("accessToken", token)
+			return next(c)
+		}
+	}
+}
+
+func getAccessToken(c echo.Context) accesstoken.Token {
+	token, _ := c.Get("accessToken").(accesstoken.Token)
+	return token
+}
+
+func (h *Handler) loadRoutesAPI(g *echo.Group) {
+	read := h.apiAuthMiddleware(accesstoken.ScopeRead)
+	write := h.apiAuthMiddleware(accesstoken.ScopeWrite)
+
+	g.GET("/bills", h.APIListBills, read)
+	g.POST("/bills", h.APICreateBill, write)
+	g.POST("/bills/:id/pay", h.APIPayBill, write)
+	g.DELETE("/bills/:id", h.APIDeleteBill, write)
+	g.GET("/payees", h.APIListPayees, read)
+	g.GET("/reports/paid-bills", h.APIPaidBillsReport, read)
+}
+
+type apiBill struct {
+	ID   int64  `json:"id"`
+	Name string `json:"name"`
+	// Amount is a decimal number with a dot, e.g. 1500.00.
+	Amount     string `json:"amount"`
+	Currency   string `json:"currency"`
+	DueOn      string `json:"due_on"`
+	RemindDays int    `json:"remind_days"`
+	PaidAt     string `json:"paid_at,omitempty"`
+	Overdue    bool   `json:"overdue"`
+	PayeeID    int64  `json:"payee_id,omitempty"`
+	Barcode    string `json:"barcode,omitempty"`
+	Pix        string `json:"pix,omitempty"`
+}
+
+func newAPIBill(b bill.Bill, now time.Time) apiBill {
+	out := apiBill{
+		ID:         b.ID,
+		Name:       b.Name,
+		Amount:     money.FormatDecimal("", b.Amount.Amount, money.Exponent(b.Amount.Currency)),
+		Currency:   b.Amount.Currency,
+		DueOn:      b.DueOn.Format(bill.DueLayout),
+		RemindDays: b.RemindDays,
+		Overdue:    b.Overdue(now),
+		PayeeID:    b.PayeeID,
+		Barcode:    b.Boleto.Barcode,
+		Pix:        b.Pix.Payload,
+	}
+	if b.Paid() {
+		out.PaidAt = b.PaidAt.UTC().Format(time.RFC3339)
+	}
+
+	return out
+}
+
+type apiBillsResponse struct {
+	Bills []apiBill `json:"bills"`
+}
+
+type apiListBillsRequest struct {
+	Query string `query:"q"`
+}
+
+// APIListBills returns the bills of the user, or the ones matching the search
+// of the q parameter, in the syntax of the search page.
+func (h *Handler) APIListBills(c echo.Context) error {
+	r := apiListBillsRequest{}
+	if err := c.Bind(&r); err != nil {
+		return err
+	}
+
+	ctx := c.Request().Context()
+	u, err := h.service.User().GetUser(ctx, getAccessToken(c).Email)
+	if err != nil {
+		return err
+	}
+
+	var bills []bill.Bill
+	if q := strings.TrimSpace(r.Query); q != "" {
+		query, err := bill.ParseQuery(q, u.Preferences.Currency)
+		if err != nil {
+			return apiErr(err)
+		}
+		bills, err = h.service.Bill().Search(ctx, u.Email, query)
+		if err != nil {
+			return apiErr(err)
+		}
+	} else {
+		bills, err = h.service.Bill().ListBills(ctx, u.Email)
+		if err != nil {
+			return apiErr(err)
+		}
+	}
+
+	now := time.Now()
+	out := apiBillsResponse{Bills: make([]apiBill, len(bills))}
+	for i, b := range bills {
+		out.Bills[i] = newAPIBill(b, now)
+	}
+
+	return c.JSON(http.StatusOK, out)
+}
+
+type apiCreateBillRequest struct {
+	Name     string `json:"name"`
+	Amount   string `json:"amount"`
+	Currency string `json:"currency"`
+	DueOn    string `json:"due_on"`
+	// RemindDays defaults to the one of the new bill form.
+	RemindDays *int   `json:"remind_days"`
+	Barcode    string `json:"barcode"`
+	Pix        string `json:"pix"`
+}
+
+// APICreateBill creates the bill and returns it.
+func (h *Handler) APICreateBill(c echo.Context) error {
+	r := apiCreateBillRequest{}
+	if err := c.Bind(&r); err != nil {
+		return err
+	}
+
+	amount, err := money.Parse(r.Amount, strings.TrimSpace(r.Currency))
+	if err != nil {
+		return apiErr(err)
+	}
+	remindDays := defaultRemindDays
+	if r.RemindDays != nil {
+		remindDays = *r.RemindDays
+	}
+
+	b, err := h.service.Bill().CreateBill(
+		c.Request().Context(),
+		getAccessToken(c).Email,
+		h.input.Sanitize(strings.TrimSpace(r.Name)),
+		amount,
+		strings.TrimSpace(r.DueOn),
+		remindDays,
+		strings.TrimSpace(r.Barcode),
+		strings.TrimSpace(r.Pix),
+	)
+	if err != nil {
+		return apiErr(err)
+	}
+
+	return c.JSON(http.StatusCreated, newAPIBill(b, time.Now()))
+}
+
+type apiBillRequest struct {
+	ID int64 `param:"id"`
+}
+
+// APIPayBill marks the bill as paid.
+func (h *Handler) APIPayBill(c echo.Context) error {
+	return h.apiBillAction(c, h.service.Bill().MarkPaid)
+}
+
+// APIDeleteBill deletes the bill.
+func (h *Handler) APIDeleteBill(c echo.Context) error {
+	return h.apiBillAction(c, h.service.Bill().DeleteBill)
+}
+
+func (h *Handler) apiBillAction(c echo.Context, action func(ctx context.Context, email string, id int64) error) error {
+	r := apiBillRequest{}
+	if err := c.Bind(&r); err != nil || r.ID <= 0 {
+		return echo.NewHTTPError(http.StatusNotFound, bill.ErrBillNotFound.Error())
+	}
+
+	if err := action(c.Request().Context(), getAccessToken(c).Email, r.ID); err != nil {
+		return apiErr(err)
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+type apiPayee struct {
+	ID      int64    `json:"id"`
+	Name    string   `json:"name"`
+	City    string   `json:"city,omitempty"`
+	TaxID   string   `json:"tax_id,omitempty"`
+	Aliases []string `json:"aliases"`
+	PixKeys []string `json:"pix_keys"`
+}
+
+type apiPayeesResponse struct {
+	Payees []apiPayee `json:"payees"`
+}
+
+// APIListPayees returns the payees of the user with their aliases.
+func (h *Handler) APIListPayees(c echo.Context) error {
+	payees, err := h.service.Payee().List(c.Request().Context(), getAccessToken(c).Email)
+	if err != nil {
+		return apiErr(err)
+	}
+
+	out := apiPayeesResponse{Payees: make([]apiPayee, len(payees))}
+	for i, p := range payees {
+		out.Payees[i] = apiPayee{
+			ID:      p.ID,
+			Name:    p.Name,
+			City:    p.City,
+			TaxID:   p.TaxID.Number,
+			Aliases: make([]string, len(p.Aliases)),
+			PixKeys: make([]string, len(p.PixKeys)),
+		}
+		for j, a := range p.Aliases {
+			out.Payees[i].Aliases[j] = a.Value
+		}
+		for j, a := range p.PixKeys {
+			out.Payees[i].PixKeys[j] = a.Value
+		}
+	}
+
+	return c.JSON(http.StatusOK, out)
+}
+
+type apiReportTotal struct {
+	// Start is the first day of the period.
+	Start  string `json:"start"`
+	Amount string `json:"amount"`
+	Count  int    `json:"count"`
+}
+
+type apiReportPayee struct {
+	PayeeID int64   `json:"payee_id,omitempty"`
+	Name    string  `json:"name"`
+	Amount  string  `json:"amount"`
+	Count   int     `json:"count"`
+	Share   float64 `json:"share"`
+}
+
+type apiReport struct {
+	From     string           `json:"from"`
+	To       string           `json:"to"`
+	Period   report.Period    `json:"period"`
+	Currency string           `json:"currency"`
+	PayeeID  int64            `json:"payee_id,omitempty"`
+	Total    string           `json:"total"`
+	Count    int              `json:"count"`
+	Periods  []apiReportTotal `json:"periods"`
+	Payees   []apiReportPayee `json:"payees"`
+}
+
+// APIPaidBillsReport returns the report of the reports page, with the same
+// parameters and defaults.
+func (h *Handler) APIPaidBillsReport(c echo.Context) error {
+	r := reportRequest{}
+	if err := c.Bind(&r); err != nil {
+		return err
+	}
+	if err := r.validate(c, h.input); err != nil {
+		return apiErr(err)
+	}
+
+	ctx := c.Request().Context()
+	u, err := h.service.User().GetUser(ctx, getAccessToken(c).Email)
+	if err != nil {
+		return err
+	}
+
+	rep, err := h.service.Report().PaidBills(ctx, u.Email, r.filter(u.Preferences, time.Now()))
+	if err != nil {
+		return apiErr(err)
+	}
+
+	decimal := func(m money.Money) string {
+		return money.FormatDecimal("", m.Amount, money.Exponent(rep.Filter.Currency))
+	}
+	out := apiReport{
+		From:     rep.Filter.From,
+		To:       rep.Filter.To,
+		Period:   rep.Filter.Period,
+		Currency: rep.Filter.Currency,
+		PayeeID:  rep.Filter.PayeeID,
+		Total:    decimal(rep.Total),
+		Count:    rep.Count,
+		Periods:  make([]apiReportTotal, len(rep.Periods)),
+		Payees:   make([]apiReportPayee, len(rep.Payees)),
+	}
+	for i, p := range rep.Periods {
+		out.Periods[i] = apiReportTotal{
+			Start:  p.Start.Format(report.DateLayout),
+			Amount: decimal(p.Amount),
+			Count:  p.Count,
+		}
+	}
+	for i, p := range rep.Payees {
+		out.Payees[i] = apiReportPayee{
+			PayeeID: p.PayeeID,
+			Name:    p.Name,
+			Amount:  decimal(p.Amount),
+			Count:   p.Count,
+			Share:   p.Share,
+		}
+	}
+
+	return c.JSON(http.StatusOK, out)
+}
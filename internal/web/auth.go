@@ -2,13 +2,14 @@ package web
 
 import (
 	"errors"
-	"fmt"
 	"net/http"
 	"net/mail"
 	"strings"
 
 	"github.com/alexedwards/scs/v2"
+	"github.com/garnizeH/dimdim/embeded"
 	"github.com/garnizeH/dimdim/service/user"
+	"github.com/garnizeH/dimdim/storage"
 	"github.com/labstack/echo/v4"
 	"github.com/microcosm-cc/bluemonday"
 )
@@ -24,6 +25,7 @@ var (
 	ErrInvalidName       = errors.New("invalid name")
 	ErrNotMatchPasswords = errors.New("passwords do not match")
 	ErrInvalidToken      = errors.New("invalid token")
+	ErrInvalidSession    = errors.New("invalid session")
 )
 
 type signinRequest struct {
@@ -70,7 +72,10 @@ func (h *Handler) Signin(c echo.Context) error {
 		return h.errTmpl("signin", err.Error())
 	}
 
-	h.sess.Put(ctx, contextKeyEmail, u.Email)
+	if err := startSession(c, h.sess, u.Email); err != nil {
+		return h.errMsg(err.Error())
+	}
+
 	return c.Redirect(http.StatusSeeOther, "/")
 }
 
@@ -79,6 +84,7 @@ type signupRequest struct {
 	Name     string `form:"name"`
 	Password string `form:"password"`
 	Confirm  string `form:"confirm"`
+	Invite   string `form:"invite"`
 }
 
 func (r *signupRequest) validate(c echo.Context, input *bluemonday.Policy) error {
@@ -104,24 +110,32 @@ func (r *signupRequest) validate(c echo.Context, input *bluemonday.Policy) error
 		return ErrNotMatchPasswords
 	}
 
+	r.Invite = strings.TrimSpace(r.Invite)
+
 	return nil
 }
 
+func (h *Handler) SignupPage(c echo.Context) error {
+	if h.service.User().Registration() == user.RegistrationClosed {
+		return h.errTmpl("signin", user.ErrRegistrationClosed.Error())
+	}
+
+	setSessionDataFields(c, signupFields(signupRequest{
+		Invite: c.QueryParam("invite"),
+	}))
+
+	return pageRendererWithFlashMsg(c, "signup", "")
+}
+
 func (h *Handler) Signup(c echo.Context) error {
 	r := signupRequest{}
 
 	setFields := func() {
-		setSessionDataFields(c, struct {
-			Email    string
-			Name     string
-			Password string
-			Confirm  string
-		}{
-			Email:    r.Email,
-			Name:     r.Name,
-			Password: r.Password,
-			Confirm:  r.Confirm,
-		})
+		setSessionDataFields(c, signupFields(r))
+	}
+
+	if h.service.User().Registration() == user.RegistrationClosed {
+		return h.errTmpl("signin", user.ErrRegistrationClosed.Error())
 	}
 
 	if err := h.validateRequest(c, &r, "signup"); err != nil {
@@ -130,7 +144,7 @@ func (h *Handler) Signup(c echo.Context) error {
 	}
 
 	ctx := c.Request().Context()
-	err := h.service.User().Signup(ctx, h.baseURL, r.Email, r.Name, r.Password)
+	err := h.service.User().Signup(ctx, h.baseURL, r.Email, r.Name, r.Password, r.Invite, getSessionData(c).Locale)
 	if err != nil {
 		setFields()
 		return h.errTmpl("signup", err.Error())
@@ -139,6 +153,22 @@ func (h *Handler) Signup(c echo.Context) error {
 	return pageRendererWithFlashMsg(c, "signin", "check your mailbox")
 }
 
+func signupFields(r signupRequest) any {
+	return struct {
+		Email    string
+		Name     string
+		Password string
+		Confirm  string
+		Invite   string
+	}{
+		Email:    r.Email,
+		Name:     r.Name,
+		Password: r.Password,
+		Confirm:  r.Confirm,
+		Invite:   r.Invite,
+	}
+}
+
 func (h *Handler) SignupToken(c echo.Context) error {
 	token := c.Param("token")
 
@@ -148,7 +178,10 @@ func (h *Handler) SignupToken(c echo.Context) error {
 		return h.errTmpl("resend-signup-token", err.Error())
 	}
 
-	h.sess.Put(ctx, contextKeyEmail, u.Email)
+	if err := startSession(c, h.sess, u.Email); err != nil {
+		return h.errMsg(err.Error())
+	}
+
 	return c.Redirect(http.StatusSeeOther, "/")
 }
 
@@ -318,6 +351,13 @@ func (h *Handler) ChangePassword(c echo.Context) error {
 		return h.errTmpl("change-password", err.Error())
 	}
 
+	if err := revokeOtherSessions(ctx, h.sess, email); err != nil {
+		return h.errMsg(err.Error())
+	}
+	if err := h.sess.RenewToken(ctx); err != nil {
+		return h.errMsg(err.Error())
+	}
+
 	return pageRendererWithFlashMsg(c, "index", "password updated")
 }
 
@@ -373,11 +413,182 @@ func (h *Handler) ChangePasswordWithToken(c echo.Context) error {
 		return h.errTmpl("reset-password-token", err.Error())
 	}
 
-	h.sess.Put(ctx, contextKeyEmail, u.Email)
+	if err := revokeAllSessions(ctx, h.sess, u.Email); err != nil {
+		return h.errMsg(err.Error())
+	}
+
+	if err := startSession(c, h.sess, u.Email); err != nil {
+		return h.errMsg(err.Error())
+	}
+
+	return c.Redirect(http.StatusSeeOther, "/")
+}
+
+type changeEmailRequest struct {
+	Email    string `form:"email"`
+	Password string `form:"password"`
+}
+
+func (r *changeEmailRequest) validate(c echo.Context, input *bluemonday.Policy) error {
+	r.Email = input.Sanitize(strings.TrimSpace(r.Email))
+	email, err := mail.ParseAddress(r.Email)
+	if err != nil {
+		return ErrInvalidEmail
+	}
+
+	r.Email = email.Address
+
+	r.Password = strings.TrimSpace(r.Password)
+	if r.Password == "" || len(r.Password) < minPasswordLength {
+		return ErrInvalidPassword
+	}
+
+	return nil
+}
+
+func (h *Handler) ChangeEmail(c echo.Context) error {
+	r := changeEmailRequest{}
+
+	setFields := func() {
+		setSessionDataFields(c, struct {
+			Email    string
+			Password string
+		}{
+			Email:    r.Email,
+			Password: "",
+		})
+	}
+
+	if err := h.validateRequest(c, &r, "change-email"); err != nil {
+		setFields()
+		return err
+	}
+
+	ctx := c.Request().Context()
+	email := h.sess.GetString(ctx, contextKeyEmail)
+	if err := h.service.User().RequestEmailChange(ctx, h.baseURL, email, r.Email, r.Password); err != nil {
+		setFields()
+
+		return h.errTmpl("change-email", err.Error())
+	}
+
+	return pageRendererWithFlashMsg(c, "index", "check the mailbox of your new email address")
+}
+
+func (h *Handler) ChangeEmailToken(c echo.Context) error {
+	token := c.Param("token")
+
+	ctx := c.Request().Context()
+	change, err := h.service.User().ConfirmEmailChange(ctx, token)
+	if err != nil {
+		return h.errTmpl("signin", err.Error())
+	}
+
+	if err := migrateSessions(ctx, h.sess, change.OldEmail, change.NewEmail); err != nil {
+		return h.errMsg(err.Error())
+	}
+
+	if h.sess.GetString(ctx, contextKeyEmail) == "" {
+		return pageRendererWithFlashMsg(c, "signin", "email address updated")
+	}
+
 	return c.Redirect(http.StatusSeeOther, "/")
 }
 
-func sessionDataMiddleware(sessionManager *scs.SessionManager, users *user.Service, appName string) echo.MiddlewareFunc {
+func (h *Handler) CancelEmailChange(c echo.Context) error {
+	token := c.Param("token")
+
+	ctx := c.Request().Context()
+	if err := h.service.User().CancelEmailChange(ctx, token); err != nil {
+		return h.errTmpl("signin", err.Error())
+	}
+
+	if h.sess.GetString(ctx, contextKeyEmail) == "" {
+		return pageRendererWithFlashMsg(c, "signin", "email address change canceled")
+	}
+
+	return pageRendererWithFlashMsg(c, "index", "email address change canceled")
+}
+
+type deleteAccountRequest struct {
+	Password string `form:"password"`
+}
+
+func (r *deleteAccountRequest) validate(c echo.Context, input *bluemonday.Policy) error {
+	r.Password = strings.TrimSpace(r.Password)
+	if r.Password == "" || len(r.Password) < minPasswordLength {
+		return ErrInvalidPassword
+	}
+
+	return nil
+}
+
+func (h *Handler) DeleteAccount(c echo.Context) error {
+	r := deleteAccountRequest{}
+	if err := h.validateRequest(c, &r, "account"); err != nil {
+		return err
+	}
+
+	ctx := c.Request().Context()
+	email := h.sess.GetString(ctx, contextKeyEmail)
+	if err := h.service.User().DeleteAccount(ctx, email, r.Password); err != nil {
+		return h.errTmpl("account", err.Error())
+	}
+
+	if err := revokeAllSessions(ctx, h.sess, email); err != nil {
+		return h.errMsg(err.Error())
+	}
+	if err := h.sess.Destroy(ctx); err != nil {
+		return h.errMsg(err.Error())
+	}
+
+	clearSessionData(c)
+	return pageRendererWithFlashMsg(c, "signin", "account deleted")
+}
+
+func (h *Handler) RequestExport(c echo.Context) error {
+	ctx := c.Request().Context()
+	email := h.sess.GetString(ctx, contextKeyEmail)
+	sessions, err := listSessions(ctx, h.sess, email)
+	if err != nil {
+		return h.errTmpl("account", err.Error())
+	}
+
+	exportSessions := make([]user.ExportSession, len(sessions))
+	for i, s := range sessions {
+		exportSessions[i] = user.ExportSession{
+			UserAgent: s.UserAgent,
+			IP:        s.IP,
+			CreatedAt: s.CreatedAt,
+			LastSeen:  s.LastSeen,
+		}
+	}
+
+	if err := h.service.User().RequestExport(ctx, h.baseURL, email, exportSessions); err != nil {
+		return h.errTmpl("account", err.Error())
+	}
+
+	return pageRendererWithFlashMsg(c, "account", "your export is being generated, check your mailbox")
+}
+
+func (h *Handler) DownloadExport(c echo.Context) error {
+	token := c.Param("token")
+
+	ctx := c.Request().Context()
+	email := h.sess.GetString(ctx, contextKeyEmail)
+	filename, err := h.service.User().GetExport(ctx, email, token)
+	if err != nil {
+		return h.errTmpl("account", user.ErrExportNotFound.Error())
+	}
+
+	return c.Attachment(filename, "dimdim-export.zip")
+}
+
+func sessionDataMiddleware(
+	sessionManager *scs.SessionManager,
+	users *user.Service,
+	appName string,
+) echo.MiddlewareFunc {
 	return func(next echo.HandlerFunc) echo.HandlerFunc {
 		return func(c echo.Context) error {
 			req := c.Request()
@@ -386,19 +597,45 @@ func sessionDataMiddleware(sessionManager *scs.SessionManager, users *user.Servi
 				return next(c)
 			}
 
-			sessionData := SessionData{AppName: appName}
+			sessionData := SessionData{
+				AppName:      appName,
+				Registration: string(users.Registration()),
+				Preferences:  user.DefaultPreferences(),
+				Locale:       embeded.MatchLocale(req.Header.Get("Accept-Language")),
+			}
 
 			ctx := req.Context()
 			email := sessionManager.GetString(ctx, contextKeyEmail)
 			if email != "" {
 				user, err := users.GetUser(ctx, email)
 				if err != nil {
-					// TODO: need to clear the session/cookie and redirect to signin.
-					panic(fmt.Sprintf("failed to get user with email %q: %v", email, err))
+					if !storage.NoRows(err) {
+						return err
+					}
+
+					// The email of the session was changed or the account deleted
+					// by another session.
+					if err := sessionManager.Destroy(ctx); err != nil {
+						return err
+					}
+
+					return c.Redirect(http.StatusSeeOther, "/auth/signin")
 				}
 
-				sessionData.Email = user.Email
-				sessionData.Name = user.Name
+				if user.Disabled {
+					// The account was disabled after the session was created.
+					if err := sessionManager.Destroy(ctx); err != nil {
+						return err
+					}
+				} else {
+					sessionData.Email = user.Email
+					sessionData.Name = user.Name
+					sessionData.Admin = user.Admin
+					sessionData.Preferences = user.Preferences
+					sessionData.Locale = user.Preferences.Locale
+
+					touchSession(c, sessionManager)
+				}
 			}
 			tk, ok := c.Get("csc").(string)
 			if ok {
@@ -422,6 +659,27 @@ func signedInMiddleware(next echo.HandlerFunc) echo.HandlerFunc {
 	}
 }
 
+// adminMiddleware checks the role in the database, since the session data comes
+// from the user cache that may not have seen a role revoked by the admin command.
+func (h *Handler) adminMiddleware(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		sess, _ := c.Get("sessionData").(SessionData)
+		if !sess.SignedIn() {
+			return c.Redirect(http.StatusSeeOther, "/auth/signin")
+		}
+
+		admin, err := h.service.User().IsAdmin(c.Request().Context(), sess.Email)
+		if err != nil {
+			return err
+		}
+		if !admin {
+			return echo.ErrNotFound
+		}
+
+		return next(c)
+	}
+}
+
 func signedOutMiddleware(next echo.HandlerFunc) echo.HandlerFunc {
 	return func(c echo.Context) error {
 		sess, _ := c.Get("sessionData").(SessionData)
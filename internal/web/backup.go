@@ -0,0 +1,63 @@
+package web
+
+import (
+	"bytes"
+	"fmt"
+	"mime"
+	"net/http"
+	"time"
+
+	"github.com/garnizeH/dimdim/service/backup"
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	// backupPath is where the backups are downloaded from and uploaded to.
+	backupPath = "/auth/backup"
+	// maxBackupSize is the body limit of the backup uploads, the only
+	// requests allowed over the default limit.
+	maxBackupSize = "10M"
+)
+
+// isBackupUpload reports whether the request uploads a backup.
+func isBackupUpload(c echo.Context) bool {
+	return c.Request().Method == http.MethodPost && c.Request().URL.Path == backupPath
+}
+
+// DownloadBackup downloads the backup of the bills and payees of the user,
+// to be imported on this or another instance.
+func (h *Handler) DownloadBackup(c echo.Context) error {
+	var buf bytes.Buffer
+	if err := h.service.Backup().Export(c.Request().Context(), getSessionData(c).Email, &buf); err != nil {
+		return h.errTmpl("account", err.Error())
+	}
+
+	filename := fmt.Sprintf("dimdim-backup-%s.zip", time.Now().Format(time.DateOnly))
+	c.Response().Header().Set(echo.HeaderContentDisposition, mime.FormatMediaType("attachment", map[string]string{"filename": filename}))
+	return c.Blob(http.StatusOK, "application/zip", buf.Bytes())
+}
+
+// ImportBackup restores the uploaded backup into the account of the user,
+// which must have no bills or payees.
+func (h *Handler) ImportBackup(c echo.Context) error {
+	fh, err := c.FormFile("backup")
+	if err != nil {
+		return h.errTmpl("account", backup.ErrInvalidBackup.Error())
+	}
+
+	f, err := fh.Open()
+	if err != nil {
+		return h.errTmpl("account", backup.ErrInvalidBackup.Error())
+	}
+	defer f.Close()
+
+	email := getSessionData(c).Email
+	if _, err := h.service.Backup().Import(c.Request().Context(), email, f, fh.Size); err != nil {
+		return h.errTmpl("account", err.Error())
+	}
+
+	// The preferences of the backup replace the cached ones.
+	h.service.User().ForgetUser(email)
+
+	return pageRendererWithFlashMsg(c, "account", "backup imported")
+}
@@ -0,0 +1,300 @@
+package web
+
+import (
+	"strings"
+	"time"
+
+	"github.com/garnizeH/dimdim/embeded"
+	"github.com/garnizeH/dimdim/pkg/boleto"
+	"github.com/garnizeH/dimdim/pkg/money"
+	"github.com/garnizeH/dimdim/pkg/pix"
+	"github.com/garnizeH/dimdim/service/bill"
+	"github.com/garnizeH/dimdim/service/user"
+	"github.com/labstack/echo/v4"
+	"github.com/microcosm-cc/bluemonday"
+)
+
+const (
+	defaultRemindDays = 3
+
+	// pixPrefix is the payload format indicator that starts every Pix code.
+	pixPrefix = "000201"
+)
+
+type billView struct {
+	bill.Bill
+	Overdue bool
+	// Payee is the name of the payee of the bill, if any.
+	Payee string
+}
+
+// billForm is the content of the new bill form.
+type billForm struct {
+	Name       string
+	Amount     string
+	Currency   string
+	DueOn      string
+	RemindDays int
+	Barcode    string
+	Pix        string
+}
+
+func newBillForm(prefs user.Preferences) billForm {
+	return billForm{
+		Currency:   prefs.Currency,
+		RemindDays: defaultRemindDays,
+	}
+}
+
+// newBillFormFromBoleto fills the new bill form with the information of the boleto.
+func newBillFormFromBoleto(sess SessionData, b boleto.Boleto, now time.Time) billForm {
+	form := newBillForm(sess.Preferences)
+	form.Currency = b.Amount.Currency
+	form.Barcode = b.DigitableLine()
+
+	switch b.Kind {
+	case boleto.KindBank:
+		form.Name = boleto.BankName(b.Bank)
+	case boleto.KindUtility:
+		form.Name = embeded.Translate(sess.Locale, b.Segment.String())
+	}
+	if !b.Amount.IsZero() {
+		form.Amount = money.FormatDecimal(sess.Locale, b.Amount.Amount, money.Exponent(b.Amount.Currency))
+	}
+	if due, ok := b.DueDate(now); ok {
+		form.DueOn = due.Format(bill.DueLayout)
+	}
+
+	return form
+}
+
+// newBillFormFromPix fills the new bill form with the information of the Pix code.
+func newBillFormFromPix(sess SessionData, p pix.Payment) billForm {
+	form := newBillForm(sess.Preferences)
+	form.Name = p.MerchantName
+	form.Currency = p.Amount.Currency
+	form.Pix = p.Payload
+	if !p.Amount.IsZero() {
+		form.Amount = money.FormatDecimal(sess.Locale, p.Amount.Amount, money.Exponent(p.Amount.Currency))
+	}
+
+	return form
+}
+
+// newBillFormFromCode fills the new bill form from a pasted boleto or Pix code.
+func newBillFormFromCode(sess SessionData, code string, now time.Time) (billForm, error) {
+	if strings.HasPrefix(code, pixPrefix) {
+		p, err := pix.Parse(code)
+		if err != nil {
+			return billForm{}, err
+		}
+
+		return newBillFormFromPix(sess, p), nil
+	}
+
+	b, err := boleto.Parse(code)
+	if err != nil {
+		return billForm{}, err
+	}
+
+	return newBillFormFromBoleto(sess, b, now), nil
+}
+
+type billsFields struct {
+	Bills []billView
+	Form  billForm
+
+	MaxRemindDays int
+	Currencies    []string
+}
+
+// setBillsFields loads the bills of the user and the content of the new bill form.
+func (h *Handler) setBillsFields(c echo.Context, form billForm) error {
+	sess := getSessionData(c)
+	bills, err := h.service.Bill().ListBills(c.Request().Context(), sess.Email)
+	if err != nil {
+		return err
+	}
+
+	payees, err := h.service.Payee().List(c.Request().Context(), sess.Email)
+	if err != nil {
+		return err
+	}
+	names := make(map[int64]string, len(payees))
+	for _, p := range payees {
+		names[p.ID] = p.Name
+	}
+
+	now := time.Now()
+	views := make([]billView, len(bills))
+	for i, b := range bills {
+		views[i] = billView{
+			Bill:    b,
+			Overdue: b.Overdue(now),
+			Payee:   names[b.PayeeID],
+		}
+	}
+
+	setSessionDataFields(c, billsFields{
+		Bills: views,
+		Form:  form,
+
+		MaxRemindDays: bill.MaxRemindDays,
+		Currencies:    user.Currencies,
+	})
+	return nil
+}
+
+type billsRequest struct {
+	Code string `query:"code"`
+}
+
+func (r *billsRequest) validate(c echo.Context, input *bluemonday.Policy) error {
+	r.Code = strings.TrimSpace(r.Code)
+
+	return nil
+}
+
+// Bills shows the bills of the user, with the new bill form filled from the
+// pasted boleto or Pix code when given.
+func (h *Handler) Bills(c echo.Context) error {
+	r := billsRequest{}
+	if err := h.validateRequest(c, &r); err != nil {
+		return err
+	}
+
+	sess := getSessionData(c)
+	form := newBillForm(sess.Preferences)
+	var codeErr error
+	if r.Code != "" {
+		codeForm, err := newBillFormFromCode(sess, r.Code, time.Now())
+		if err == nil {
+			form = codeForm
+		}
+		codeErr = err
+	}
+
+	if err := h.setBillsFields(c, form); err != nil {
+		return h.errMsg(err.Error())
+	}
+	if codeErr != nil {
+		return h.errTmpl("bills", codeErr.Error())
+	}
+
+	return pageRendererWithFlashMsg(c, "bills", "")
+}
+
+type createBillRequest struct {
+	Name       string `form:"name"`
+	Amount     string `form:"amount"`
+	Currency   string `form:"currency"`
+	DueOn      string `form:"due_on"`
+	RemindDays int    `form:"remind_days"`
+	Barcode    string `form:"barcode"`
+	Pix        string `form:"pix"`
+
+	amount money.Money
+}
+
+func (r *createBillRequest) form() billForm {
+	return billForm{
+		Name:       r.Name,
+		Amount:     r.Amount,
+		Currency:   r.Currency,
+		DueOn:      r.DueOn,
+		RemindDays: r.RemindDays,
+		Barcode:    r.Barcode,
+		Pix:        r.Pix,
+	}
+}
+
+func (r *createBillRequest) validate(c echo.Context, input *bluemonday.Policy) error {
+	r.Name = input.Sanitize(strings.TrimSpace(r.Name))
+	if r.Name == "" {
+		return bill.ErrInvalidBill
+	}
+
+	amount, err := money.Parse(r.Amount, strings.TrimSpace(r.Currency))
+	if err != nil {
+		return err
+	}
+	r.amount = amount
+
+	r.DueOn = strings.TrimSpace(r.DueOn)
+	r.Barcode = strings.TrimSpace(r.Barcode)
+	r.Pix = strings.TrimSpace(r.Pix)
+
+	return nil
+}
+
+// CreateBill creates the bill, keeping the content of the form when it fails.
+func (h *Handler) CreateBill(c echo.Context) error {
+	sess := getSessionData(c)
+	r := createBillRequest{}
+	err := c.Bind(&r)
+	if err == nil {
+		err = r.validate(c, h.input)
+	}
+	if err == nil {
+		ctx := c.Request().Context()
+		_, err = h.service.Bill().CreateBill(ctx, sess.Email, r.Name, r.amount, r.DueOn, r.RemindDays, r.Barcode, r.Pix)
+	}
+
+	form := newBillForm(sess.Preferences)
+	if err != nil {
+		form = r.form()
+	}
+	if fieldsErr := h.setBillsFields(c, form); fieldsErr != nil {
+		return h.errMsg(fieldsErr.Error())
+	}
+	if err != nil {
+		return h.errTmpl("bills", err.Error())
+	}
+
+	return pageRendererWithFlashMsg(c, "bills", "bill created")
+}
+
+type billRequest struct {
+	ID int64 `form:"id"`
+}
+
+func (r *billRequest) validate(c echo.Context, input *bluemonday.Policy) error {
+	if r.ID <= 0 {
+		return bill.ErrBillNotFound
+	}
+
+	return nil
+}
+
+// PayBill marks the bill as paid.
+func (h *Handler) PayBill(c echo.Context) error {
+	ctx := c.Request().Context()
+	return h.billAction(c, func(email string, id int64) error {
+		return h.service.Bill().MarkPaid(ctx, email, id)
+	}, "bill marked as paid")
+}
+
+func (h *Handler) DeleteBill(c echo.Context) error {
+	ctx := c.Request().Context()
+	return h.billAction(c, func(email string, id int64) error {
+		return h.service.Bill().DeleteBill(ctx, email, id)
+	}, "bill deleted")
+}
+
+func (h *Handler) billAction(c echo.Context, action func(email string, id int64) error, msg string) error {
+	r := billRequest{}
+	if err := h.validateRequest(c, &r); err != nil {
+		return err
+	}
+
+	sess := getSessionData(c)
+	err := action(sess.Email, r.ID)
+	if fieldsErr := h.setBillsFields(c, newBillForm(sess.Preferences)); fieldsErr != nil {
+		return h.errMsg(fieldsErr.Error())
+	}
+	if err != nil {
+		return h.errTmpl("bills", err.Error())
+	}
+
+	return pageRendererWithFlashMsg(c, "bills", msg)
+}
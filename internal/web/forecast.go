@@ -0,0 +1,153 @@
+package web
+
+import (
+	"html/template"
+	"strings"
+	"time"
+
+	"github.com/garnizeH/dimdim/embeded"
+	"github.com/garnizeH/dimdim/pkg/chart"
+	"github.com/garnizeH/dimdim/pkg/money"
+	"github.com/garnizeH/dimdim/service/report"
+	"github.com/garnizeH/dimdim/service/user"
+	"github.com/labstack/echo/v4"
+	"github.com/microcosm-cc/bluemonday"
+)
+
+// defaultForecastMonths is the number of months of the forecast when none is given.
+const defaultForecastMonths = 6
+
+// forecastForm is the filter of the forecast as typed, the balance included.
+type forecastForm struct {
+	Months   int
+	Currency string
+	Balance  string
+}
+
+type forecastFields struct {
+	Form forecastForm
+
+	MinMonths  int
+	MaxMonths  int
+	Currencies []string
+
+	// Forecast is nil when the filter is invalid.
+	Forecast  *report.Forecast
+	Rows      []reportPeriod
+	BarChart  template.HTML
+	LineChart template.HTML
+}
+
+type forecastRequest struct {
+	Months   int    `query:"months"`
+	Currency string `query:"currency"`
+	Balance  string `query:"balance"`
+}
+
+func (r *forecastRequest) validate(c echo.Context, input *bluemonday.Policy) error {
+	r.Currency = strings.TrimSpace(r.Currency)
+	r.Balance = strings.TrimSpace(r.Balance)
+	if r.Months < 0 {
+		return report.ErrInvalidFilter
+	}
+
+	return nil
+}
+
+// form returns the filter of the request, defaulting to six months in the
+// currency of the user without balance.
+func (r *forecastRequest) form(prefs user.Preferences) forecastForm {
+	f := forecastForm{
+		Months:   r.Months,
+		Currency: r.Currency,
+		Balance:  r.Balance,
+	}
+	if f.Months == 0 {
+		f.Months = defaultForecastMonths
+	}
+	if f.Currency == "" {
+		f.Currency = prefs.Currency
+	}
+
+	return f
+}
+
+// setForecastFields loads the forecast of the request, keeping the filter and
+// its options in the fields when the filter is invalid.
+func (h *Handler) setForecastFields(c echo.Context, r forecastRequest) error {
+	sess := getSessionData(c)
+	fields := forecastFields{
+		Form: r.form(sess.Preferences),
+
+		MinMonths:  report.MinForecastMonths,
+		MaxMonths:  report.MaxForecastMonths,
+		Currencies: user.Currencies,
+	}
+	setSessionDataFields(c, fields)
+
+	filter := report.ForecastFilter{
+		Months:   fields.Form.Months,
+		Currency: fields.Form.Currency,
+		Balance:  money.New(0, fields.Form.Currency),
+	}
+	if fields.Form.Balance != "" {
+		balance, err := money.Parse(fields.Form.Balance, fields.Form.Currency)
+		if err != nil {
+			return err
+		}
+		filter.Balance = balance
+	}
+
+	fc, err := h.service.Report().UnpaidBills(c.Request().Context(), sess.Email, filter, time.Now())
+	if err != nil {
+		return err
+	}
+
+	labels := make([]string, len(fc.Months))
+	values := make([]int64, len(fc.Months))
+	balances := make([]int64, len(fc.Months))
+	fields.Rows = make([]reportPeriod, len(fc.Months))
+	balance := filter.Balance.Amount
+	for i, m := range fc.Months {
+		labels[i] = periodLabel(sess.Preferences, report.PeriodMonth, m.Start)
+		values[i] = m.Amount.Amount
+		balance -= m.Amount.Amount
+		balances[i] = balance
+		fields.Rows[i] = reportPeriod{Total: m, Label: labels[i]}
+	}
+
+	format := func(v int64) string {
+		return money.New(v, filter.Currency).Format(sess.Locale)
+	}
+	fields.Forecast = &fc
+	fields.BarChart = chart.Chart{
+		Title:  embeded.Translate(sess.Locale, "Due by month"),
+		Labels: labels,
+		Values: values,
+		Format: format,
+	}.Bar()
+	fields.LineChart = chart.Chart{
+		Title:  embeded.Translate(sess.Locale, "Balance at the end of the month"),
+		Labels: labels,
+		Values: balances,
+		Format: format,
+	}.Line()
+
+	setSessionDataFields(c, fields)
+	return nil
+}
+
+// Forecast shows the unpaid bills of the user by due date, and when the
+// balance runs out paying them.
+func (h *Handler) Forecast(c echo.Context) error {
+	r := forecastRequest{}
+	if err := h.validateRequest(c, &r, "forecast"); err != nil {
+		return err
+	}
+
+	if err := h.setForecastFields(c, r); err != nil {
+		return h.errTmpl("forecast", err.Error())
+	}
+
+	return pageRendererWithFlashMsg(c, "forecast", "")
+}
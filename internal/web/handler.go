@@ -3,6 +3,7 @@ package web
 import (
 	"bytes"
 	"fmt"
+	"io"
 	"net/http"
 	"strings"
 
@@ -10,18 +11,28 @@ import (
 	"github.com/garnizeH/dimdim/embeded"
 	"github.com/garnizeH/dimdim/pkg/domain"
 	"github.com/garnizeH/dimdim/service"
+	"github.com/garnizeH/dimdim/service/bill"
+	"github.com/garnizeH/dimdim/service/notification"
+	"github.com/garnizeH/dimdim/service/user"
 	"github.com/labstack/echo/v4"
 	"github.com/microcosm-cc/bluemonday"
 )
 
 type SessionData struct {
-	AppName   string
-	Email     string
-	Name      string
-	ErrMsg    string
-	FlashMsg  string
-	CSRFToken string
-	Fields    any
+	AppName       string
+	Registration  string
+	Email         string
+	Name          string
+	Admin         bool
+	Preferences   user.Preferences
+	Locale        string
+	Notifications int64
+	// SavedSearches are the saved searches of the user, shown in the menu.
+	SavedSearches []bill.SavedSearch
+	ErrMsg        string
+	FlashMsg      string
+	CSRFToken     string
+	Fields        any
 }
 
 func (sd SessionData) SignedIn() bool {
@@ -53,9 +64,60 @@ func (h *Handler) LoadRoutes(e *echo.Echo, templates *embeded.Template) {
 	templates.NewView("index", "base.tmpl", "menu.tmpl", "messages.tmpl", "index.tmpl")
 	e.GET("/", pageRenderer("index"), signedInMiddleware)
 
+	// profile
+	templates.NewView("profile", "base.tmpl", "menu.tmpl", "messages.tmpl", "profile.tmpl")
+	e.GET("/profile", h.Profile, signedInMiddleware)
+	e.POST("/profile", h.UpdateProfile, signedInMiddleware)
+	e.POST("/profile/tokens", h.CreateToken, signedInMiddleware)
+	e.POST("/profile/tokens/revoke", h.RevokeToken, signedInMiddleware)
+
+	// bills
+	templates.NewView("bills", "base.tmpl", "menu.tmpl", "messages.tmpl", "bills.tmpl")
+	e.GET("/bills", h.Bills, signedInMiddleware)
+	e.POST("/bills", h.CreateBill, signedInMiddleware)
+	e.POST("/bills/pay", h.PayBill, signedInMiddleware)
+	e.POST("/bills/delete", h.DeleteBill, signedInMiddleware)
+
+	// search
+	templates.NewView("search", "base.tmpl", "menu.tmpl", "messages.tmpl", "search.tmpl")
+	e.GET("/bills/search", h.Search, signedInMiddleware)
+	e.POST("/bills/search/save", h.SaveSearch, signedInMiddleware)
+	e.POST("/bills/search/pin", h.PinSavedSearch, signedInMiddleware)
+	e.POST("/bills/search/unpin", h.UnpinSavedSearch, signedInMiddleware)
+	e.POST("/bills/search/delete", h.DeleteSavedSearch, signedInMiddleware)
+
+	// payees
+	templates.NewView("payees", "base.tmpl", "menu.tmpl", "messages.tmpl", "payees.tmpl")
+	e.GET("/payees", h.Payees, signedInMiddleware)
+	e.POST("/payees", h.CreatePayee, signedInMiddleware)
+	e.POST("/payees/update", h.UpdatePayee, signedInMiddleware)
+	e.POST("/payees/delete", h.DeletePayee, signedInMiddleware)
+	e.POST("/payees/aliases", h.AddPayeeAlias, signedInMiddleware)
+	e.POST("/payees/aliases/delete", h.RemovePayeeAlias, signedInMiddleware)
+	e.POST("/payees/merge", h.MergePayees, signedInMiddleware)
+
+	// reports
+	templates.NewView("reports", "base.tmpl", "menu.tmpl", "messages.tmpl", "reports.tmpl")
+	e.GET("/reports", h.Reports, signedInMiddleware)
+	e.GET("/reports/pdf", h.ReportPDF, signedInMiddleware)
+	templates.NewView("forecast", "base.tmpl", "menu.tmpl", "messages.tmpl", "forecast.tmpl")
+	e.GET("/reports/forecast", h.Forecast, signedInMiddleware)
+
+	// notifications
+	templates.NewView("notifications", "base.tmpl", "menu.tmpl", "messages.tmpl", "notifications.tmpl")
+	e.GET("/notifications", h.Notifications, signedInMiddleware)
+
+	// api
+	api := e.Group(apiPrefix)
+	h.loadRoutesAPI(api)
+
 	// auth
 	auth := e.Group("/auth")
 	h.loadRoutesAuth(auth, templates)
+
+	// admin
+	admin := e.Group("/admin", h.adminMiddleware)
+	h.loadRoutesAdmin(admin, templates)
 }
 
 func (h *Handler) loadRoutesAuth(g *echo.Group, templates *embeded.Template) {
@@ -74,7 +136,7 @@ func (h *Handler) loadRoutesAuth(g *echo.Group, templates *embeded.Template) {
 
 	// signup
 	templates.NewView("signup", "base.tmpl", "messages.tmpl", "auth-links.tmpl", "auth/signup.tmpl")
-	g.GET("/signup", pageRenderer("signup"), signedOutMiddleware)
+	g.GET("/signup", h.SignupPage, signedOutMiddleware)
 	g.GET("/signup/:token", h.SignupToken)
 	g.POST("/signup", h.Signup, signedOutMiddleware)
 	g.GET("/signout", h.Signout, signedInMiddleware)
@@ -88,6 +150,28 @@ func (h *Handler) loadRoutesAuth(g *echo.Group, templates *embeded.Template) {
 	templates.NewView("change-password", "base.tmpl", "messages.tmpl", "auth/change-password.tmpl")
 	g.GET("/change-password", pageRenderer("change-password"), signedInMiddleware)
 	g.POST("/change-password", h.ChangePassword, signedInMiddleware)
+
+	// change email
+	templates.NewView("change-email", "base.tmpl", "messages.tmpl", "auth/change-email.tmpl")
+	g.GET("/change-email", pageRenderer("change-email"), signedInMiddleware)
+	g.GET("/change-email/:token", h.ChangeEmailToken)
+	g.GET("/cancel-email-change/:token", h.CancelEmailChange)
+	g.POST("/change-email", h.ChangeEmail, signedInMiddleware)
+
+	// account data
+	templates.NewView("account", "base.tmpl", "menu.tmpl", "messages.tmpl", "auth/account.tmpl")
+	g.GET("/account", pageRenderer("account"), signedInMiddleware)
+	g.POST("/delete-account", h.DeleteAccount, signedInMiddleware)
+	g.POST("/export", h.RequestExport, signedInMiddleware)
+	g.GET("/export/:token", h.DownloadExport, signedInMiddleware)
+	g.GET("/backup", h.DownloadBackup, signedInMiddleware)
+	g.POST("/backup", h.ImportBackup, signedInMiddleware)
+
+	// sessions
+	templates.NewView("sessions", "base.tmpl", "menu.tmpl", "messages.tmpl", "auth/sessions.tmpl")
+	g.GET("/sessions", h.Sessions, signedInMiddleware)
+	g.POST("/sessions/revoke", h.RevokeSession, signedInMiddleware)
+	g.POST("/sessions/revoke-others", h.RevokeOtherSessions, signedInMiddleware)
 }
 
 type validator interface {
@@ -147,7 +231,7 @@ func pageRendererWithFlashMsg(c echo.Context, page, msg string) error {
 	return c.Render(http.StatusOK, page, sess)
 }
 
-func errorHandler(template *embeded.Template) func(error, echo.Context) {
+func errorHandler(renderer menuRenderer) func(error, echo.Context) {
 	return func(err error, c echo.Context) {
 		if c.Response().Committed {
 			return
@@ -173,12 +257,24 @@ func errorHandler(template *embeded.Template) func(error, echo.Context) {
 		if strings.HasPrefix(c.Request().URL.Path, "/static") {
 			return
 		}
+		if isAPIRequest(c) {
+			apiErrorHandler(err, c)
+			return
+		}
 
 		sess := getSessionData(c)
 		sess.ErrMsg = msg
+		if sess.Locale == "" {
+			// The request failed before the session data was loaded.
+			sess.Locale = embeded.MatchLocale(c.Request().Header.Get("Accept-Language"))
+		}
 
 		buf := bytes.Buffer{}
-		template.Render(&buf, tmpl, sess, c)
+		if err := renderer.Render(&buf, tmpl, sess, c); err != nil {
+			// The menu failed to load, so the error is shown without it.
+			buf.Reset()
+			renderer.templates.Render(&buf, tmpl, sess, c)
+		}
 		m := buf.String()
 		if err := c.HTML(code, m); err != nil {
 			panic(fmt.Sprintf("failed to return html code: %v", err))
@@ -186,11 +282,48 @@ func errorHandler(template *embeded.Template) func(error, echo.Context) {
 	}
 }
 
+// menuRenderer loads the notifications and the saved searches shown in the
+// menu of the signed in users only when a page is rendered, so the redirects,
+// the API and the static files do not query them.
+type menuRenderer struct {
+	templates     *embeded.Template
+	notifications *notification.Service
+	bills         *bill.Service
+}
+
+func (r menuRenderer) Render(w io.Writer, name string, data any, c echo.Context) error {
+	if sess, ok := data.(SessionData); ok && sess.SignedIn() {
+		ctx := c.Request().Context()
+		unread, err := r.notifications.CountUnread(ctx, sess.Email)
+		if err != nil {
+			return err
+		}
+		sess.Notifications = unread
+
+		searches, err := r.bills.ListSavedSearches(ctx, sess.Email)
+		if err != nil {
+			return err
+		}
+		sess.SavedSearches = searches
+		data = sess
+	}
+
+	return r.templates.Render(w, name, data, c)
+}
+
 func getSessionData(c echo.Context) SessionData {
 	common, _ := c.Get("sessionData").(SessionData)
 	return common
 }
 
+func clearSessionData(c echo.Context) {
+	common := getSessionData(c)
+	common.Email = ""
+	common.Name = ""
+	common.Admin = false
+	c.Set("sessionData", common)
+}
+
 func setSessionDataFields(c echo.Context, fields any) {
 	common := getSessionData(c)
 	common.Fields = fields
@@ -0,0 +1,131 @@
+package web_test
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io/fs"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/garnizeH/dimdim/embeded"
+)
+
+// messageDirs are the packages whose errors and flash messages are shown to the users.
+var messageDirs = []string{
+	".",
+	"../../pkg/boleto",
+	"../../pkg/money",
+	"../../pkg/pix",
+	"../../pkg/taxid",
+	"../../service",
+	"../../service/accesstoken",
+	"../../service/backup",
+	"../../service/bill",
+	"../../service/invite",
+	"../../service/outbox",
+	"../../service/payee",
+	"../../service/report",
+	"../../service/user",
+}
+
+// flashCalls maps the functions that render a flash message to the position of the message argument.
+var flashCalls = map[string]int{
+	"pageRendererWithFlashMsg": 2,
+	"adminUserAction":          2,
+	"billAction":               2,
+	"savedSearchAction":        2,
+	"payeeAction":              3,
+}
+
+// userMessages collects the sentinel errors declared with errors.New and the
+// literal flash messages of the packages.
+func userMessages(t *testing.T) map[string]string {
+	t.Helper()
+
+	msgs := map[string]string{}
+	fset := token.NewFileSet()
+	for _, dir := range messageDirs {
+		pkgs, err := parser.ParseDir(fset, dir, func(fi fs.FileInfo) bool {
+			return !strings.HasSuffix(fi.Name(), "_test.go")
+		}, 0)
+		if err != nil {
+			t.Fatalf("failed to parse the package %q: %v", dir, err)
+		}
+
+		for _, pkg := range pkgs {
+			ast.Inspect(pkg, func(n ast.Node) bool {
+				switch n := n.(type) {
+				case *ast.ValueSpec:
+					for i, name := range n.Names {
+						if i < len(n.Values) && name.IsExported() && isErrorsNew(n.Values[i]) {
+							if msg, ok := stringArg(n.Values[i].(*ast.CallExpr), 0); ok {
+								msgs[msg] = fset.Position(n.Pos()).String()
+							}
+						}
+					}
+				case *ast.CallExpr:
+					ident, ok := n.Fun.(*ast.Ident)
+					if !ok {
+						if sel, ok := n.Fun.(*ast.SelectorExpr); ok {
+							ident = sel.Sel
+						}
+					}
+					if ident == nil {
+						return true
+					}
+					if pos, ok := flashCalls[ident.Name]; ok {
+						if msg, ok := stringArg(n, pos); ok && msg != "" {
+							msgs[msg] = fset.Position(n.Pos()).String()
+						}
+					}
+				}
+				return true
+			})
+		}
+	}
+
+	return msgs
+}
+
+func isErrorsNew(expr ast.Expr) bool {
+	call, ok := expr.(*ast.CallExpr)
+	if !ok {
+		return false
+	}
+	sel, ok := call.Fun.(*ast.SelectorExpr)
+	if !ok {
+		return false
+	}
+	pkg, ok := sel.X.(*ast.Ident)
+	return ok && pkg.Name == "errors" && sel.Sel.Name == "New"
+}
+
+func stringArg(call *ast.CallExpr, pos int) (string, bool) {
+	if pos >= len(call.Args) {
+		return "", false
+	}
+	lit, ok := call.Args[pos].(*ast.BasicLit)
+	if !ok || lit.Kind != token.STRING {
+		return "", false
+	}
+
+	msg, err := strconv.Unquote(lit.Value)
+	return msg, err == nil
+}
+
+func TestCatalogsCoverMessages(t *testing.T) {
+	msgs := userMessages(t)
+	if len(msgs) == 0 {
+		t.Fatal("found no user messages")
+	}
+
+	for _, locale := range embeded.Locales() {
+		for msg, pos := range msgs {
+			if _, ok := embeded.Lookup(locale, msg); !ok {
+				t.Errorf("locale %s is missing the message %q declared at %s", locale, msg, pos)
+			}
+		}
+	}
+}
@@ -0,0 +1,31 @@
+package web
+
+import (
+	"slices"
+
+	"github.com/garnizeH/dimdim/service/notification"
+	"github.com/labstack/echo/v4"
+)
+
+// Notifications shows the notification center and marks every notification as read.
+func (h *Handler) Notifications(c echo.Context) error {
+	ctx := c.Request().Context()
+	sess := getSessionData(c)
+	notifications, err := h.service.Notification().List(ctx, sess.Email)
+	if err != nil {
+		return h.errMsg(err.Error())
+	}
+
+	if slices.ContainsFunc(notifications, func(n notification.Notification) bool { return !n.Read }) {
+		if err := h.service.Notification().MarkAllRead(ctx, sess.Email); err != nil {
+			return h.errMsg(err.Error())
+		}
+	}
+
+	setSessionDataFields(c, struct {
+		Notifications []notification.Notification
+	}{
+		Notifications: notifications,
+	})
+	return pageRendererWithFlashMsg(c, "notifications", "")
+}
@@ -0,0 +1,158 @@
+package web
+
+import (
+	"strings"
+
+	"github.com/garnizeH/dimdim/service/payee"
+	"github.com/labstack/echo/v4"
+	"github.com/microcosm-cc/bluemonday"
+)
+
+type payeesFields struct {
+	Payees []payee.Payee
+}
+
+func (h *Handler) setPayeesFields(c echo.Context) error {
+	payees, err := h.service.Payee().List(c.Request().Context(), getSessionData(c).Email)
+	if err != nil {
+		return err
+	}
+
+	setSessionDataFields(c, payeesFields{
+		Payees: payees,
+	})
+	return nil
+}
+
+// Payees shows the payees of the user with their aliases.
+func (h *Handler) Payees(c echo.Context) error {
+	if err := h.setPayeesFields(c); err != nil {
+		return h.errMsg(err.Error())
+	}
+
+	return pageRendererWithFlashMsg(c, "payees", "")
+}
+
+type payeeRequest struct {
+	ID    int64  `form:"id"`
+	Name  string `form:"name"`
+	TaxID string `form:"tax_id"`
+}
+
+func (r *payeeRequest) validate(c echo.Context, input *bluemonday.Policy) error {
+	r.Name = input.Sanitize(strings.TrimSpace(r.Name))
+	if r.Name == "" {
+		return payee.ErrInvalidPayee
+	}
+	r.TaxID = strings.TrimSpace(r.TaxID)
+
+	return nil
+}
+
+func (h *Handler) CreatePayee(c echo.Context) error {
+	ctx := c.Request().Context()
+	r := payeeRequest{}
+	return h.payeeAction(c, &r, func(email string) error {
+		return h.service.Payee().Create(ctx, email, r.Name, r.TaxID)
+	}, "payee created")
+}
+
+func (h *Handler) UpdatePayee(c echo.Context) error {
+	ctx := c.Request().Context()
+	r := payeeRequest{}
+	return h.payeeAction(c, &r, func(email string) error {
+		return h.service.Payee().Update(ctx, email, r.ID, r.Name, r.TaxID)
+	}, "payee updated")
+}
+
+type payeeIDRequest struct {
+	ID int64 `form:"id"`
+}
+
+func (r *payeeIDRequest) validate(c echo.Context, input *bluemonday.Policy) error {
+	if r.ID <= 0 {
+		return payee.ErrPayeeNotFound
+	}
+
+	return nil
+}
+
+// DeletePayee deletes the payee, keeping its bills without payee.
+func (h *Handler) DeletePayee(c echo.Context) error {
+	ctx := c.Request().Context()
+	r := payeeIDRequest{}
+	return h.payeeAction(c, &r, func(email string) error {
+		return h.service.Payee().Delete(ctx, email, r.ID)
+	}, "payee deleted")
+}
+
+type payeeAliasRequest struct {
+	ID    int64  `form:"id"`
+	Alias string `form:"alias"`
+}
+
+func (r *payeeAliasRequest) validate(c echo.Context, input *bluemonday.Policy) error {
+	if r.ID <= 0 {
+		return payee.ErrPayeeNotFound
+	}
+	r.Alias = strings.TrimSpace(r.Alias)
+
+	return nil
+}
+
+func (h *Handler) AddPayeeAlias(c echo.Context) error {
+	ctx := c.Request().Context()
+	r := payeeAliasRequest{}
+	return h.payeeAction(c, &r, func(email string) error {
+		return h.service.Payee().AddAlias(ctx, email, r.ID, r.Alias)
+	}, "alias added")
+}
+
+// RemovePayeeAlias removes an alias or a Pix key, given by its id.
+func (h *Handler) RemovePayeeAlias(c echo.Context) error {
+	ctx := c.Request().Context()
+	r := payeeIDRequest{}
+	return h.payeeAction(c, &r, func(email string) error {
+		return h.service.Payee().RemoveAlias(ctx, email, r.ID)
+	}, "alias removed")
+}
+
+type mergePayeesRequest struct {
+	SourceID int64 `form:"source_id"`
+	TargetID int64 `form:"target_id"`
+}
+
+func (r *mergePayeesRequest) validate(c echo.Context, input *bluemonday.Policy) error {
+	if r.SourceID <= 0 || r.TargetID <= 0 {
+		return payee.ErrPayeeNotFound
+	}
+
+	return nil
+}
+
+// MergePayees merges the source payee into the target one, moving its aliases and bills.
+func (h *Handler) MergePayees(c echo.Context) error {
+	ctx := c.Request().Context()
+	r := mergePayeesRequest{}
+	return h.payeeAction(c, &r, func(email string) error {
+		return h.service.Payee().Merge(ctx, email, r.SourceID, r.TargetID)
+	}, "payees merged")
+}
+
+// payeeAction validates the request, runs the action reading it and renders
+// the payees page with the result.
+func (h *Handler) payeeAction(c echo.Context, r validator, action func(email string) error, msg string) error {
+	if err := h.validateRequest(c, r); err != nil {
+		return err
+	}
+
+	err := action(getSessionData(c).Email)
+	if fieldsErr := h.setPayeesFields(c); fieldsErr != nil {
+		return h.errMsg(fieldsErr.Error())
+	}
+	if err != nil {
+		return h.errTmpl("payees", err.Error())
+	}
+
+	return pageRendererWithFlashMsg(c, "payees", msg)
+}
@@ -0,0 +1,224 @@
+package web
+
+import (
+	"strings"
+	"time"
+
+	"github.com/garnizeH/dimdim/service/accesstoken"
+	"github.com/garnizeH/dimdim/service/user"
+	"github.com/labstack/echo/v4"
+	"github.com/microcosm-cc/bluemonday"
+)
+
+type profileFields struct {
+	Name           string
+	Locale         string
+	Timezone       string
+	Currency       string
+	FirstDayOfWeek int
+	DateFormat     string
+
+	Locales     []string
+	Currencies  []string
+	DateFormats []string
+
+	Tokens []accesstoken.Token
+	// NewToken is the secret of the token just created, shown only once.
+	NewToken string
+	Now      time.Time
+
+	TokenScopes        []string
+	TokenLifetimeDays  []int
+	MaxTokenNameLength int
+}
+
+// tokenLifetimeDays are the lifetimes offered for the new access tokens.
+var tokenLifetimeDays = []int{30, 90, 365}
+
+func newProfileFields(name string, prefs user.Preferences) profileFields {
+	return profileFields{
+		Name:           name,
+		Locale:         prefs.Locale,
+		Timezone:       prefs.Timezone,
+		Currency:       prefs.Currency,
+		FirstDayOfWeek: int(prefs.FirstDayOfWeek),
+		DateFormat:     prefs.DateFormat,
+
+		Locales:     user.Locales,
+		Currencies:  user.Currencies,
+		DateFormats: user.DateFormats,
+
+		TokenScopes:        accesstoken.Scopes,
+		TokenLifetimeDays:  tokenLifetimeDays,
+		MaxTokenNameLength: accesstoken.MaxNameLength,
+	}
+}
+
+// setProfileFields sets the fields of the profile page with the access tokens
+// of the user.
+func (h *Handler) setProfileFields(c echo.Context, fields profileFields) error {
+	tokens, err := h.service.AccessToken().List(c.Request().Context(), getSessionData(c).Email)
+	if err != nil {
+		return err
+	}
+
+	fields.Tokens = tokens
+	fields.Now = time.Now()
+	setSessionDataFields(c, fields)
+	return nil
+}
+
+func (h *Handler) Profile(c echo.Context) error {
+	sess := getSessionData(c)
+	if err := h.setProfileFields(c, newProfileFields(sess.Name, sess.Preferences)); err != nil {
+		return h.errMsg(err.Error())
+	}
+
+	return pageRendererWithFlashMsg(c, "profile", "")
+}
+
+type profileRequest struct {
+	Name           string `form:"name"`
+	Locale         string `form:"locale"`
+	Timezone       string `form:"timezone"`
+	Currency       string `form:"currency"`
+	FirstDayOfWeek int    `form:"first_day_of_week"`
+	DateFormat     string `form:"date_format"`
+}
+
+func (r *profileRequest) validate(c echo.Context, input *bluemonday.Policy) error {
+	r.Name = input.Sanitize(strings.TrimSpace(r.Name))
+	if r.Name == "" {
+		return ErrInvalidName
+	}
+
+	r.Locale = strings.TrimSpace(r.Locale)
+	r.Timezone = strings.TrimSpace(r.Timezone)
+	r.Currency = strings.TrimSpace(r.Currency)
+	r.DateFormat = strings.TrimSpace(r.DateFormat)
+
+	return nil
+}
+
+func (r *profileRequest) preferences() user.Preferences {
+	return user.Preferences{
+		Locale:         r.Locale,
+		Timezone:       r.Timezone,
+		Currency:       r.Currency,
+		FirstDayOfWeek: time.Weekday(r.FirstDayOfWeek),
+		DateFormat:     r.DateFormat,
+	}
+}
+
+func (h *Handler) UpdateProfile(c echo.Context) error {
+	r := profileRequest{}
+
+	setFields := func() error {
+		return h.setProfileFields(c, newProfileFields(r.Name, r.preferences()))
+	}
+
+	if err := h.validateRequest(c, &r, "profile"); err != nil {
+		if fieldsErr := setFields(); fieldsErr != nil {
+			return h.errMsg(fieldsErr.Error())
+		}
+		return err
+	}
+
+	ctx := c.Request().Context()
+	email := h.sess.GetString(ctx, contextKeyEmail)
+	u, err := h.service.User().UpdateProfile(ctx, email, r.Name, r.preferences())
+	if err != nil {
+		if fieldsErr := setFields(); fieldsErr != nil {
+			return h.errMsg(fieldsErr.Error())
+		}
+
+		return h.errTmpl("profile", err.Error())
+	}
+
+	sess := getSessionData(c)
+	sess.Name = u.Name
+	sess.Preferences = u.Preferences
+	sess.Locale = u.Preferences.Locale
+	c.Set("sessionData", sess)
+	if err := h.setProfileFields(c, newProfileFields(u.Name, u.Preferences)); err != nil {
+		return h.errMsg(err.Error())
+	}
+
+	return pageRendererWithFlashMsg(c, "profile", "profile updated")
+}
+
+type createTokenRequest struct {
+	Name         string `form:"name"`
+	Scope        string `form:"scope"`
+	LifetimeDays int    `form:"lifetime_days"`
+}
+
+func (r *createTokenRequest) validate(c echo.Context, input *bluemonday.Policy) error {
+	r.Name = input.Sanitize(strings.TrimSpace(r.Name))
+	if r.Name == "" {
+		return accesstoken.ErrInvalidName
+	}
+
+	r.Scope = strings.TrimSpace(r.Scope)
+
+	return nil
+}
+
+// CreateToken creates an access token of the API, showing its secret once.
+func (h *Handler) CreateToken(c echo.Context) error {
+	sess := getSessionData(c)
+	fields := newProfileFields(sess.Name, sess.Preferences)
+
+	r := createTokenRequest{}
+	err := c.Bind(&r)
+	if err == nil {
+		err = r.validate(c, h.input)
+	}
+	if err == nil {
+		lifetime := time.Duration(r.LifetimeDays) * 24 * time.Hour
+		_, fields.NewToken, err = h.service.AccessToken().Create(c.Request().Context(), sess.Email, r.Name, r.Scope, lifetime)
+	}
+
+	if fieldsErr := h.setProfileFields(c, fields); fieldsErr != nil {
+		return h.errMsg(fieldsErr.Error())
+	}
+	if err != nil {
+		return h.errTmpl("profile", err.Error())
+	}
+
+	return pageRendererWithFlashMsg(c, "profile", "access token created")
+}
+
+type tokenRequest struct {
+	ID int64 `form:"id"`
+}
+
+func (r *tokenRequest) validate(c echo.Context, input *bluemonday.Policy) error {
+	if r.ID <= 0 {
+		return accesstoken.ErrTokenNotFound
+	}
+
+	return nil
+}
+
+// RevokeToken deletes the access token, which stops working at once.
+func (h *Handler) RevokeToken(c echo.Context) error {
+	sess := getSessionData(c)
+	r := tokenRequest{}
+	err := c.Bind(&r)
+	if err == nil {
+		err = r.validate(c, h.input)
+	}
+	if err == nil {
+		err = h.service.AccessToken().Revoke(c.Request().Context(), sess.Email, r.ID)
+	}
+
+	if fieldsErr := h.setProfileFields(c, newProfileFields(sess.Name, sess.Preferences)); fieldsErr != nil {
+		return h.errMsg(fieldsErr.Error())
+	}
+	if err != nil {
+		return h.errTmpl("profile", err.Error())
+	}
+
+	return pageRendererWithFlashMsg(c, "profile", "access token revoked")
+}
@@ -0,0 +1,177 @@
+package web
+
+import (
+	"html/template"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/garnizeH/dimdim/embeded"
+	"github.com/garnizeH/dimdim/pkg/chart"
+	"github.com/garnizeH/dimdim/pkg/money"
+	"github.com/garnizeH/dimdim/service/payee"
+	"github.com/garnizeH/dimdim/service/report"
+	"github.com/garnizeH/dimdim/service/user"
+	"github.com/labstack/echo/v4"
+	"github.com/microcosm-cc/bluemonday"
+)
+
+// defaultReportMonths is the number of months of the report when no dates are given.
+const defaultReportMonths = 12
+
+// reportPeriod is a row of the report, with the label of its period.
+type reportPeriod struct {
+	report.Total
+	Label string
+}
+
+type reportsFields struct {
+	Form report.Filter
+
+	Periods    []report.Period
+	Currencies []string
+	Payees     []payee.Payee
+
+	// Report is nil when the filter is invalid.
+	Report    *report.Report
+	Rows      []reportPeriod
+	BarChart  template.HTML
+	LineChart template.HTML
+}
+
+type reportRequest struct {
+	From     string `query:"from"`
+	To       string `query:"to"`
+	Period   string `query:"period"`
+	Currency string `query:"currency"`
+	PayeeID  int64  `query:"payee"`
+}
+
+func (r *reportRequest) validate(c echo.Context, input *bluemonday.Policy) error {
+	r.From = strings.TrimSpace(r.From)
+	r.To = strings.TrimSpace(r.To)
+	r.Period = strings.TrimSpace(r.Period)
+	r.Currency = strings.TrimSpace(r.Currency)
+	if r.PayeeID < 0 {
+		return report.ErrInvalidFilter
+	}
+
+	return nil
+}
+
+// filter returns the filter of the request, defaulting to the last twelve
+// months by month in the currency of the user.
+func (r *reportRequest) filter(prefs user.Preferences, now time.Time) report.Filter {
+	f := report.Filter{
+		From:     r.From,
+		To:       r.To,
+		Period:   report.Period(r.Period),
+		Currency: r.Currency,
+		PayeeID:  r.PayeeID,
+	}
+
+	today := now.In(prefs.Location())
+	if f.To == "" {
+		f.To = today.Format(report.DateLayout)
+	}
+	if f.From == "" {
+		f.From = time.Date(today.Year(), today.Month()-defaultReportMonths+1, 1, 0, 0, 0, 0, today.Location()).Format(report.DateLayout)
+	}
+	if f.Period == "" {
+		f.Period = report.PeriodMonth
+	}
+	if f.Currency == "" {
+		f.Currency = prefs.Currency
+	}
+
+	return f
+}
+
+// periodLabel returns the label of the period starting at the given day, in
+// the locale and date format of the user.
+func periodLabel(prefs user.Preferences, period report.Period, start time.Time) string {
+	switch period {
+	case report.PeriodQuarter:
+		return embeded.Translate(prefs.Locale, "Q%d %d", (int(start.Month())+2)/3, start.Year())
+	case report.PeriodYear:
+		return strconv.Itoa(start.Year())
+	default:
+		if prefs.DateFormat == "YYYY-MM-DD" {
+			return start.Format("2006-01")
+		}
+		return start.Format("01/2006")
+	}
+}
+
+// setReportsFields loads the report of the request, keeping the filter and
+// its options in the fields when the filter is invalid.
+func (h *Handler) setReportsFields(c echo.Context, r reportRequest) (reportsFields, error) {
+	ctx := c.Request().Context()
+	sess := getSessionData(c)
+	payees, err := h.service.Payee().List(ctx, sess.Email)
+	if err != nil {
+		return reportsFields{}, err
+	}
+
+	fields := reportsFields{
+		Form: r.filter(sess.Preferences, time.Now()),
+
+		Periods:    report.Periods,
+		Currencies: user.Currencies,
+		Payees:     payees,
+	}
+
+	rep, err := h.service.Report().PaidBills(ctx, sess.Email, fields.Form)
+	if err != nil {
+		setSessionDataFields(c, fields)
+		return fields, err
+	}
+
+	labels := make([]string, len(rep.Periods))
+	values := make([]int64, len(rep.Periods))
+	cumulative := make([]int64, len(rep.Periods))
+	fields.Rows = make([]reportPeriod, len(rep.Periods))
+	for i, p := range rep.Periods {
+		labels[i] = periodLabel(sess.Preferences, rep.Filter.Period, p.Start)
+		values[i] = p.Amount.Amount
+		cumulative[i] = p.Amount.Amount
+		if i > 0 {
+			cumulative[i] += cumulative[i-1]
+		}
+		fields.Rows[i] = reportPeriod{Total: p, Label: labels[i]}
+	}
+
+	format := func(v int64) string {
+		return money.New(v, rep.Filter.Currency).Format(sess.Locale)
+	}
+	fields.Report = &rep
+	fields.BarChart = chart.Chart{
+		Title:  embeded.Translate(sess.Locale, "Paid by period"),
+		Labels: labels,
+		Values: values,
+		Format: format,
+	}.Bar()
+	fields.LineChart = chart.Chart{
+		Title:  embeded.Translate(sess.Locale, "Paid over time"),
+		Labels: labels,
+		Values: cumulative,
+		Format: format,
+	}.Line()
+
+	setSessionDataFields(c, fields)
+	return fields, nil
+}
+
+// Reports shows the paid bills of the user by period and by payee.
+func (h *Handler) Reports(c echo.Context) error {
+	r := reportRequest{}
+	if err := h.validateRequest(c, &r, "reports"); err != nil {
+		return err
+	}
+
+	if _, err := h.setReportsFields(c, r); err != nil {
+		return h.errTmpl("reports", err.Error())
+	}
+
+	return pageRendererWithFlashMsg(c, "reports", "")
+}
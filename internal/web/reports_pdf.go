@@ -0,0 +1,210 @@
+package web
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"mime"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/garnizeH/dimdim/embeded"
+	"github.com/garnizeH/dimdim/pkg/money"
+	"github.com/garnizeH/dimdim/pkg/pdf"
+	"github.com/garnizeH/dimdim/service/report"
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	pdfMargin     = 40.0
+	pdfLineHeight = 16.0
+	pdfChartSize  = 160.0
+	// pdfColumnWidth is the width of the columns of numbers in the tables.
+	pdfColumnWidth = 90.0
+	// pdfLabelWidth is the room given to each label under the chart.
+	pdfLabelWidth = 48.0
+)
+
+// ReportPDF downloads the report of the request as a PDF, laid out from the
+// same fields as the reports page.
+func (h *Handler) ReportPDF(c echo.Context) error {
+	r := reportRequest{}
+	if err := h.validateRequest(c, &r, "reports"); err != nil {
+		return err
+	}
+
+	fields, err := h.setReportsFields(c, r)
+	if err != nil {
+		return h.errTmpl("reports", err.Error())
+	}
+
+	var buf bytes.Buffer
+	if _, err := newReportPDF(getSessionData(c), fields).WriteTo(&buf); err != nil {
+		return h.errTmpl("reports", err.Error())
+	}
+
+	filename := fmt.Sprintf("dimdim-report-%s-%s.pdf", fields.Form.From, fields.Form.To)
+	c.Response().Header().Set(echo.HeaderContentDisposition, mime.FormatMediaType("attachment", map[string]string{"filename": filename}))
+	return c.Blob(http.StatusOK, "application/pdf", buf.Bytes())
+}
+
+// reportPDF lays out the report from the top of the pages down.
+type reportPDF struct {
+	doc    *pdf.Document
+	locale string
+	y      float64
+}
+
+// newReportPDF lays out the report with the summary, the chart by period and
+// the tables by period and by payee.
+func newReportPDF(sess SessionData, fields reportsFields) *pdf.Document {
+	locale := sess.Locale
+	rep := fields.Report
+	p := &reportPDF{
+		doc:    pdf.New(embeded.Translate(locale, "Paid bills")),
+		locale: locale,
+		y:      pdfMargin,
+	}
+	p.doc.AddPage()
+
+	p.text(pdf.Bold, 18, embeded.Translate(locale, "Paid bills"))
+	p.text(pdf.Regular, 10, embeded.Translate(locale, "From %s to %s", reportDate(sess, rep.Filter.From), reportDate(sess, rep.Filter.To)))
+	payee := embeded.Translate(locale, "All payees")
+	for _, py := range fields.Payees {
+		if py.ID == rep.Filter.PayeeID {
+			payee = py.Name
+		}
+	}
+	p.text(pdf.Regular, 10, rep.Filter.Currency+", "+payee)
+	p.y += pdfLineHeight / 2
+	p.text(pdf.Regular, 12, embeded.Translate(locale, "%d bills paid, %s in total.", rep.Count, rep.Total.Format(locale)))
+	p.y += pdfLineHeight / 2
+
+	p.chart(fields.Rows)
+
+	rows := make([][]string, len(fields.Rows))
+	for i, row := range fields.Rows {
+		rows[i] = []string{row.Label, strconv.Itoa(row.Count), row.Amount.Format(locale)}
+	}
+	p.table([]string{
+		embeded.Translate(locale, "Period"),
+		embeded.Translate(locale, "Bills"),
+		embeded.Translate(locale, "Amount"),
+	}, rows)
+
+	if len(rep.Payees) > 0 {
+		rows = make([][]string, len(rep.Payees))
+		for i, py := range rep.Payees {
+			name := py.Name
+			if name == "" {
+				name = embeded.Translate(locale, "No payee")
+			}
+			rows[i] = []string{name, strconv.Itoa(py.Count), py.Amount.Format(locale), money.FormatPercent(locale, py.Share, 1)}
+		}
+		p.table([]string{
+			embeded.Translate(locale, "Payee"),
+			embeded.Translate(locale, "Bills"),
+			embeded.Translate(locale, "Amount"),
+			embeded.Translate(locale, "Share"),
+		}, rows)
+	}
+
+	return p.doc
+}
+
+// reportDate formats the day of the filter in the date format of the user.
+func reportDate(sess SessionData, day string) string {
+	t, err := time.ParseInLocation(report.DateLayout, day, sess.Preferences.Location())
+	if err != nil {
+		return day
+	}
+
+	return sess.Preferences.FormatDate(t)
+}
+
+// need starts a new page when the height does not fit in the current one.
+func (p *reportPDF) need(height float64) {
+	if p.y+height > pdf.PageHeight-pdfMargin {
+		p.doc.AddPage()
+		p.y = pdfMargin
+	}
+}
+
+func (p *reportPDF) text(font pdf.Font, size float64, text string) {
+	p.need(size * 1.5)
+	p.y += size * 1.5
+	p.doc.Text(pdfMargin, p.y, font, size, text)
+}
+
+// chart draws the amounts of the periods as bars, labelled with the largest one.
+func (p *reportPDF) chart(rows []reportPeriod) {
+	if len(rows) == 0 {
+		return
+	}
+
+	var highest money.Money
+	for _, row := range rows {
+		if row.Amount.Amount > highest.Amount {
+			highest = row.Amount
+		}
+	}
+
+	p.need(pdfChartSize + 2*pdfLineHeight)
+	width := pdf.PageWidth - 2*pdfMargin
+	top, bottom := p.y+pdfLineHeight, p.y+pdfLineHeight+pdfChartSize
+	if highest.Amount > 0 {
+		p.doc.Line(pdfMargin, top, pdf.PageWidth-pdfMargin, top, 0.5, 0.85)
+		p.doc.Text(pdfMargin, top-4, pdf.Regular, 8, highest.Format(p.locale))
+	}
+	p.doc.Line(pdfMargin, bottom, pdf.PageWidth-pdfMargin, bottom, 0.5, 0.5)
+
+	slot := width / float64(len(rows))
+	every := int(math.Ceil(float64(len(rows)) * pdfLabelWidth / width))
+	for i, row := range rows {
+		x := pdfMargin + slot*float64(i)
+		if highest.Amount > 0 && row.Amount.Amount > 0 {
+			height := pdfChartSize * float64(row.Amount.Amount) / float64(highest.Amount)
+			p.doc.Rect(x+slot*0.15, bottom-height, slot*0.7, height, 0.35)
+		}
+		if i%every == 0 {
+			p.doc.Text(x+(slot-pdf.TextWidth(pdf.Regular, 8, row.Label))/2, bottom+12, pdf.Regular, 8, row.Label)
+		}
+	}
+	p.y = bottom + 2*pdfLineHeight
+}
+
+// table draws the rows under the header, with the first column aligned to
+// the left and the others to the right, repeating the header on new pages.
+func (p *reportPDF) table(header []string, rows [][]string) {
+	width := pdf.PageWidth - 2*pdfMargin
+	// The first column takes the room left by the others.
+	first := width - float64(len(header)-1)*pdfColumnWidth
+	right := func(col int) float64 {
+		return pdfMargin + first + float64(col)*pdfColumnWidth
+	}
+	line := func(font pdf.Font, cells []string) {
+		p.doc.Text(pdfMargin, p.y, font, 10, cells[0])
+		for i, cell := range cells[1:] {
+			p.doc.TextRight(right(i+1), p.y, font, 10, cell)
+		}
+	}
+	headerLine := func() {
+		p.y += pdfLineHeight
+		line(pdf.Bold, header)
+		p.doc.Line(pdfMargin, p.y+5, pdf.PageWidth-pdfMargin, p.y+5, 0.5, 0.5)
+	}
+
+	p.need(3 * pdfLineHeight)
+	headerLine()
+	for _, row := range rows {
+		if p.y+pdfLineHeight > pdf.PageHeight-pdfMargin {
+			p.doc.AddPage()
+			p.y = pdfMargin
+			headerLine()
+		}
+		p.y += pdfLineHeight
+		line(pdf.Regular, row)
+	}
+	p.y += pdfLineHeight
+}
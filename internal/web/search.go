@@ -0,0 +1,184 @@
+package web
+
+import (
+	"strings"
+	"time"
+
+	"github.com/garnizeH/dimdim/service/bill"
+	"github.com/labstack/echo/v4"
+	"github.com/microcosm-cc/bluemonday"
+)
+
+type searchFields struct {
+	Query string
+	// Searched is false until the user searches, to tell an empty result
+	// apart from the empty page.
+	Searched bool
+	Bills    []billView
+
+	MaxSavedSearchName int
+}
+
+// setSearchFields searches the bills matching the query, keeping the query
+// in the fields when it is invalid.
+func (h *Handler) setSearchFields(c echo.Context, query string) error {
+	fields := searchFields{
+		Query: query,
+
+		MaxSavedSearchName: bill.MaxSavedSearchName,
+	}
+	setSessionDataFields(c, fields)
+	if query == "" {
+		return nil
+	}
+
+	sess := getSessionData(c)
+	q, err := bill.ParseQuery(query, sess.Preferences.Currency)
+	if err != nil {
+		return err
+	}
+
+	ctx := c.Request().Context()
+	bills, err := h.service.Bill().Search(ctx, sess.Email, q)
+	if err != nil {
+		return err
+	}
+
+	payees, err := h.service.Payee().List(ctx, sess.Email)
+	if err != nil {
+		return err
+	}
+	names := make(map[int64]string, len(payees))
+	for _, p := range payees {
+		names[p.ID] = p.Name
+	}
+
+	now := time.Now()
+	fields.Searched = true
+	fields.Bills = make([]billView, len(bills))
+	for i, b := range bills {
+		fields.Bills[i] = billView{
+			Bill:    b,
+			Overdue: b.Overdue(now),
+			Payee:   names[b.PayeeID],
+		}
+	}
+
+	setSessionDataFields(c, fields)
+	return nil
+}
+
+type searchRequest struct {
+	Query string `query:"q"`
+}
+
+func (r *searchRequest) validate(c echo.Context, input *bluemonday.Policy) error {
+	r.Query = strings.TrimSpace(r.Query)
+
+	return nil
+}
+
+// Search shows the bills of the user matching the search.
+func (h *Handler) Search(c echo.Context) error {
+	r := searchRequest{}
+	if err := h.validateRequest(c, &r, "search"); err != nil {
+		return err
+	}
+
+	if err := h.setSearchFields(c, r.Query); err != nil {
+		return h.errTmpl("search", err.Error())
+	}
+
+	return pageRendererWithFlashMsg(c, "search", "")
+}
+
+type saveSearchRequest struct {
+	Name   string `form:"name"`
+	Query  string `form:"q"`
+	Pinned bool   `form:"pinned"`
+}
+
+func (r *saveSearchRequest) validate(c echo.Context, input *bluemonday.Policy) error {
+	r.Name = input.Sanitize(strings.TrimSpace(r.Name))
+	r.Query = strings.TrimSpace(r.Query)
+	if r.Name == "" || r.Query == "" {
+		return bill.ErrInvalidSavedSearch
+	}
+
+	return nil
+}
+
+// SaveSearch saves the search with a name, showing its bills again.
+func (h *Handler) SaveSearch(c echo.Context) error {
+	sess := getSessionData(c)
+	r := saveSearchRequest{}
+	err := c.Bind(&r)
+	if err == nil {
+		err = r.validate(c, h.input)
+	}
+	if err == nil {
+		err = h.service.Bill().SaveSearch(c.Request().Context(), sess.Email, r.Name, r.Query, r.Pinned)
+	}
+
+	if fieldsErr := h.setSearchFields(c, r.Query); fieldsErr != nil {
+		return h.errTmpl("search", fieldsErr.Error())
+	}
+	if err != nil {
+		return h.errTmpl("search", err.Error())
+	}
+
+	return pageRendererWithFlashMsg(c, "search", "search saved")
+}
+
+type savedSearchRequest struct {
+	ID int64 `form:"id"`
+}
+
+func (r *savedSearchRequest) validate(c echo.Context, input *bluemonday.Policy) error {
+	if r.ID <= 0 {
+		return bill.ErrSavedSearchNotFound
+	}
+
+	return nil
+}
+
+// PinSavedSearch shows the saved search on the home page.
+func (h *Handler) PinSavedSearch(c echo.Context) error {
+	ctx := c.Request().Context()
+	return h.savedSearchAction(c, func(email string, id int64) error {
+		return h.service.Bill().PinSearch(ctx, email, id, true)
+	}, "saved search pinned")
+}
+
+// UnpinSavedSearch removes the saved search from the home page.
+func (h *Handler) UnpinSavedSearch(c echo.Context) error {
+	ctx := c.Request().Context()
+	return h.savedSearchAction(c, func(email string, id int64) error {
+		return h.service.Bill().PinSearch(ctx, email, id, false)
+	}, "saved search unpinned")
+}
+
+func (h *Handler) DeleteSavedSearch(c echo.Context) error {
+	ctx := c.Request().Context()
+	return h.savedSearchAction(c, func(email string, id int64) error {
+		return h.service.Bill().DeleteSavedSearch(ctx, email, id)
+	}, "saved search deleted")
+}
+
+func (h *Handler) savedSearchAction(c echo.Context, action func(email string, id int64) error, msg string) error {
+	r := savedSearchRequest{}
+	if err := h.validateRequest(c, &r, "search"); err != nil {
+		return err
+	}
+
+	sess := getSessionData(c)
+	err := action(sess.Email, r.ID)
+	if fieldsErr := h.setSearchFields(c, ""); fieldsErr != nil {
+		return h.errMsg(fieldsErr.Error())
+	}
+	if err != nil {
+		return h.errTmpl("search", err.Error())
+	}
+
+	return pageRendererWithFlashMsg(c, "search", msg)
+}
@@ -1,6 +1,8 @@
 package web
 
 import (
+	"fmt"
+	"net"
 	"net/http"
 	"strconv"
 	"strings"
@@ -27,6 +29,44 @@ type Config struct {
 	ShutdownTimeout time.Duration
 
 	CORSAllowedOrigins []string
+
+	// TrustedProxies are the reverse proxies whose X-Forwarded-For header
+	// gives the IP of the clients. Without them the IP is the address of the
+	// connection.
+	TrustedProxies []*net.IPNet
+}
+
+// ParseTrustedProxies parses the IP addresses and CIDR ranges of the trusted reverse proxies.
+func ParseTrustedProxies(proxies []string) ([]*net.IPNet, error) {
+	var nets []*net.IPNet
+	for _, proxy := range proxies {
+		proxy = strings.TrimSpace(proxy)
+		if proxy == "" {
+			continue
+		}
+
+		if !strings.Contains(proxy, "/") {
+			ip := net.ParseIP(proxy)
+			if ip == nil {
+				return nil, fmt.Errorf("invalid trusted proxy %q", proxy)
+			}
+
+			bits := 8 * len(ip.To16())
+			if ip.To4() != nil {
+				ip, bits = ip.To4(), 32
+			}
+			nets = append(nets, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+
+		_, ipNet, err := net.ParseCIDR(proxy)
+		if err != nil {
+			return nil, fmt.Errorf("invalid trusted proxy %q: %w", proxy, err)
+		}
+		nets = append(nets, ipNet)
+	}
+
+	return nets, nil
 }
 
 func (c Config) Address() string {
@@ -72,12 +112,26 @@ func NewWebServer(
 	e.Debug = cfg.IsLocalhost()
 	e.HideBanner = !cfg.IsLocalhost()
 	e.HidePort = !cfg.IsLocalhost()
+	e.IPExtractor = ipExtractor(cfg.TrustedProxies)
 
-	templates := embeded.Templates()
-	e.Renderer = templates
-	e.HTTPErrorHandler = errorHandler(templates)
+	renderer := menuRenderer{
+		templates:     embeded.Templates(),
+		notifications: service.Notification(),
+		bills:         service.Bill(),
+	}
+	e.Renderer = renderer
+	e.HTTPErrorHandler = errorHandler(renderer)
 
-	e.Use(middleware.BodyLimit("1k"))
+	e.Use(middleware.BodyLimitWithConfig(middleware.BodyLimitConfig{
+		Limit:   "1k",
+		Skipper: isBackupUpload,
+	}))
+	e.Use(middleware.BodyLimitWithConfig(middleware.BodyLimitConfig{
+		Limit: maxBackupSize,
+		Skipper: func(c echo.Context) bool {
+			return !isBackupUpload(c)
+		},
+	}))
 
 	// Setup CSRF protection.
 	e.Use(middleware.CSRFWithConfig(middleware.CSRFConfig{
@@ -91,7 +145,10 @@ func NewWebServer(
 		CookieSameSite: http.SameSiteStrictMode,
 		Skipper: func(c echo.Context) bool {
 			path := c.Request().URL.Path
-			return strings.HasPrefix(path, "/static") || path == "/payment_hook"
+			// The API requests with a token are authenticated by it and not by
+			// the session cookie, and the invalid tokens are rejected by the API.
+			apiToken := isAPIRequest(c) && bearerToken(c) != ""
+			return strings.HasPrefix(path, "/static") || path == "/payment_hook" || apiToken
 		},
 	}))
 
@@ -114,7 +171,7 @@ func NewWebServer(
 	// Setup handler.
 	domain := domain.Domain(cfg.FullDomain())
 	handlers := NewHandler(domain, sessionManager, service)
-	handlers.LoadRoutes(e, templates)
+	handlers.LoadRoutes(e, renderer.templates)
 
 	// Setup static page serving.
 	staticG := e.Group("static")
@@ -140,3 +197,23 @@ func NewWebServer(
 		sessionManager: sm,
 	}
 }
+
+// ipExtractor returns the IP of the clients shown in their sessions. The
+// X-Forwarded-For header is only read behind the trusted proxies, since any
+// client can set it.
+func ipExtractor(proxies []*net.IPNet) echo.IPExtractor {
+	if len(proxies) == 0 {
+		return echo.ExtractIPDirect()
+	}
+
+	options := []echo.TrustOption{
+		echo.TrustLoopback(false),
+		echo.TrustLinkLocal(false),
+		echo.TrustPrivateNet(false),
+	}
+	for _, proxy := range proxies {
+		options = append(options, echo.TrustIPRange(proxy))
+	}
+
+	return echo.ExtractIPFromXFFHeader(options...)
+}
@@ -0,0 +1,45 @@
+package web
+
+import (
+	"net/http/httptest"
+	"testing"
+)
+
+func TestIPExtractor(t *testing.T) {
+	proxies, err := ParseTrustedProxies([]string{"10.0.0.1", " 172.16.0.0/12"})
+	if err != nil {
+		t.Fatalf("ParseTrustedProxies() error = %v", err)
+	}
+	if _, err := ParseTrustedProxies([]string{"10.0.0"}); err == nil {
+		t.Error("ParseTrustedProxies() of an invalid address did not fail")
+	}
+
+	tests := []struct {
+		name       string
+		proxies    bool
+		remoteAddr string
+		forwarded  string
+		want       string
+	}{
+		{name: "direct", remoteAddr: "203.0.113.7:4321", forwarded: "198.51.100.1", want: "203.0.113.7"},
+		{name: "private network without proxies", remoteAddr: "10.0.0.1:4321", forwarded: "198.51.100.1", want: "10.0.0.1"},
+		{name: "trusted proxy", proxies: true, remoteAddr: "10.0.0.1:4321", forwarded: "198.51.100.1", want: "198.51.100.1"},
+		{name: "trusted proxy chain", proxies: true, remoteAddr: "172.20.0.3:4321", forwarded: "198.51.100.1, 10.0.0.1", want: "198.51.100.1"},
+		{name: "untrusted proxy", proxies: true, remoteAddr: "10.0.0.2:4321", forwarded: "198.51.100.1", want: "10.0.0.2"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			extract := ipExtractor(nil)
+			if tt.proxies {
+				extract = ipExtractor(proxies)
+			}
+
+			req := httptest.NewRequest("GET", "/", nil)
+			req.RemoteAddr = tt.remoteAddr
+			req.Header.Set("X-Forwarded-For", tt.forwarded)
+			if got := extract(req); got != tt.want {
+				t.Errorf("ipExtractor() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
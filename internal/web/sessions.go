@@ -0,0 +1,234 @@
+package web
+
+import (
+	"context"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/alexedwards/scs/v2"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/microcosm-cc/bluemonday"
+)
+
+const (
+	contextKeySessionID = "session_id"
+	contextKeyUserAgent = "user_agent"
+	contextKeyIP        = "ip"
+	contextKeyCreatedAt = "created_at"
+	contextKeyLastSeen  = "last_seen"
+
+	maxUserAgentLength = 255
+	lastSeenResolution = time.Minute
+)
+
+type sessionInfo struct {
+	ID        string
+	UserAgent string
+	IP        string
+	CreatedAt time.Time
+	LastSeen  time.Time
+	Current   bool
+}
+
+// startSession binds the current session to the given email. The session token is renewed to
+// avoid session fixation and the session receives a new identifier used to list and revoke it.
+func startSession(c echo.Context, sm *scs.SessionManager, email string) error {
+	ctx := c.Request().Context()
+	if err := sm.RenewToken(ctx); err != nil {
+		return err
+	}
+
+	now := time.Now().UTC().UnixMilli()
+	sm.Put(ctx, contextKeyEmail, email)
+	sm.Put(ctx, contextKeySessionID, uuid.New().String())
+	sm.Put(ctx, contextKeyCreatedAt, now)
+	touchSession(c, sm)
+
+	return nil
+}
+
+// touchSession records the device, address and last activity of a signed in session. The last seen
+// timestamp is only updated once per lastSeenResolution to avoid writing the session on every request.
+func touchSession(c echo.Context, sm *scs.SessionManager) {
+	req := c.Request()
+	ctx := req.Context()
+
+	if sm.GetString(ctx, contextKeySessionID) == "" {
+		// Sessions created before the tracking was in place.
+		sm.Put(ctx, contextKeySessionID, uuid.New().String())
+	}
+
+	userAgent := req.UserAgent()
+	if len(userAgent) > maxUserAgentLength {
+		userAgent = userAgent[:maxUserAgentLength]
+	}
+	if sm.GetString(ctx, contextKeyUserAgent) != userAgent {
+		sm.Put(ctx, contextKeyUserAgent, userAgent)
+	}
+
+	ip := c.RealIP()
+	if sm.GetString(ctx, contextKeyIP) != ip {
+		sm.Put(ctx, contextKeyIP, ip)
+	}
+
+	now := time.Now().UTC()
+	lastSeen := time.UnixMilli(sm.GetInt64(ctx, contextKeyLastSeen))
+	if now.Sub(lastSeen) >= lastSeenResolution {
+		sm.Put(ctx, contextKeyLastSeen, now.UnixMilli())
+	}
+}
+
+// listSessions returns the active sessions of the email, most recently seen first.
+func listSessions(ctx context.Context, sm *scs.SessionManager, email string) ([]sessionInfo, error) {
+	currentID := sm.GetString(ctx, contextKeySessionID)
+
+	var sessions []sessionInfo
+	if err := sm.Iterate(ctx, func(ctx context.Context) error {
+		if sm.GetString(ctx, contextKeyEmail) != email {
+			return nil
+		}
+
+		id := sm.GetString(ctx, contextKeySessionID)
+		sessions = append(sessions, sessionInfo{
+			ID:        id,
+			UserAgent: sm.GetString(ctx, contextKeyUserAgent),
+			IP:        sm.GetString(ctx, contextKeyIP),
+			CreatedAt: time.UnixMilli(sm.GetInt64(ctx, contextKeyCreatedAt)).UTC(),
+			LastSeen:  time.UnixMilli(sm.GetInt64(ctx, contextKeyLastSeen)).UTC(),
+			Current:   id != "" && id == currentID,
+		})
+
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	sort.Slice(sessions, func(i, j int) bool {
+		return sessions[i].LastSeen.After(sessions[j].LastSeen)
+	})
+
+	return sessions, nil
+}
+
+// revokeSessions destroys every stored session of the email for which revoke returns true.
+func revokeSessions(ctx context.Context, sm *scs.SessionManager, email string, revoke func(id string) bool) error {
+	return sm.Iterate(ctx, func(ctx context.Context) error {
+		if sm.GetString(ctx, contextKeyEmail) != email {
+			return nil
+		}
+		if !revoke(sm.GetString(ctx, contextKeySessionID)) {
+			return nil
+		}
+
+		return sm.Destroy(ctx)
+	})
+}
+
+// revokeOtherSessions destroys every session of the email except the current one.
+func revokeOtherSessions(ctx context.Context, sm *scs.SessionManager, email string) error {
+	currentID := sm.GetString(ctx, contextKeySessionID)
+	return revokeSessions(ctx, sm, email, func(id string) bool {
+		return id != currentID
+	})
+}
+
+// revokeAllSessions destroys every stored session of the email.
+func revokeAllSessions(ctx context.Context, sm *scs.SessionManager, email string) error {
+	return revokeSessions(ctx, sm, email, func(string) bool {
+		return true
+	})
+}
+
+// RevokeUserSessions destroys every session of the email kept in the sessions
+// database, for the administrative tasks run outside of the web server.
+func RevokeUserSessions(ctx context.Context, dsn, email string) error {
+	sm, err := newSessionManager(dsn)
+	if err != nil {
+		return err
+	}
+	defer sm.Close()
+
+	return revokeAllSessions(ctx, sm.SessionManager(), email)
+}
+
+func (h *Handler) Sessions(c echo.Context) error {
+	ctx := c.Request().Context()
+	email := h.sess.GetString(ctx, contextKeyEmail)
+	sessions, err := listSessions(ctx, h.sess, email)
+	if err != nil {
+		return h.errMsg(err.Error())
+	}
+
+	setSessionDataFields(c, struct {
+		Sessions []sessionInfo
+	}{
+		Sessions: sessions,
+	})
+	return pageRendererWithFlashMsg(c, "sessions", "")
+}
+
+type revokeSessionRequest struct {
+	ID string `form:"id"`
+}
+
+func (r *revokeSessionRequest) validate(c echo.Context, input *bluemonday.Policy) error {
+	r.ID = strings.TrimSpace(r.ID)
+	if _, err := uuid.Parse(r.ID); err != nil {
+		return ErrInvalidSession
+	}
+
+	return nil
+}
+
+func (h *Handler) RevokeSession(c echo.Context) error {
+	r := revokeSessionRequest{}
+	if err := h.validateRequest(c, &r); err != nil {
+		return err
+	}
+
+	ctx := c.Request().Context()
+	if r.ID == h.sess.GetString(ctx, contextKeySessionID) {
+		return h.Signout(c)
+	}
+
+	email := h.sess.GetString(ctx, contextKeyEmail)
+	if err := revokeSessions(ctx, h.sess, email, func(id string) bool {
+		return id == r.ID
+	}); err != nil {
+		return h.errMsg(err.Error())
+	}
+
+	return c.Redirect(http.StatusSeeOther, "/auth/sessions")
+}
+
+func (h *Handler) RevokeOtherSessions(c echo.Context) error {
+	ctx := c.Request().Context()
+	email := h.sess.GetString(ctx, contextKeyEmail)
+	if err := revokeOtherSessions(ctx, h.sess, email); err != nil {
+		return h.errMsg(err.Error())
+	}
+
+	return c.Redirect(http.StatusSeeOther, "/auth/sessions")
+}
+
+// migrateSessions moves every stored session of the old email to the new one. The current session
+// is updated in place since it is committed by the session middleware at the end of the request.
+func migrateSessions(ctx context.Context, sm *scs.SessionManager, oldEmail, newEmail string) error {
+	if sm.GetString(ctx, contextKeyEmail) == oldEmail {
+		sm.Put(ctx, contextKeyEmail, newEmail)
+	}
+
+	currentToken := sm.Token(ctx)
+	return sm.Iterate(ctx, func(ctx context.Context) error {
+		if sm.Token(ctx) == currentToken || sm.GetString(ctx, contextKeyEmail) != oldEmail {
+			return nil
+		}
+
+		sm.Put(ctx, contextKeyEmail, newEmail)
+		_, _, err := sm.Commit(ctx)
+		return err
+	})
+}
@@ -0,0 +1,113 @@
+package web
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/alexedwards/scs/v2"
+)
+
+// newTestSession stores a signed in session of the email and returns its context.
+func newTestSession(t *testing.T, sm *scs.SessionManager, email, id string, lastSeen time.Time) context.Context {
+	t.Helper()
+
+	ctx, err := sm.Load(context.Background(), "")
+	if err != nil {
+		t.Fatalf("failed to load a new session: %v", err)
+	}
+
+	sm.Put(ctx, contextKeyEmail, email)
+	sm.Put(ctx, contextKeySessionID, id)
+	sm.Put(ctx, contextKeyLastSeen, lastSeen.UnixMilli())
+	if _, _, err := sm.Commit(ctx); err != nil {
+		t.Fatalf("failed to commit the session: %v", err)
+	}
+
+	return ctx
+}
+
+func sessionIDs(t *testing.T, ctx context.Context, sm *scs.SessionManager, email string) []string {
+	t.Helper()
+
+	sessions, err := listSessions(ctx, sm, email)
+	if err != nil {
+		t.Fatalf("listSessions() error = %v", err)
+	}
+
+	ids := make([]string, len(sessions))
+	for i, s := range sessions {
+		ids[i] = s.ID
+	}
+
+	return ids
+}
+
+func TestSessions(t *testing.T) {
+	dsn := filepath.Join(t.TempDir(), "sessions.db")
+	manager, err := newSessionManager(dsn)
+	if err != nil {
+		t.Fatalf("newSessionManager() error = %v", err)
+	}
+	defer manager.Close()
+	sm := manager.SessionManager()
+
+	const (
+		email   = "someone@example.com"
+		other   = "other@example.com"
+		changed = "changed@example.com"
+	)
+	now := time.Now()
+	current := newTestSession(t, sm, email, "laptop", now.Add(-time.Hour))
+	newTestSession(t, sm, email, "phone", now)
+	newTestSession(t, sm, email, "tablet", now.Add(-2*time.Hour))
+	newTestSession(t, sm, other, "desktop", now)
+
+	sessions, err := listSessions(current, sm, email)
+	if err != nil {
+		t.Fatalf("listSessions() error = %v", err)
+	}
+	if len(sessions) != 3 || sessions[0].ID != "phone" || sessions[1].ID != "laptop" || !sessions[1].Current || sessions[0].Current {
+		t.Fatalf("listSessions() = %+v, want phone, the current laptop and tablet", sessions)
+	}
+
+	if err := revokeSessions(current, sm, email, func(id string) bool { return id == "tablet" }); err != nil {
+		t.Fatalf("revokeSessions() error = %v", err)
+	}
+	if got := sessionIDs(t, current, sm, email); len(got) != 2 {
+		t.Errorf("sessions after revoking the tablet = %v, want phone and laptop", got)
+	}
+
+	if err := migrateSessions(current, sm, email, changed); err != nil {
+		t.Fatalf("migrateSessions() error = %v", err)
+	}
+	// The current session is committed by the session middleware.
+	if _, _, err := sm.Commit(current); err != nil {
+		t.Fatalf("failed to commit the session: %v", err)
+	}
+	if got := sessionIDs(t, current, sm, email); len(got) != 0 {
+		t.Errorf("sessions of the old email = %v, want none", got)
+	}
+	if got := sessionIDs(t, current, sm, changed); len(got) != 2 {
+		t.Errorf("sessions of the new email = %v, want phone and laptop", got)
+	}
+
+	if err := revokeOtherSessions(current, sm, changed); err != nil {
+		t.Fatalf("revokeOtherSessions() error = %v", err)
+	}
+	if got := sessionIDs(t, current, sm, changed); len(got) != 1 || got[0] != "laptop" {
+		t.Errorf("sessions after revoking the others = %v, want laptop", got)
+	}
+
+	// The admin command revokes the sessions out of the web server.
+	if err := RevokeUserSessions(context.Background(), dsn, other); err != nil {
+		t.Fatalf("RevokeUserSessions() error = %v", err)
+	}
+	if got := sessionIDs(t, current, sm, other); len(got) != 0 {
+		t.Errorf("sessions after RevokeUserSessions() = %v, want none", got)
+	}
+	if got := sessionIDs(t, current, sm, changed); len(got) != 1 {
+		t.Errorf("sessions of another user after RevokeUserSessions() = %v, want laptop", got)
+	}
+}
@@ -0,0 +1,352 @@
+// Package boleto parses the barcodes and the digitable lines (linha digitável)
+// of the brazilian boletos, both the bank ones (ficha de compensação) and the
+// utility and tax bills (arrecadação).
+package boleto
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/garnizeH/dimdim/pkg/money"
+)
+
+const (
+	barcodeLength     = 44
+	bankLineLength    = 47
+	utilityLineLength = 48
+
+	// currencyReal is the currency code of the bank boletos in reais.
+	currencyReal = '9'
+	// utilityProduct is the first digit of the utility bills.
+	utilityProduct = '8'
+)
+
+var (
+	ErrInvalidCode       = errors.New("invalid boleto code")
+	ErrInvalidCheckDigit = errors.New("invalid boleto check digit")
+)
+
+type Kind int
+
+const (
+	// KindBank is the boleto issued by a bank, the ficha de compensação.
+	KindBank Kind = iota + 1
+	// KindUtility is the utility or tax bill, the arrecadação.
+	KindUtility
+)
+
+// Segment is the kind of the issuer of an utility bill.
+type Segment int
+
+const (
+	SegmentCityHall    Segment = 1
+	SegmentSanitation  Segment = 2
+	SegmentEnergy      Segment = 3
+	SegmentTelecom     Segment = 4
+	SegmentGovernment  Segment = 5
+	SegmentCompany     Segment = 6
+	SegmentTrafficFine Segment = 7
+	SegmentBank        Segment = 9
+)
+
+var segmentNames = map[Segment]string{
+	SegmentCityHall:    "City hall",
+	SegmentSanitation:  "Sanitation",
+	SegmentEnergy:      "Electricity and gas",
+	SegmentTelecom:     "Telecommunications",
+	SegmentGovernment:  "Government",
+	SegmentCompany:     "Company",
+	SegmentTrafficFine: "Traffic fine",
+	SegmentBank:        "Bank",
+}
+
+func (s Segment) String() string {
+	name, ok := segmentNames[s]
+	if !ok {
+		return "Other"
+	}
+
+	return name
+}
+
+// bankNames maps the COMPE code of the most common issuing banks to their names.
+var bankNames = map[string]string{
+	"001": "Banco do Brasil",
+	"033": "Santander",
+	"041": "Banrisul",
+	"070": "BRB",
+	"077": "Inter",
+	"104": "Caixa Econômica Federal",
+	"208": "BTG Pactual",
+	"212": "Banco Original",
+	"237": "Bradesco",
+	"260": "Nu Pagamentos",
+	"290": "PagBank",
+	"323": "Mercado Pago",
+	"336": "C6 Bank",
+	"341": "Itaú Unibanco",
+	"380": "PicPay",
+	"422": "Safra",
+	"748": "Sicredi",
+	"756": "Sicoob",
+}
+
+// BankName returns the name of the bank, or the code when unknown.
+func BankName(code string) string {
+	name, ok := bankNames[code]
+	if !ok {
+		return code
+	}
+
+	return name
+}
+
+// Boleto is the information encoded in the barcode of a boleto.
+type Boleto struct {
+	Kind Kind
+	// Barcode is the 44 digits of the barcode.
+	Barcode string
+	// Bank is the code of the issuing bank of the bank boletos.
+	Bank string
+	// Segment is the issuer segment of the utility bills.
+	Segment Segment
+	// Amount is zero when the boleto leaves the amount to the payer.
+	Amount money.Money
+	// DueFactor is the number of days of the due date since the base date of
+	// the bank boletos, zero when there is no due date.
+	DueFactor int
+}
+
+// Parse parses a barcode or a digitable line, ignoring the spaces, dots and
+// dashes used to group the digits.
+func Parse(code string) (Boleto, error) {
+	digits := strings.Map(func(r rune) rune {
+		switch r {
+		case ' ', '.', '-', '\t', '\n', '\r':
+			return -1
+		}
+		return r
+	}, code)
+	for _, r := range digits {
+		if r < '0' || r > '9' {
+			return Boleto{}, ErrInvalidCode
+		}
+	}
+
+	switch len(digits) {
+	case barcodeLength:
+		if digits[0] == utilityProduct {
+			return parseUtilityBarcode(digits)
+		}
+		return parseBankBarcode(digits)
+	case bankLineLength:
+		return parseBankLine(digits)
+	case utilityLineLength:
+		return parseUtilityLine(digits)
+	default:
+		return Boleto{}, ErrInvalidCode
+	}
+}
+
+// The bank barcode is the bank (3), the currency (1), the general check digit
+// (1), the due factor (4), the amount (10) and the free field of the bank (25).
+func parseBankBarcode(barcode string) (Boleto, error) {
+	if barcode[3] != currencyReal {
+		return Boleto{}, ErrInvalidCode
+	}
+	if digit(barcode[4]) != mod11Bank(barcode[:4]+barcode[5:]) {
+		return Boleto{}, ErrInvalidCheckDigit
+	}
+
+	return Boleto{
+		Kind:      KindBank,
+		Barcode:   barcode,
+		Bank:      barcode[:3],
+		Amount:    money.New(number(barcode[9:19]), "BRL"),
+		DueFactor: int(number(barcode[5:9])),
+	}, nil
+}
+
+// The bank digitable line has three fields with the bank, the currency and
+// the free field, each followed by its check digit, then the general check
+// digit, the due factor and the amount.
+func parseBankLine(line string) (Boleto, error) {
+	fields := []string{line[0:10], line[10:21], line[21:32]}
+	for _, f := range fields {
+		if digit(f[len(f)-1]) != mod10(f[:len(f)-1]) {
+			return Boleto{}, ErrInvalidCheckDigit
+		}
+	}
+
+	barcode := line[0:4] + line[32:47] + line[4:9] + line[10:20] + line[21:31]
+	return parseBankBarcode(barcode)
+}
+
+// The utility barcode is the product (1), the segment (1), the kind of value
+// (1), the general check digit (1), the amount (11) and the issuer and free
+// fields (29).
+func parseUtilityBarcode(barcode string) (Boleto, error) {
+	checkDigit, err := utilityCheckDigit(barcode[2])
+	if err != nil {
+		return Boleto{}, err
+	}
+	if digit(barcode[3]) != checkDigit(barcode[:3]+barcode[4:]) {
+		return Boleto{}, ErrInvalidCheckDigit
+	}
+
+	b := Boleto{
+		Kind:    KindUtility,
+		Barcode: barcode,
+		Segment: Segment(digit(barcode[1])),
+	}
+	// The kinds 7 and 9 carry an index reference instead of an amount in reais.
+	if barcode[2] == '6' || barcode[2] == '8' {
+		b.Amount = money.New(number(barcode[4:15]), "BRL")
+	}
+
+	return b, nil
+}
+
+// The utility digitable line is the barcode in four blocks of 11 digits, each
+// followed by its check digit.
+func parseUtilityLine(line string) (Boleto, error) {
+	if line[0] != utilityProduct {
+		return Boleto{}, ErrInvalidCode
+	}
+	checkDigit, err := utilityCheckDigit(line[2])
+	if err != nil {
+		return Boleto{}, err
+	}
+
+	var barcode strings.Builder
+	for i := 0; i < utilityLineLength; i += 12 {
+		block := line[i : i+11]
+		if digit(line[i+11]) != checkDigit(block) {
+			return Boleto{}, ErrInvalidCheckDigit
+		}
+		barcode.WriteString(block)
+	}
+
+	return parseUtilityBarcode(barcode.String())
+}
+
+// utilityCheckDigit returns the check digit function given by the kind of value.
+func utilityCheckDigit(kind byte) (func(string) int, error) {
+	switch kind {
+	case '6', '7':
+		return mod10, nil
+	case '8', '9':
+		return mod11Utility, nil
+	default:
+		return nil, ErrInvalidCode
+	}
+}
+
+// DigitableLine returns the digitable line of the boleto, grouped as printed on the bills.
+func (b Boleto) DigitableLine() string {
+	bc := b.Barcode
+	if b.Kind == KindUtility {
+		checkDigit, err := utilityCheckDigit(bc[2])
+		if err != nil {
+			return ""
+		}
+
+		blocks := make([]string, 0, 4)
+		for i := 0; i < barcodeLength; i += 11 {
+			block := bc[i : i+11]
+			blocks = append(blocks, block+"-"+string(rune('0'+checkDigit(block))))
+		}
+		return strings.Join(blocks, " ")
+	}
+
+	field := func(s string) string {
+		s += string(rune('0' + mod10(s)))
+		return s[:5] + "." + s[5:]
+	}
+
+	return strings.Join([]string{
+		field(bc[0:4] + bc[19:24]),
+		field(bc[24:34]),
+		field(bc[34:44]),
+		bc[4:5],
+		bc[5:19],
+	}, " ")
+}
+
+// dueBase is the day zero of the due factors.
+var dueBase = time.Date(1997, time.October, 7, 0, 0, 0, 0, time.UTC)
+
+// dueCycle is the number of days after which the due factors restart from
+// 1000, as they did in 2025-02-22 after reaching 9999.
+const dueCycle = 9000
+
+// DueDate returns the due date of the boleto, the one closest to now among
+// the cycles of the due factors.
+func (b Boleto) DueDate(now time.Time) (time.Time, bool) {
+	if b.Kind != KindBank || b.DueFactor < 1000 {
+		return time.Time{}, false
+	}
+
+	due := dueBase.AddDate(0, 0, b.DueFactor)
+	for next := due.AddDate(0, 0, dueCycle); next.Sub(now).Abs() < due.Sub(now).Abs(); next = next.AddDate(0, 0, dueCycle) {
+		due = next
+	}
+
+	return due, true
+}
+
+// mod10 is the check digit with the weights 2 and 1 from the right, summing
+// the digits of the products.
+func mod10(s string) int {
+	sum := 0
+	for i := range len(s) {
+		p := digit(s[len(s)-1-i]) * (2 - i%2)
+		sum += p/10 + p%10
+	}
+
+	return (10 - sum%10) % 10
+}
+
+// weightedMod11 is the sum of the digits with the weights 2 to 9 from the right.
+func weightedMod11(s string) int {
+	sum := 0
+	for i := range len(s) {
+		sum += digit(s[len(s)-1-i]) * (2 + i%8)
+	}
+
+	return sum % 11
+}
+
+// mod11Bank is the general check digit of the bank boletos, never zero.
+func mod11Bank(s string) int {
+	d := 11 - weightedMod11(s)
+	if d == 0 || d == 10 || d == 11 {
+		return 1
+	}
+
+	return d
+}
+
+// mod11Utility is the check digit of the utility bills.
+func mod11Utility(s string) int {
+	d := 11 - weightedMod11(s)
+	if d >= 10 {
+		return 0
+	}
+
+	return d
+}
+
+func digit(b byte) int {
+	return int(b - '0')
+}
+
+func number(s string) int64 {
+	var n int64
+	for i := range len(s) {
+		n = n*10 + int64(digit(s[i]))
+	}
+
+	return n
+}
@@ -0,0 +1,226 @@
+package boleto_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/garnizeH/dimdim/pkg/boleto"
+	"github.com/garnizeH/dimdim/pkg/money"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name    string
+		code    string
+		want    boleto.Boleto
+		wantErr error
+	}{
+		{
+			name: "bank digitable line",
+			code: "00190.50095 40144.816069 06809.350314 3 37370000000100",
+			want: boleto.Boleto{
+				Kind:      boleto.KindBank,
+				Barcode:   "00193373700000001000500940144816060680935031",
+				Bank:      "001",
+				Amount:    money.New(100, "BRL"),
+				DueFactor: 3737,
+			},
+		},
+		{
+			name: "bank barcode",
+			code: "34199144200000123451234567890123456789012345",
+			want: boleto.Boleto{
+				Kind:      boleto.KindBank,
+				Barcode:   "34199144200000123451234567890123456789012345",
+				Bank:      "341",
+				Amount:    money.New(12345, "BRL"),
+				DueFactor: 1442,
+			},
+		},
+		{
+			name: "bank digitable line without separators",
+			code: "34191234546789012345767890123457914420000012345",
+			want: boleto.Boleto{
+				Kind:      boleto.KindBank,
+				Barcode:   "34199144200000123451234567890123456789012345",
+				Bank:      "341",
+				Amount:    money.New(12345, "BRL"),
+				DueFactor: 1442,
+			},
+		},
+		{
+			name: "utility digitable line with mod 10",
+			code: "836200000005 667800481000 180975657313 001589636081",
+			want: boleto.Boleto{
+				Kind:    boleto.KindUtility,
+				Barcode: "83620000000667800481001809756573100158963608",
+				Segment: boleto.SegmentEnergy,
+				Amount:  money.New(6678, "BRL"),
+			},
+		},
+		{
+			name: "utility digitable line with mod 11",
+			code: "84820000001-8 23450001202-1 60510999999-0 99999999999-7",
+			want: boleto.Boleto{
+				Kind:    boleto.KindUtility,
+				Barcode: "84820000001234500012026051099999999999999999",
+				Segment: boleto.SegmentTelecom,
+				Amount:  money.New(12345, "BRL"),
+			},
+		},
+		{
+			name: "utility barcode",
+			code: "83620000000667800481001809756573100158963608",
+			want: boleto.Boleto{
+				Kind:    boleto.KindUtility,
+				Barcode: "83620000000667800481001809756573100158963608",
+				Segment: boleto.SegmentEnergy,
+				Amount:  money.New(6678, "BRL"),
+			},
+		},
+		{
+			name:    "bank field check digit",
+			code:    "00190.50096 40144.816069 06809.350314 3 37370000000100",
+			wantErr: boleto.ErrInvalidCheckDigit,
+		},
+		{
+			name:    "bank general check digit",
+			code:    "00190.50095 40144.816069 06809.350314 4 37370000000100",
+			wantErr: boleto.ErrInvalidCheckDigit,
+		},
+		{
+			name:    "utility block check digit",
+			code:    "836200000006 667800481000 180975657313 001589636081",
+			wantErr: boleto.ErrInvalidCheckDigit,
+		},
+		{
+			name:    "utility general check digit",
+			code:    "83630000000667800481001809756573100158963608",
+			wantErr: boleto.ErrInvalidCheckDigit,
+		},
+		{
+			name:    "utility unknown kind of value",
+			code:    "83520000000667800481001809756573100158963608",
+			wantErr: boleto.ErrInvalidCode,
+		},
+		{
+			name:    "foreign currency",
+			code:    "00113373700000001000500940144816060680935031",
+			wantErr: boleto.ErrInvalidCode,
+		},
+		{
+			name:    "letters",
+			code:    "00190.5009X 40144.816069 06809.350314 3 37370000000100",
+			wantErr: boleto.ErrInvalidCode,
+		},
+		{
+			name:    "wrong length",
+			code:    "00190.50095 40144.816069",
+			wantErr: boleto.ErrInvalidCode,
+		},
+		{
+			name:    "empty",
+			code:    "",
+			wantErr: boleto.ErrInvalidCode,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := boleto.Parse(tt.code)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Parse(%q) error = %v, wantErr %v", tt.code, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("Parse(%q) = %+v, want %+v", tt.code, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBoletoDigitableLine(t *testing.T) {
+	tests := []struct {
+		name    string
+		barcode string
+		want    string
+	}{
+		{
+			name:    "bank",
+			barcode: "00193373700000001000500940144816060680935031",
+			want:    "00190.50095 40144.816069 06809.350314 3 37370000000100",
+		},
+		{
+			name:    "utility",
+			barcode: "83620000000667800481001809756573100158963608",
+			want:    "83620000000-5 66780048100-0 18097565731-3 00158963608-1",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b, err := boleto.Parse(tt.barcode)
+			if err != nil {
+				t.Fatalf("Parse(%q) error = %v", tt.barcode, err)
+			}
+			if got := b.DigitableLine(); got != tt.want {
+				t.Errorf("Boleto.DigitableLine() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBoletoDueDate(t *testing.T) {
+	tests := []struct {
+		name   string
+		b      boleto.Boleto
+		now    time.Time
+		want   time.Time
+		wantOK bool
+	}{
+		{
+			name:   "first cycle",
+			b:      boleto.Boleto{Kind: boleto.KindBank, DueFactor: 3737},
+			now:    time.Date(2007, time.December, 1, 0, 0, 0, 0, time.UTC),
+			want:   time.Date(2007, time.December, 31, 0, 0, 0, 0, time.UTC),
+			wantOK: true,
+		},
+		{
+			name:   "last day of the first cycle",
+			b:      boleto.Boleto{Kind: boleto.KindBank, DueFactor: 9999},
+			now:    time.Date(2025, time.February, 1, 0, 0, 0, 0, time.UTC),
+			want:   time.Date(2025, time.February, 21, 0, 0, 0, 0, time.UTC),
+			wantOK: true,
+		},
+		{
+			name:   "restarted factor",
+			b:      boleto.Boleto{Kind: boleto.KindBank, DueFactor: 1000},
+			now:    time.Date(2025, time.February, 1, 0, 0, 0, 0, time.UTC),
+			want:   time.Date(2025, time.February, 22, 0, 0, 0, 0, time.UTC),
+			wantOK: true,
+		},
+		{
+			name:   "second cycle",
+			b:      boleto.Boleto{Kind: boleto.KindBank, DueFactor: 1442},
+			now:    time.Date(2026, time.April, 20, 0, 0, 0, 0, time.UTC),
+			want:   time.Date(2026, time.May, 10, 0, 0, 0, 0, time.UTC),
+			wantOK: true,
+		},
+		{
+			name: "no due date",
+			b:    boleto.Boleto{Kind: boleto.KindBank, DueFactor: 0},
+			now:  time.Date(2026, time.April, 20, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name: "utility",
+			b:    boleto.Boleto{Kind: boleto.KindUtility, DueFactor: 1442},
+			now:  time.Date(2026, time.April, 20, 0, 0, 0, 0, time.UTC),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := tt.b.DueDate(tt.now)
+			if ok != tt.wantOK || !got.Equal(tt.want) {
+				t.Errorf("Boleto.DueDate() = %v, %v, want %v, %v", got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
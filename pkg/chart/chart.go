@@ -0,0 +1,192 @@
+// Package chart renders simple bar and line charts as SVG, to be embedded in
+// the server rendered pages without any script.
+package chart
+
+import (
+	"fmt"
+	"html"
+	"html/template"
+	"math"
+	"strconv"
+	"strings"
+)
+
+const (
+	defaultWidth  = 720
+	defaultHeight = 280
+
+	marginTop    = 16
+	marginRight  = 16
+	marginBottom = 32
+	marginLeft   = 96
+
+	ticks = 5
+	// labelWidth is the room given to each label of the horizontal axis, the
+	// labels are skipped evenly when they do not fit.
+	labelWidth = 64
+	// barRatio is the share of the slot of each value taken by its bar.
+	barRatio = 0.7
+
+	// The colors fall back to the ones of Pico CSS outside of the pages.
+	seriesColor = "fill:var(--pico-primary,#0172ad)"
+	lineColor   = "stroke:var(--pico-primary,#0172ad)"
+	gridColor   = "stroke:var(--pico-muted-border-color,#e7eaf0)"
+	textColor   = "fill:var(--pico-muted-color,#646b79)"
+)
+
+// Chart is a single series of values, one for each label.
+type Chart struct {
+	// Title is the accessible name of the chart.
+	Title  string
+	Labels []string
+	Values []int64
+	// Format formats the values in the vertical axis and the tooltips.
+	Format func(int64) string
+	// Width and Height default to 720 by 280.
+	Width  int
+	Height int
+}
+
+// Bar renders the chart as vertical bars.
+func (c Chart) Bar() template.HTML {
+	return c.render(func(sb *strings.Builder, p plot) {
+		slot := p.width / float64(len(c.Values))
+		for i, v := range c.Values {
+			x := p.left + slot*float64(i) + slot*(1-barRatio)/2
+			y0, y1 := p.y(0), p.y(v)
+			fmt.Fprintf(sb, `<rect x="%s" y="%s" width="%s" height="%s" style="%s"><title>%s</title></rect>`,
+				num(x), num(math.Min(y0, y1)), num(slot*barRatio), num(math.Abs(y0-y1)), seriesColor, c.tooltip(i))
+		}
+	})
+}
+
+// Line renders the chart as a line with a point for each value.
+func (c Chart) Line() template.HTML {
+	return c.render(func(sb *strings.Builder, p plot) {
+		slot := p.width / float64(len(c.Values))
+		points := make([]string, len(c.Values))
+		for i, v := range c.Values {
+			points[i] = num(p.left+slot*(float64(i)+0.5)) + "," + num(p.y(v))
+		}
+		fmt.Fprintf(sb, `<polyline points="%s" fill="none" stroke-width="2" style="%s"/>`, strings.Join(points, " "), lineColor)
+
+		for i, point := range points {
+			x, y, _ := strings.Cut(point, ",")
+			fmt.Fprintf(sb, `<circle cx="%s" cy="%s" r="3" style="%s"><title>%s</title></circle>`, x, y, seriesColor, c.tooltip(i))
+		}
+	})
+}
+
+// plot is the area of the chart inside the axes.
+type plot struct {
+	left, top, width, height float64
+	lo, hi                   int64
+}
+
+// y returns the vertical position of the value.
+func (p plot) y(v int64) float64 {
+	return p.top + p.height*float64(p.hi-v)/float64(p.hi-p.lo)
+}
+
+func (c Chart) render(series func(*strings.Builder, plot)) template.HTML {
+	width, height := c.Width, c.Height
+	if width <= 0 {
+		width = defaultWidth
+	}
+	if height <= 0 {
+		height = defaultHeight
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, `<svg xmlns="http://www.w3.org/2000/svg" viewBox="0 0 %d %d" role="img" aria-label="%s" style="width:100%%;height:auto" font-size="12">`,
+		width, height, html.EscapeString(c.Title))
+	fmt.Fprintf(&sb, `<title>%s</title>`, html.EscapeString(c.Title))
+
+	lo, hi, step := scale(c.Values)
+	p := plot{
+		left:   marginLeft,
+		top:    marginTop,
+		width:  float64(width - marginLeft - marginRight),
+		height: float64(height - marginTop - marginBottom),
+		lo:     lo,
+		hi:     hi,
+	}
+
+	for v := lo; v <= hi; v += step {
+		y := num(p.y(v))
+		fmt.Fprintf(&sb, `<line x1="%s" y1="%s" x2="%s" y2="%s" style="%s"/>`, num(p.left), y, num(p.left+p.width), y, gridColor)
+		fmt.Fprintf(&sb, `<text x="%s" y="%s" text-anchor="end" dominant-baseline="middle" style="%s">%s</text>`,
+			num(p.left-8), y, textColor, html.EscapeString(c.format(v)))
+	}
+
+	if len(c.Values) > 0 {
+		series(&sb, p)
+
+		slot := p.width / float64(len(c.Values))
+		every := int(math.Ceil(float64(len(c.Values)) * labelWidth / p.width))
+		for i, label := range c.Labels {
+			if i >= len(c.Values) || i%every != 0 {
+				continue
+			}
+			fmt.Fprintf(&sb, `<text x="%s" y="%s" text-anchor="middle" style="%s">%s</text>`,
+				num(p.left+slot*(float64(i)+0.5)), num(p.top+p.height+20), textColor, html.EscapeString(label))
+		}
+	}
+
+	sb.WriteString(`</svg>`)
+	return template.HTML(sb.String())
+}
+
+func (c Chart) format(v int64) string {
+	if c.Format == nil {
+		return strconv.FormatInt(v, 10)
+	}
+
+	return c.Format(v)
+}
+
+func (c Chart) tooltip(i int) string {
+	label := ""
+	if i < len(c.Labels) {
+		label = c.Labels[i] + ": "
+	}
+
+	return html.EscapeString(label + c.format(c.Values[i]))
+}
+
+// scale returns the range of the vertical axis, always including zero, and
+// the step between its ticks, rounded to 1, 2 or 5 times a power of ten.
+func scale(values []int64) (lo, hi, step int64) {
+	for _, v := range values {
+		lo, hi = min(lo, v), max(hi, v)
+	}
+	if lo == hi {
+		hi = lo + 1
+	}
+
+	raw := float64(hi-lo) / ticks
+	magnitude := math.Pow(10, math.Floor(math.Log10(raw)))
+	for _, m := range []float64{1, 2, 5, 10} {
+		if raw <= m*magnitude {
+			step = int64(m * magnitude)
+			break
+		}
+	}
+	step = max(step, 1)
+
+	return floorTo(lo, step), -floorTo(-hi, step), step
+}
+
+// floorTo rounds the value down to a multiple of the step.
+func floorTo(v, step int64) int64 {
+	if v < 0 && v%step != 0 {
+		return (v/step - 1) * step
+	}
+
+	return v / step * step
+}
+
+// num formats the coordinate with at most one decimal.
+func num(f float64) string {
+	return strconv.FormatFloat(math.Round(f*10)/10, 'f', -1, 64)
+}
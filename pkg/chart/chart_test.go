@@ -0,0 +1,116 @@
+package chart_test
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/garnizeH/dimdim/pkg/chart"
+)
+
+var tickPattern = regexp.MustCompile(`text-anchor="end"[^>]*>([^<]*)</text>`)
+
+// axisTicks returns the labels of the vertical axis, from the bottom.
+func axisTicks(svg string) []string {
+	var labels []string
+	for _, m := range tickPattern.FindAllStringSubmatch(svg, -1) {
+		labels = append(labels, m[1])
+	}
+
+	return labels
+}
+
+func TestChartBar(t *testing.T) {
+	tests := []struct {
+		name      string
+		chart     chart.Chart
+		wantBars  int
+		wantTicks []string
+		wantText  []string
+	}{
+		{
+			name: "positive",
+			chart: chart.Chart{
+				Title:  "Paid bills",
+				Labels: []string{"Jan", "Feb", "Mar"},
+				Values: []int64{0, 12345, 5000},
+				Format: func(v int64) string { return fmt.Sprintf("$%d", v) },
+			},
+			wantBars:  3,
+			wantTicks: []string{"$0", "$5000", "$10000", "$15000"},
+			wantText:  []string{`aria-label="Paid bills"`, "<title>Feb: $12345</title>"},
+		},
+		{
+			name: "negative",
+			chart: chart.Chart{
+				Labels: []string{"a", "b"},
+				Values: []int64{-120, 300},
+			},
+			wantBars:  2,
+			wantTicks: []string{"-200", "-100", "0", "100", "200", "300"},
+		},
+		{
+			name: "escaped labels",
+			chart: chart.Chart{
+				Title:  `Bills "2026"`,
+				Labels: []string{"<b>&"},
+				Values: []int64{1},
+			},
+			wantBars:  1,
+			wantTicks: []string{"0", "1"},
+			wantText:  []string{`aria-label="Bills &#34;2026&#34;"`, "&lt;b&gt;&amp;"},
+		},
+		{
+			name:      "empty",
+			chart:     chart.Chart{},
+			wantTicks: []string{"0", "1"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svg := string(tt.chart.Bar())
+			if !strings.HasPrefix(svg, "<svg ") || !strings.HasSuffix(svg, "</svg>") {
+				t.Fatalf("Chart.Bar() = %q, want an svg element", svg)
+			}
+			if got := strings.Count(svg, "<rect "); got != tt.wantBars {
+				t.Errorf("Chart.Bar() has %d bars, want %d", got, tt.wantBars)
+			}
+			if got := axisTicks(svg); !slices.Equal(got, tt.wantTicks) {
+				t.Errorf("Chart.Bar() ticks = %q, want %q", got, tt.wantTicks)
+			}
+			for _, want := range tt.wantText {
+				if !strings.Contains(svg, want) {
+					t.Errorf("Chart.Bar() = %q, want it to contain %q", svg, want)
+				}
+			}
+			if strings.Contains(svg, "<b>") {
+				t.Errorf("Chart.Bar() = %q, want the labels escaped", svg)
+			}
+		})
+	}
+}
+
+func TestChartLine(t *testing.T) {
+	labels := make([]string, 24)
+	values := make([]int64, 24)
+	for i := range labels {
+		labels[i] = fmt.Sprintf("L%02d", i)
+		values[i] = int64(i * 100)
+	}
+
+	svg := string(chart.Chart{Labels: labels, Values: values}.Line())
+	if got := strings.Count(svg, "<polyline "); got != 1 {
+		t.Errorf("Chart.Line() has %d lines, want 1", got)
+	}
+	if got := strings.Count(svg, "<circle "); got != len(values) {
+		t.Errorf("Chart.Line() has %d points, want %d", got, len(values))
+	}
+
+	// The labels that do not fit the axis are skipped evenly.
+	shown := regexp.MustCompile(`text-anchor="middle"[^>]*>(L\d+)</text>`).FindAllStringSubmatch(svg, -1)
+	if len(shown) != 8 || shown[0][1] != "L00" || shown[1][1] != "L03" {
+		t.Errorf("Chart.Line() shows the labels %q, want every third one", shown)
+	}
+}
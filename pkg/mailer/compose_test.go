@@ -0,0 +1,153 @@
+package mailer_test
+
+import (
+	"bytes"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/mail"
+	"strings"
+	"testing"
+
+	"github.com/garnizeH/dimdim/pkg/mailer"
+)
+
+func TestMailerSendComposesMultipart(t *testing.T) {
+	const baseURL = "http://localhost:3000"
+
+	tests := []struct {
+		name    string
+		msg     mailer.Message
+		subject string
+		url     string
+	}{
+		{
+			name:    "signup",
+			msg:     mailer.NewMailSignup("en", baseURL, "someone@example.com", "Someone", "token"),
+			subject: "Confirm your email address",
+			url:     baseURL + "/auth/signup/token",
+		},
+		{
+			name:    "password",
+			msg:     mailer.NewMailPassword("en", baseURL, "someone@example.com", "Someone", "token"),
+			subject: "Change your password",
+			url:     baseURL + "/auth/reset-password/token",
+		},
+		{
+			name:    "email change",
+			msg:     mailer.NewMailEmailChange("en", baseURL, "someone@example.com", "Someone", "token"),
+			subject: "Confirm your new email address",
+			url:     baseURL + "/auth/change-email/token",
+		},
+		{
+			name:    "email change notice",
+			msg:     mailer.NewMailEmailChangeNotice("en", baseURL, "someone@example.com", "new@example.com", "Someone", "token"),
+			subject: "Your email address is being changed",
+			url:     baseURL + "/auth/cancel-email-change/token",
+		},
+		{
+			name:    "export",
+			msg:     mailer.NewMailExport("en", baseURL, "someone@example.com", "Someone", "token"),
+			subject: "Your data export is ready",
+			url:     baseURL + "/auth/export/token",
+		},
+		{
+			name:    "bill reminder",
+			msg:     mailer.NewMailBillReminder("en", baseURL, "someone@example.com", "Someone", "Rent", "$1,200.00", "10/05/2026"),
+			subject: "A bill is due soon",
+			url:     baseURL + "/bills",
+		},
+		{
+			name:    "localized",
+			msg:     mailer.NewMailSignup("pt-BR", baseURL, "joao@example.com", "João", "token"),
+			subject: "Confirme seu endereço de e-mail",
+			url:     baseURL + "/auth/signup/token",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			transport := mailer.NewMemoryTransport()
+			if err := mailer.New("dimdim@example.com", transport).Send(tt.msg); err != nil {
+				t.Fatalf("Mailer.Send() error = %v", err)
+			}
+
+			sent := transport.Messages()
+			if len(sent) != 1 {
+				t.Fatalf("MemoryTransport.Messages() = %d messages, want 1", len(sent))
+			}
+
+			m, err := mail.ReadMessage(bytes.NewReader(sent[0].Data))
+			if err != nil {
+				t.Fatalf("failed to parse the message: %v", err)
+			}
+
+			for _, key := range []string{"From", "To", "Date", "Message-ID", "MIME-Version"} {
+				if m.Header.Get(key) == "" {
+					t.Errorf("missing header %s", key)
+				}
+			}
+			if _, err := m.Header.Date(); err != nil {
+				t.Errorf("invalid Date header: %v", err)
+			}
+
+			rawSubject := m.Header.Get("Subject")
+			if strings.ContainsFunc(rawSubject, func(r rune) bool { return r > 127 }) {
+				t.Errorf("Subject header is not encoded: %q", rawSubject)
+			}
+			subject, err := new(mime.WordDecoder).DecodeHeader(rawSubject)
+			if err != nil {
+				t.Fatalf("failed to decode the subject: %v", err)
+			}
+			if subject != tt.subject {
+				t.Errorf("Subject = %q, want %q", subject, tt.subject)
+			}
+
+			mediaType, params, err := mime.ParseMediaType(m.Header.Get("Content-Type"))
+			if err != nil || mediaType != "multipart/alternative" {
+				t.Fatalf("Content-Type = %q, want multipart/alternative", m.Header.Get("Content-Type"))
+			}
+
+			var types []string
+			mr := multipart.NewReader(m.Body, params["boundary"])
+			for {
+				p, err := mr.NextPart()
+				if err == io.EOF {
+					break
+				}
+				if err != nil {
+					t.Fatalf("failed to read the part: %v", err)
+				}
+
+				// NextPart decodes the quoted-printable parts.
+				body, err := io.ReadAll(p)
+				if err != nil {
+					t.Fatalf("failed to read the part body: %v", err)
+				}
+				if !strings.Contains(string(body), tt.url) {
+					t.Errorf("part %s does not contain %q:\n%s", p.Header.Get("Content-Type"), tt.url, body)
+				}
+
+				mediaType, _, _ := mime.ParseMediaType(p.Header.Get("Content-Type"))
+				types = append(types, mediaType)
+			}
+
+			if strings.Join(types, ",") != "text/plain,text/html" {
+				t.Errorf("parts = %v, want [text/plain text/html]", types)
+			}
+		})
+	}
+}
+
+func TestMailerSendUnknownTemplate(t *testing.T) {
+	msg := mailer.NewMailSignup("en", "http://localhost:3000", "someone@example.com", "Someone", "token")
+	msg.Template = "unknown"
+
+	// The dispatcher records the render errors as failed attempts.
+	transport := mailer.NewMemoryTransport()
+	if err := mailer.New("dimdim@example.com", transport).Send(msg); err == nil {
+		t.Fatal("Mailer.Send() of an unknown template did not fail")
+	}
+	if sent := transport.Messages(); len(sent) != 0 {
+		t.Errorf("MemoryTransport.Messages() = %d messages, want none", len(sent))
+	}
+}
@@ -0,0 +1,113 @@
+package mailer
+
+import (
+	"context"
+	"time"
+
+	"github.com/garnizeH/dimdim/pkg/logger"
+)
+
+// Outbox is the persistent queue of messages delivered by the dispatcher.
+type Outbox interface {
+	Pending(ctx context.Context, now time.Time, limit int) ([]Message, error)
+	MarkSent(ctx context.Context, id int64, sentAt time.Time) error
+	MarkFailed(ctx context.Context, id int64, attempts int64, lastErr string, nextAttemptAt time.Time, final bool) error
+}
+
+type DispatcherConfig struct {
+	Interval    time.Duration
+	BatchSize   int
+	MaxAttempts int64
+	MinBackoff  time.Duration
+	MaxBackoff  time.Duration
+}
+
+// Dispatcher delivers the messages queued in the outbox, retrying the failed
+// deliveries with exponential backoff until the max attempts is reached.
+type Dispatcher struct {
+	log    *logger.Logger
+	cfg    DispatcherConfig
+	mailer *Mailer
+	outbox Outbox
+}
+
+func NewDispatcher(log *logger.Logger, cfg DispatcherConfig, mailer *Mailer, outbox Outbox) *Dispatcher {
+	return &Dispatcher{
+		log:    log,
+		cfg:    cfg,
+		mailer: mailer,
+		outbox: outbox,
+	}
+}
+
+// Run delivers the pending messages on every interval or when new messages are
+// queued, until the context is canceled.
+func (d *Dispatcher) Run(ctx context.Context) {
+	ticker := time.NewTicker(d.cfg.Interval)
+	defer ticker.Stop()
+
+	for {
+		if err := d.dispatch(ctx); err != nil {
+			d.log.Error(ctx, "outbox", "status", "failed to dispatch the outbox", "error", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-d.mailer.queued:
+		}
+	}
+}
+
+func (d *Dispatcher) dispatch(ctx context.Context) error {
+	for {
+		msgs, err := d.outbox.Pending(ctx, time.Now(), d.cfg.BatchSize)
+		if err != nil {
+			return err
+		}
+
+		for _, msg := range msgs {
+			if err := d.deliver(ctx, msg); err != nil {
+				return err
+			}
+		}
+
+		if len(msgs) < d.cfg.BatchSize {
+			return nil
+		}
+	}
+}
+
+func (d *Dispatcher) deliver(ctx context.Context, msg Message) error {
+	sendErr := d.mailer.Send(msg)
+	if sendErr == nil {
+		return d.outbox.MarkSent(ctx, msg.ID, time.Now())
+	}
+
+	attempts := msg.Attempts + 1
+	final := attempts >= d.cfg.MaxAttempts
+	next := time.Now().Add(backoff(attempts, d.cfg.MinBackoff, d.cfg.MaxBackoff))
+
+	if final {
+		d.log.Error(ctx, "outbox", "status", "giving up the message delivery", "id", msg.ID, "template", msg.Template, "attempts", attempts, "error", sendErr)
+	} else {
+		d.log.Info(ctx, "outbox", "status", "failed to deliver the message", "id", msg.ID, "template", msg.Template, "attempts", attempts, "retry_at", next, "error", sendErr)
+	}
+
+	return d.outbox.MarkFailed(ctx, msg.ID, attempts, sendErr.Error(), next, final)
+}
+
+// backoff returns the wait before the next delivery attempt, doubling the min
+// backoff on every failed attempt up to the max backoff.
+func backoff(attempts int64, minWait, maxWait time.Duration) time.Duration {
+	wait := minWait
+	for i := int64(1); i < attempts; i++ {
+		wait *= 2
+		if wait >= maxWait {
+			return maxWait
+		}
+	}
+
+	return wait
+}
@@ -0,0 +1,126 @@
+package mailer_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/garnizeH/dimdim/pkg/logger"
+	"github.com/garnizeH/dimdim/pkg/mailer"
+)
+
+type failingTransport struct{}
+
+func (failingTransport) Send(from string, to []string, msg []byte) error {
+	return errors.New("connection refused")
+}
+
+type failure struct {
+	attempts int64
+	next     time.Time
+	final    bool
+}
+
+type fakeOutbox struct {
+	pending  []mailer.Message
+	sent     []int64
+	failures map[int64]failure
+}
+
+func (o *fakeOutbox) Pending(ctx context.Context, now time.Time, limit int) ([]mailer.Message, error) {
+	msgs := o.pending
+	o.pending = nil
+	return msgs, nil
+}
+
+func (o *fakeOutbox) MarkSent(ctx context.Context, id int64, sentAt time.Time) error {
+	o.sent = append(o.sent, id)
+	return nil
+}
+
+func (o *fakeOutbox) MarkFailed(ctx context.Context, id int64, attempts int64, lastErr string, nextAttemptAt time.Time, final bool) error {
+	o.failures[id] = failure{
+		attempts: attempts,
+		next:     nextAttemptAt,
+		final:    final,
+	}
+	return nil
+}
+
+func TestDispatcherRetries(t *testing.T) {
+	tests := []struct {
+		name      string
+		attempts  int64
+		wantWait  time.Duration
+		wantFinal bool
+	}{
+		{
+			name:      "first failure",
+			attempts:  0,
+			wantWait:  time.Minute,
+			wantFinal: false,
+		},
+		{
+			name:      "third failure",
+			attempts:  2,
+			wantWait:  4 * time.Minute,
+			wantFinal: false,
+		},
+		{
+			name:      "backoff capped",
+			attempts:  8,
+			wantWait:  time.Hour,
+			wantFinal: false,
+		},
+		{
+			name:      "last attempt",
+			attempts:  9,
+			wantWait:  time.Hour,
+			wantFinal: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			outbox := &fakeOutbox{
+				pending: []mailer.Message{
+					mailer.NewMailSignup("en", "http://localhost", "someone@example.com", "someone", "token"),
+				},
+				failures: map[int64]failure{},
+			}
+			outbox.pending[0].ID = 1
+			outbox.pending[0].Attempts = tt.attempts
+
+			m := mailer.New("dimdim@localhost", failingTransport{})
+			cfg := mailer.DispatcherConfig{
+				Interval:    time.Hour,
+				BatchSize:   10,
+				MaxAttempts: 10,
+				MinBackoff:  time.Minute,
+				MaxBackoff:  time.Hour,
+			}
+			log := logger.New(io.Discard, logger.LevelError, "test", func(context.Context) string { return "" })
+
+			ctx, cancel := context.WithCancel(context.Background())
+			cancel()
+
+			start := time.Now()
+			mailer.NewDispatcher(log, cfg, m, outbox).Run(ctx)
+
+			got, ok := outbox.failures[1]
+			if !ok {
+				t.Fatalf("Dispatcher.Run() did not record the failure, sent = %v", outbox.sent)
+			}
+			if got.attempts != tt.attempts+1 {
+				t.Errorf("attempts = %d, want %d", got.attempts, tt.attempts+1)
+			}
+			if got.final != tt.wantFinal {
+				t.Errorf("final = %v, want %v", got.final, tt.wantFinal)
+			}
+			if wait := got.next.Sub(start); wait < tt.wantWait || wait > tt.wantWait+time.Minute {
+				t.Errorf("next attempt in %v, want %v", wait, tt.wantWait)
+			}
+		})
+	}
+}
@@ -0,0 +1,56 @@
+package mailer
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync/atomic"
+	"time"
+)
+
+// MaildirTransport writes the messages to a maildir instead of delivering
+// them, so they can be read with any mail client during the development.
+type MaildirTransport struct {
+	path     string
+	hostname string
+	seq      atomic.Int64
+}
+
+func NewMaildirTransport(path string) (*MaildirTransport, error) {
+	for _, dir := range []string{"tmp", "new", "cur"} {
+		if err := os.MkdirAll(filepath.Join(path, dir), 0o700); err != nil {
+			return nil, fmt.Errorf("failed to create the maildir %q: %w", path, err)
+		}
+	}
+
+	hostname, err := os.Hostname()
+	if err != nil {
+		hostname = "localhost"
+	}
+
+	return &MaildirTransport{
+		path:     path,
+		hostname: hostname,
+	}, nil
+}
+
+func (t *MaildirTransport) Send(from string, to []string, msg []byte) error {
+	name := strconv.FormatInt(time.Now().UnixNano(), 10) + "." +
+		strconv.Itoa(os.Getpid()) + "_" + strconv.FormatInt(t.seq.Add(1), 10) + "." +
+		t.hostname
+
+	// The message is written in tmp and moved to new only when complete, as
+	// the readers expect every file in new to be a complete message.
+	tmp := filepath.Join(t.path, "tmp", name)
+	if err := os.WriteFile(tmp, msg, 0o600); err != nil {
+		return fmt.Errorf("failed to write the message to the maildir: %w", err)
+	}
+
+	if err := os.Rename(tmp, filepath.Join(t.path, "new", name)); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("failed to deliver the message to the maildir: %w", err)
+	}
+
+	return nil
+}
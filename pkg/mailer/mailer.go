@@ -4,8 +4,8 @@ import (
 	"bytes"
 	"errors"
 	"fmt"
-	"net/smtp"
 	"net/url"
+	"time"
 
 	"github.com/garnizeH/dimdim/embeded"
 )
@@ -15,82 +15,183 @@ var (
 	ErrFailedExecuteTemplate = errors.New("failed to execute template")
 )
 
-type mail struct {
-	subject string
-	to      []string
-	data    any
+// Message is an email waiting in the outbox to be rendered and delivered.
+type Message struct {
+	ID       int64
+	Template string
+	Subject  string
+	To       string
+	Data     map[string]string
+	Attempts int64
 }
 
-func NewMailSignup(baseURL, email, name, token string) *mail {
+func NewMailSignup(locale, baseURL, email, name, token string) Message {
 	const endpoint = "/auth/signup/"
 	url := baseURL + endpoint + url.QueryEscape(token)
-	data := struct {
-		Name string
-		URL  string
-	}{
-		Name: name,
-		URL:  url,
+	data := map[string]string{
+		"Locale": locale,
+		"Name":   name,
+		"URL":    url,
 	}
 
 	const subject = "Confirm your email address"
 
-	return &mail{
-		subject: subject,
-		to:      []string{email},
-		data:    data,
+	return Message{
+		Template: "signup",
+		Subject:  embeded.Translate(locale, subject),
+		To:       email,
+		Data:     data,
 	}
 }
 
-func NewMailPassword(baseURL, email, name, token string) *mail {
+func NewMailPassword(locale, baseURL, email, name, token string) Message {
 	const endpoint = "/auth/reset-password/"
 	url := baseURL + endpoint + url.QueryEscape(token)
-	data := struct {
-		Name string
-		URL  string
-	}{
-		Name: name,
-		URL:  url,
+	data := map[string]string{
+		"Locale": locale,
+		"Name":   name,
+		"URL":    url,
 	}
 
 	const subject = "Change your password"
 
-	return &mail{
-		subject: subject,
-		to:      []string{email},
-		data:    data,
+	return Message{
+		Template: "password",
+		Subject:  embeded.Translate(locale, subject),
+		To:       email,
+		Data:     data,
+	}
+}
+
+func NewMailEmailChange(locale, baseURL, email, name, token string) Message {
+	const endpoint = "/auth/change-email/"
+	url := baseURL + endpoint + url.QueryEscape(token)
+	data := map[string]string{
+		"Locale": locale,
+		"Name":   name,
+		"URL":    url,
+	}
+
+	const subject = "Confirm your new email address"
+
+	return Message{
+		Template: "email-change",
+		Subject:  embeded.Translate(locale, subject),
+		To:       email,
+		Data:     data,
+	}
+}
+
+func NewMailEmailChangeNotice(locale, baseURL, email, newEmail, name, token string) Message {
+	const endpoint = "/auth/cancel-email-change/"
+	url := baseURL + endpoint + url.QueryEscape(token)
+	data := map[string]string{
+		"Locale":   locale,
+		"Name":     name,
+		"NewEmail": newEmail,
+		"URL":      url,
+	}
+
+	const subject = "Your email address is being changed"
+
+	return Message{
+		Template: "email-change-notice",
+		Subject:  embeded.Translate(locale, subject),
+		To:       email,
+		Data:     data,
+	}
+}
+
+func NewMailExport(locale, baseURL, email, name, token string) Message {
+	const endpoint = "/auth/export/"
+	url := baseURL + endpoint + url.QueryEscape(token)
+	data := map[string]string{
+		"Locale": locale,
+		"Name":   name,
+		"URL":    url,
+	}
+
+	const subject = "Your data export is ready"
+
+	return Message{
+		Template: "export",
+		Subject:  embeded.Translate(locale, subject),
+		To:       email,
+		Data:     data,
+	}
+}
+
+// NewMailBillReminder takes the amount and the due date already formatted in
+// the locale and the preferences of the user.
+func NewMailBillReminder(locale, baseURL, email, name, bill, amount, dueOn string) Message {
+	const endpoint = "/bills"
+	data := map[string]string{
+		"Locale": locale,
+		"Name":   name,
+		"Bill":   bill,
+		"Amount": amount,
+		"DueOn":  dueOn,
+		"URL":    baseURL + endpoint,
+	}
+
+	const subject = "A bill is due soon"
+
+	return Message{
+		Template: "bill-reminder",
+		Subject:  embeded.Translate(locale, subject),
+		To:       email,
+		Data:     data,
 	}
 }
 
 type Mailer struct {
-	auth      smtp.Auth
-	addr      string
 	from      string
+	transport Transport
 	templates *embeded.Template
+	queued    chan struct{}
 }
 
-func New(addr, host, identity, username, password string) *Mailer {
-	auth := smtp.PlainAuth(identity, username, password, host)
-
+func New(from string, transport Transport) *Mailer {
 	templates := embeded.Templates()
-	templates.NewEmail("signup", "signup.tmpl")
+	templates.NewEmail("signup", "signup.tmpl", "signup.txt.tmpl")
+	templates.NewEmail("password", "password.tmpl", "password.txt.tmpl")
+	templates.NewEmail("email-change", "email-change.tmpl", "email-change.txt.tmpl")
+	templates.NewEmail("email-change-notice", "email-change-notice.tmpl", "email-change-notice.txt.tmpl")
+	templates.NewEmail("export", "export.tmpl", "export.txt.tmpl")
+	templates.NewEmail("bill-reminder", "bill-reminder.tmpl", "bill-reminder.txt.tmpl")
 
 	return &Mailer{
-		auth:      auth,
-		addr:      addr,
-		from:      identity,
+		from:      from,
+		transport: transport,
 		templates: templates,
+		queued:    make(chan struct{}, 1),
+	}
+}
+
+// Notify wakes up the dispatcher to deliver the messages just queued in the outbox.
+func (m *Mailer) Notify() {
+	select {
+	case m.queued <- struct{}{}:
+	default:
 	}
 }
 
-func (m *Mailer) SendMailSignup(mail *mail) error {
-	buf := new(bytes.Buffer)
-	if err := m.templates.RenderEmail(buf, "signup", mail.data); err != nil {
-		return fmt.Errorf("failed to render email template signup: %w", err)
+// Send renders and delivers the message.
+func (m *Mailer) Send(msg Message) error {
+	html := new(bytes.Buffer)
+	if err := m.templates.RenderEmail(html, msg.Template, msg.Data); err != nil {
+		return fmt.Errorf("failed to render email template %s: %w", msg.Template, err)
+	}
+
+	text := new(bytes.Buffer)
+	if err := m.templates.RenderEmailText(text, msg.Template, msg.Data); err != nil {
+		return fmt.Errorf("failed to render email text template %s: %w", msg.Template, err)
 	}
 
-	mime := "MIME-version: 1.0;\nContent-Type: text/plain; charset=\"UTF-8\";\n\n"
-	subject := "Subject: " + mail.subject + "!\n"
-	msg := []byte(subject + mime + "\n" + buf.String())
+	body, err := compose(m.from, msg.To, msg.Subject, text.Bytes(), html.Bytes(), time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to compose the email %s: %w", msg.Template, err)
+	}
 
-	return smtp.SendMail(m.addr, m.auth, m.from, mail.to, msg)
+	return m.transport.Send(m.from, []string{msg.To}, body)
 }
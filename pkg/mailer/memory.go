@@ -0,0 +1,43 @@
+package mailer
+
+import (
+	"sync"
+)
+
+// SentMessage is a message recorded by the memory transport.
+type SentMessage struct {
+	From string
+	To   []string
+	Data []byte
+}
+
+// MemoryTransport records the messages in memory, it is meant for the tests.
+type MemoryTransport struct {
+	mu       sync.Mutex
+	messages []SentMessage
+}
+
+func NewMemoryTransport() *MemoryTransport {
+	return &MemoryTransport{}
+}
+
+func (t *MemoryTransport) Send(from string, to []string, msg []byte) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.messages = append(t.messages, SentMessage{
+		From: from,
+		To:   append([]string(nil), to...),
+		Data: append([]byte(nil), msg...),
+	})
+
+	return nil
+}
+
+// Messages returns the messages sent so far.
+func (t *MemoryTransport) Messages() []SentMessage {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return append([]SentMessage(nil), t.messages...)
+}
@@ -0,0 +1,143 @@
+package mailer
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/smtp"
+	"time"
+)
+
+// Security is how the connection to the SMTP server is protected.
+type Security string
+
+const (
+	// SecurityAuto upgrades the connection with STARTTLS when the server supports it.
+	SecurityAuto Security = "auto"
+	// SecuritySTARTTLS requires the server to support STARTTLS.
+	SecuritySTARTTLS Security = "starttls"
+	// SecurityTLS connects with implicit TLS, usually on port 465.
+	SecurityTLS Security = "tls"
+	// SecurityNone never encrypts the connection.
+	SecurityNone Security = "none"
+)
+
+const smtpTimeout = 30 * time.Second
+
+// SMTPTransport delivers the messages to a SMTP server. The authentication is
+// skipped when the username is empty.
+type SMTPTransport struct {
+	addr     string
+	host     string
+	auth     smtp.Auth
+	security Security
+}
+
+func NewSMTPTransport(addr, username, password string, security Security) (*SMTPTransport, error) {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse the smtp address %q: %w", addr, err)
+	}
+
+	switch security {
+	case SecurityAuto, SecuritySTARTTLS, SecurityTLS, SecurityNone:
+	default:
+		return nil, fmt.Errorf("invalid smtp security %q", security)
+	}
+
+	var auth smtp.Auth
+	if username != "" {
+		auth = smtp.PlainAuth("", username, password, host)
+	}
+
+	return &SMTPTransport{
+		addr:     addr,
+		host:     host,
+		auth:     auth,
+		security: security,
+	}, nil
+}
+
+func (t *SMTPTransport) Send(from string, to []string, msg []byte) error {
+	c, err := t.dial()
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+
+	if err := c.Hello("localhost"); err != nil {
+		return err
+	}
+
+	if t.security == SecurityAuto || t.security == SecuritySTARTTLS {
+		ok, _ := c.Extension("STARTTLS")
+		if ok {
+			if err := c.StartTLS(&tls.Config{ServerName: t.host}); err != nil {
+				return err
+			}
+		} else if t.security == SecuritySTARTTLS {
+			return fmt.Errorf("smtp server %s does not support STARTTLS", t.addr)
+		}
+	}
+
+	if t.auth != nil {
+		if ok, _ := c.Extension("AUTH"); !ok {
+			return fmt.Errorf("smtp server %s does not support AUTH", t.addr)
+		}
+		if err := c.Auth(t.auth); err != nil {
+			return err
+		}
+	}
+
+	if err := c.Mail(from); err != nil {
+		return err
+	}
+	for _, addr := range to {
+		if err := c.Rcpt(addr); err != nil {
+			return err
+		}
+	}
+
+	w, err := c.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(msg); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+
+	return c.Quit()
+}
+
+func (t *SMTPTransport) dial() (*smtp.Client, error) {
+	dialer := &net.Dialer{Timeout: smtpTimeout}
+
+	var (
+		conn net.Conn
+		err  error
+	)
+	if t.security == SecurityTLS {
+		conn, err = tls.DialWithDialer(dialer, "tcp", t.addr, &tls.Config{ServerName: t.host})
+	} else {
+		conn, err = dialer.Dial("tcp", t.addr)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := conn.SetDeadline(time.Now().Add(smtpTimeout)); err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	c, err := smtp.NewClient(conn, t.host)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	return c, nil
+}
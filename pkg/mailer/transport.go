@@ -0,0 +1,33 @@
+package mailer
+
+import (
+	"fmt"
+)
+
+// Transport delivers a rendered message to the recipients.
+type Transport interface {
+	Send(from string, to []string, msg []byte) error
+}
+
+type TransportConfig struct {
+	Kind        string // smtp, maildir or memory
+	Addr        string
+	Username    string
+	Password    string
+	Security    string // auto, starttls, tls or none
+	MaildirPath string
+}
+
+// NewTransport creates the transport selected in the config.
+func NewTransport(cfg TransportConfig) (Transport, error) {
+	switch cfg.Kind {
+	case "smtp":
+		return NewSMTPTransport(cfg.Addr, cfg.Username, cfg.Password, Security(cfg.Security))
+	case "maildir":
+		return NewMaildirTransport(cfg.MaildirPath)
+	case "memory":
+		return NewMemoryTransport(), nil
+	default:
+		return nil, fmt.Errorf("invalid mailer transport %q", cfg.Kind)
+	}
+}
@@ -0,0 +1,119 @@
+package mailer_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/garnizeH/dimdim/pkg/mailer"
+)
+
+func TestMemoryTransport(t *testing.T) {
+	transport := mailer.NewMemoryTransport()
+	m := mailer.New("dimdim@localhost", transport)
+
+	msg := mailer.NewMailSignup("en", "http://localhost:3000", "someone@example.com", "someone", "token")
+	if err := m.Send(msg); err != nil {
+		t.Fatalf("Mailer.Send() error = %v", err)
+	}
+
+	sent := transport.Messages()
+	if len(sent) != 1 {
+		t.Fatalf("MemoryTransport.Messages() = %d messages, want 1", len(sent))
+	}
+	if sent[0].From != "dimdim@localhost" {
+		t.Errorf("From = %q, want %q", sent[0].From, "dimdim@localhost")
+	}
+	if len(sent[0].To) != 1 || sent[0].To[0] != "someone@example.com" {
+		t.Errorf("To = %v, want [someone@example.com]", sent[0].To)
+	}
+	if !strings.Contains(string(sent[0].Data), "http://localhost:3000/auth/signup/token") {
+		t.Errorf("Data does not contain the signup link:\n%s", sent[0].Data)
+	}
+}
+
+func TestMaildirTransport(t *testing.T) {
+	dir := t.TempDir()
+	transport, err := mailer.NewMaildirTransport(dir)
+	if err != nil {
+		t.Fatalf("NewMaildirTransport() error = %v", err)
+	}
+
+	for _, body := range []string{"first", "second"} {
+		if err := transport.Send("dimdim@localhost", []string{"someone@example.com"}, []byte(body)); err != nil {
+			t.Fatalf("MaildirTransport.Send() error = %v", err)
+		}
+	}
+
+	tmp, err := os.ReadDir(filepath.Join(dir, "tmp"))
+	if err != nil {
+		t.Fatalf("failed to read the tmp dir: %v", err)
+	}
+	if len(tmp) != 0 {
+		t.Errorf("tmp has %d files, want 0", len(tmp))
+	}
+
+	entries, err := os.ReadDir(filepath.Join(dir, "new"))
+	if err != nil {
+		t.Fatalf("failed to read the new dir: %v", err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("new has %d files, want 2", len(entries))
+	}
+
+	got := map[string]bool{}
+	for _, entry := range entries {
+		b, err := os.ReadFile(filepath.Join(dir, "new", entry.Name()))
+		if err != nil {
+			t.Fatalf("failed to read the message: %v", err)
+		}
+		got[string(b)] = true
+	}
+	if !got["first"] || !got["second"] {
+		t.Errorf("messages = %v, want first and second", got)
+	}
+}
+
+func TestNewTransport(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     mailer.TransportConfig
+		wantErr bool
+	}{
+		{
+			name: "smtp",
+			cfg:  mailer.TransportConfig{Kind: "smtp", Addr: "localhost:1025", Security: "auto"},
+		},
+		{
+			name:    "smtp without port",
+			cfg:     mailer.TransportConfig{Kind: "smtp", Addr: "localhost", Security: "auto"},
+			wantErr: true,
+		},
+		{
+			name:    "smtp invalid security",
+			cfg:     mailer.TransportConfig{Kind: "smtp", Addr: "localhost:1025", Security: "ssl"},
+			wantErr: true,
+		},
+		{
+			name: "maildir",
+			cfg:  mailer.TransportConfig{Kind: "maildir", MaildirPath: t.TempDir()},
+		},
+		{
+			name: "memory",
+			cfg:  mailer.TransportConfig{Kind: "memory"},
+		},
+		{
+			name:    "unknown",
+			cfg:     mailer.TransportConfig{Kind: "pigeon"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := mailer.NewTransport(tt.cfg); (err != nil) != tt.wantErr {
+				t.Errorf("NewTransport() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,312 @@
+package money
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+var (
+	ErrInvalidAmount    = errors.New("invalid amount")
+	ErrUnknownCurrency  = errors.New("unknown currency")
+	ErrCurrencyMismatch = errors.New("currency mismatch")
+)
+
+type currency struct {
+	symbol   string
+	exponent int
+}
+
+// currencies maps the ISO 4217 code to the symbol and the number of minor units.
+var currencies = map[string]currency{
+	"BRL": {symbol: "R$", exponent: 2},
+	"USD": {symbol: "$", exponent: 2},
+	"EUR": {symbol: "€", exponent: 2},
+}
+
+// Money is an amount in the minor unit of the currency, e.g. cents.
+type Money struct {
+	Amount   int64
+	Currency string
+}
+
+func New(amount int64, currency string) Money {
+	return Money{
+		Amount:   amount,
+		Currency: currency,
+	}
+}
+
+// Exponent returns the number of decimal digits of the currency minor unit.
+func Exponent(code string) int {
+	c, ok := currencies[code]
+	if !ok {
+		return 2
+	}
+
+	return c.exponent
+}
+
+// Symbol returns the symbol of the currency, or the code when unknown.
+func Symbol(code string) string {
+	c, ok := currencies[code]
+	if !ok {
+		return code
+	}
+
+	return c.symbol
+}
+
+func (m Money) IsZero() bool {
+	return m.Amount == 0
+}
+
+func (m Money) IsNegative() bool {
+	return m.Amount < 0
+}
+
+func (m Money) Neg() Money {
+	return New(-m.Amount, m.Currency)
+}
+
+func (m Money) Add(o Money) (Money, error) {
+	if m.Currency != o.Currency {
+		return Money{}, ErrCurrencyMismatch
+	}
+
+	return New(m.Amount+o.Amount, m.Currency), nil
+}
+
+func (m Money) Sub(o Money) (Money, error) {
+	if m.Currency != o.Currency {
+		return Money{}, ErrCurrencyMismatch
+	}
+
+	return New(m.Amount-o.Amount, m.Currency), nil
+}
+
+func (m Money) Mul(n int64) Money {
+	return New(m.Amount*n, m.Currency)
+}
+
+// Split divides the amount in n parts that add up to the amount, the
+// remainder is spread one minor unit at a time over the first parts.
+func (m Money) Split(n int) []Money {
+	if n <= 0 {
+		return nil
+	}
+
+	parts := make([]Money, n)
+	quotient, remainder := m.Amount/int64(n), m.Amount%int64(n)
+	for i := range parts {
+		parts[i] = New(quotient, m.Currency)
+		switch {
+		case remainder > 0:
+			parts[i].Amount++
+			remainder--
+		case remainder < 0:
+			parts[i].Amount--
+			remainder++
+		}
+	}
+
+	return parts
+}
+
+// String returns the amount in the plain decimal notation followed by the currency code.
+func (m Money) String() string {
+	return FormatDecimal("", m.Amount, Exponent(m.Currency)) + " " + m.Currency
+}
+
+// Format formats the amount with the symbol of the currency and the separators of the locale.
+func (m Money) Format(locale string) string {
+	symbol := Symbol(m.Currency)
+	if conventionsOf(locale).symbolSpace {
+		symbol += " "
+	}
+
+	amount := FormatDecimal(locale, m.Amount, Exponent(m.Currency))
+	if rest, ok := strings.CutPrefix(amount, "-"); ok {
+		return "-" + symbol + rest
+	}
+
+	return symbol + amount
+}
+
+// Parse parses the amount typed by an user, accepting both the comma and the
+// dot as the decimal separator, with or without the thousands separators and
+// the currency symbol.
+func Parse(input, code string) (Money, error) {
+	if _, ok := currencies[code]; !ok {
+		return Money{}, ErrUnknownCurrency
+	}
+
+	s := strings.TrimSpace(input)
+	s = strings.TrimPrefix(s, "+")
+	negative := false
+	if strings.HasPrefix(s, "-") {
+		negative = true
+		s = s[1:]
+	}
+	s = strings.TrimSpace(strings.TrimPrefix(s, Symbol(code)))
+	s = strings.TrimSpace(strings.TrimPrefix(s, code))
+	if !negative && strings.HasPrefix(s, "-") {
+		negative = true
+		s = strings.TrimSpace(s[1:])
+	}
+
+	integer, fraction, err := splitDecimal(s)
+	if err != nil {
+		return Money{}, err
+	}
+
+	exponent := Exponent(code)
+	if len(fraction) > exponent {
+		return Money{}, ErrInvalidAmount
+	}
+	fraction += strings.Repeat("0", exponent-len(fraction))
+
+	amount, err := strconv.ParseInt(integer+fraction, 10, 64)
+	if err != nil {
+		return Money{}, ErrInvalidAmount
+	}
+	if negative {
+		amount = -amount
+	}
+
+	return New(amount, code), nil
+}
+
+// splitDecimal splits the number in the integer and the fraction digits. When
+// both separators are present the last one is the decimal separator, a single
+// separator followed by exactly three digits is a thousands separator.
+func splitDecimal(s string) (string, string, error) {
+	if s == "" {
+		return "", "", ErrInvalidAmount
+	}
+	for _, r := range s {
+		if (r < '0' || r > '9') && r != '.' && r != ',' {
+			return "", "", ErrInvalidAmount
+		}
+	}
+
+	decimal := ""
+	lastDot, lastComma := strings.LastIndex(s, "."), strings.LastIndex(s, ",")
+	switch {
+	case lastDot >= 0 && lastComma >= 0:
+		decimal = "."
+		if lastComma > lastDot {
+			decimal = ","
+		}
+	case lastDot >= 0 || lastComma >= 0:
+		sep := "."
+		if lastComma >= 0 {
+			sep = ","
+		}
+		if strings.Count(s, sep) == 1 && len(s)-strings.LastIndex(s, sep)-1 != 3 {
+			decimal = sep
+		}
+	}
+
+	integer, fraction := s, ""
+	if decimal != "" {
+		i := strings.LastIndex(s, decimal)
+		integer, fraction = s[:i], s[i+1:]
+		if fraction == "" || strings.ContainsAny(fraction, ".,") {
+			return "", "", ErrInvalidAmount
+		}
+	}
+
+	if integer == "" {
+		integer = "0"
+	}
+	if strings.ContainsAny(integer, ".,") {
+		groups := strings.FieldsFunc(integer, func(r rune) bool { return r == '.' || r == ',' })
+		if len(groups) != strings.Count(integer, ".")+strings.Count(integer, ",")+1 || len(groups[0]) > 3 || groups[0][0] == '0' {
+			return "", "", ErrInvalidAmount
+		}
+		for _, g := range groups[1:] {
+			if len(g) != 3 {
+				return "", "", ErrInvalidAmount
+			}
+		}
+		integer = strings.Join(groups, "")
+	}
+
+	return integer, fraction, nil
+}
+
+type conventions struct {
+	decimal     string
+	group       string
+	symbolSpace bool
+}
+
+// localeConventions maps the locale to the separators of the numbers.
+var localeConventions = map[string]conventions{
+	"pt-BR": {decimal: ",", group: ".", symbolSpace: true},
+	"en":    {decimal: ".", group: ",", symbolSpace: false},
+}
+
+func conventionsOf(locale string) conventions {
+	conv, ok := localeConventions[locale]
+	if !ok {
+		return conventions{decimal: ".", group: "", symbolSpace: true}
+	}
+
+	return conv
+}
+
+// FormatDecimal formats the number given in units of 10^-exponent with the
+// separators of the locale, unknown locales get no grouping and the dot.
+func FormatDecimal(locale string, n int64, exponent int) string {
+	conv := conventionsOf(locale)
+
+	sign := ""
+	var u uint64
+	if n < 0 {
+		sign = "-"
+		u = uint64(-(n + 1)) + 1
+	} else {
+		u = uint64(n)
+	}
+
+	digits := strconv.FormatUint(u, 10)
+	if len(digits) <= exponent {
+		digits = strings.Repeat("0", exponent-len(digits)+1) + digits
+	}
+	integer, fraction := digits[:len(digits)-exponent], digits[len(digits)-exponent:]
+
+	if conv.group != "" && len(integer) > 3 {
+		var b strings.Builder
+		head := len(integer) % 3
+		if head > 0 {
+			b.WriteString(integer[:head])
+		}
+		for i := head; i < len(integer); i += 3 {
+			if b.Len() > 0 {
+				b.WriteString(conv.group)
+			}
+			b.WriteString(integer[i : i+3])
+		}
+		integer = b.String()
+	}
+
+	if exponent == 0 {
+		return sign + integer
+	}
+
+	return sign + integer + conv.decimal + fraction
+}
+
+// FormatPercent formats the ratio as a percentage with the given decimals, 0.125 is 12.5%.
+func FormatPercent(locale string, ratio float64, decimals int) string {
+	n := math.Round(ratio * 100 * math.Pow10(decimals))
+	if math.IsNaN(n) || math.IsInf(n, 0) || math.Abs(n) > math.MaxInt64/2 {
+		return fmt.Sprintf("%v%%", ratio*100)
+	}
+
+	return FormatDecimal(locale, int64(n), decimals) + "%"
+}
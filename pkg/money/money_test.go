@@ -0,0 +1,157 @@
+package money_test
+
+import (
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/garnizeH/dimdim/pkg/money"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		currency string
+		want     int64
+		wantErr  error
+	}{
+		{name: "integer", input: "1234", currency: "BRL", want: 123400},
+		{name: "comma decimal", input: "1234,56", currency: "BRL", want: 123456},
+		{name: "dot decimal", input: "1234.56", currency: "USD", want: 123456},
+		{name: "one decimal digit", input: "10,5", currency: "BRL", want: 1050},
+		{name: "brazilian grouping", input: "1.234,56", currency: "BRL", want: 123456},
+		{name: "us grouping", input: "1,234.56", currency: "USD", want: 123456},
+		{name: "dot thousands", input: "1.234", currency: "BRL", want: 123400},
+		{name: "comma thousands", input: "1,234", currency: "USD", want: 123400},
+		{name: "many groups", input: "1.234.567", currency: "BRL", want: 123456700},
+		{name: "symbol", input: "R$ 1.234,56", currency: "BRL", want: 123456},
+		{name: "symbol without space", input: "$1,234.56", currency: "USD", want: 123456},
+		{name: "code", input: "EUR 9,99", currency: "EUR", want: 999},
+		{name: "negative", input: "-R$ 10,00", currency: "BRL", want: -1000},
+		{name: "negative after symbol", input: "R$ -10,00", currency: "BRL", want: -1000},
+		{name: "leading decimal", input: ",5", currency: "BRL", want: 50},
+		{name: "spaces", input: "  42 ", currency: "BRL", want: 4200},
+		{name: "empty", input: "", currency: "BRL", wantErr: money.ErrInvalidAmount},
+		{name: "letters", input: "12a", currency: "BRL", wantErr: money.ErrInvalidAmount},
+		{name: "too many decimals", input: "1,234567", currency: "BRL", wantErr: money.ErrInvalidAmount},
+		{name: "bad grouping", input: "12.34.56", currency: "BRL", wantErr: money.ErrInvalidAmount},
+		{name: "leading zero group", input: "0.125", currency: "BRL", wantErr: money.ErrInvalidAmount},
+		{name: "trailing separator", input: "12,", currency: "BRL", wantErr: money.ErrInvalidAmount},
+		{name: "overflow", input: "99999999999999999999", currency: "BRL", wantErr: money.ErrInvalidAmount},
+		{name: "unknown currency", input: "10", currency: "XYZ", wantErr: money.ErrUnknownCurrency},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := money.Parse(tt.input, tt.currency)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Parse(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			}
+			if tt.wantErr != nil {
+				return
+			}
+			if got.Amount != tt.want || got.Currency != tt.currency {
+				t.Errorf("Parse(%q) = %v, want %d %s", tt.input, got, tt.want, tt.currency)
+			}
+		})
+	}
+}
+
+func TestMoneyFormat(t *testing.T) {
+	tests := []struct {
+		name   string
+		m      money.Money
+		locale string
+		want   string
+	}{
+		{name: "brazilian", m: money.New(123456, "BRL"), locale: "pt-BR", want: "R$ 1.234,56"},
+		{name: "english", m: money.New(123456, "USD"), locale: "en", want: "$1,234.56"},
+		{name: "euro in portuguese", m: money.New(999, "EUR"), locale: "pt-BR", want: "€ 9,99"},
+		{name: "cents", m: money.New(5, "BRL"), locale: "pt-BR", want: "R$ 0,05"},
+		{name: "zero", m: money.New(0, "USD"), locale: "en", want: "$0.00"},
+		{name: "negative", m: money.New(-123456789, "BRL"), locale: "pt-BR", want: "-R$ 1.234.567,89"},
+		{name: "negative english", m: money.New(-100, "USD"), locale: "en", want: "-$1.00"},
+		{name: "unknown locale", m: money.New(123456, "BRL"), locale: "fr", want: "R$ 1234.56"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.m.Format(tt.locale); got != tt.want {
+				t.Errorf("Money.Format(%q) = %q, want %q", tt.locale, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMoneyArithmetic(t *testing.T) {
+	a, b := money.New(1050, "BRL"), money.New(250, "BRL")
+
+	sum, err := a.Add(b)
+	if err != nil || sum != money.New(1300, "BRL") {
+		t.Errorf("Money.Add() = %v, %v, want 13.00 BRL", sum, err)
+	}
+
+	diff, err := b.Sub(a)
+	if err != nil || diff != money.New(-800, "BRL") || !diff.IsNegative() {
+		t.Errorf("Money.Sub() = %v, %v, want -8.00 BRL", diff, err)
+	}
+
+	if got := a.Mul(3); got != money.New(3150, "BRL") {
+		t.Errorf("Money.Mul() = %v, want 31.50 BRL", got)
+	}
+
+	if _, err := a.Add(money.New(1, "USD")); !errors.Is(err, money.ErrCurrencyMismatch) {
+		t.Errorf("Money.Add() error = %v, want %v", err, money.ErrCurrencyMismatch)
+	}
+
+	if got := a.String(); got != "10.50 BRL" {
+		t.Errorf("Money.String() = %q, want %q", got, "10.50 BRL")
+	}
+}
+
+func TestMoneySplit(t *testing.T) {
+	tests := []struct {
+		name string
+		m    money.Money
+		n    int
+		want []int64
+	}{
+		{name: "even", m: money.New(900, "BRL"), n: 3, want: []int64{300, 300, 300}},
+		{name: "remainder", m: money.New(1000, "BRL"), n: 3, want: []int64{334, 333, 333}},
+		{name: "negative remainder", m: money.New(-1000, "BRL"), n: 3, want: []int64{-334, -333, -333}},
+		{name: "more parts than cents", m: money.New(2, "BRL"), n: 3, want: []int64{1, 1, 0}},
+		{name: "no parts", m: money.New(100, "BRL"), n: 0, want: nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []int64
+			for _, p := range tt.m.Split(tt.n) {
+				got = append(got, p.Amount)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("Money.Split(%d) = %v, want %v", tt.n, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFormatPercent(t *testing.T) {
+	tests := []struct {
+		name   string
+		locale string
+		ratio  float64
+		want   string
+	}{
+		{name: "brazilian", locale: "pt-BR", ratio: 0.125, want: "12,5%"},
+		{name: "english", locale: "en", ratio: 0.125, want: "12.5%"},
+		{name: "grouping", locale: "pt-BR", ratio: 12.5, want: "1.250,0%"},
+		{name: "negative", locale: "en", ratio: -0.031, want: "-3.1%"},
+		{name: "rounding", locale: "en", ratio: 0.33333, want: "33.3%"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := money.FormatPercent(tt.locale, tt.ratio, 1); got != tt.want {
+				t.Errorf("FormatPercent(%v) = %q, want %q", tt.ratio, got, tt.want)
+			}
+		})
+	}
+}
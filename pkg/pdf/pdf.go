@@ -0,0 +1,212 @@
+// Package pdf writes simple PDF documents, with text in the standard
+// Helvetica fonts, lines and filled rectangles, without any dependency on
+// external fonts or programs.
+package pdf
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"golang.org/x/text/encoding/charmap"
+)
+
+// The size of an A4 page, in points.
+const (
+	PageWidth  = 595.28
+	PageHeight = 841.89
+)
+
+// Font is one of the standard fonts every PDF reader has.
+type Font int
+
+const (
+	Regular Font = iota
+	Bold
+)
+
+var fontNames = [...]string{
+	Regular: "Helvetica",
+	Bold:    "Helvetica-Bold",
+}
+
+// Document is a PDF document of A4 pages. The coordinates are in points from
+// the top left corner of the page.
+type Document struct {
+	Title string
+
+	pages []*bytes.Buffer
+}
+
+// New returns an empty document with the title.
+func New(title string) *Document {
+	return &Document{
+		Title: title,
+	}
+}
+
+// AddPage starts a new page, where the next operations draw.
+func (d *Document) AddPage() {
+	d.pages = append(d.pages, &bytes.Buffer{})
+}
+
+// Pages returns the number of pages of the document.
+func (d *Document) Pages() int {
+	return len(d.pages)
+}
+
+func (d *Document) page() *bytes.Buffer {
+	if len(d.pages) == 0 {
+		d.AddPage()
+	}
+
+	return d.pages[len(d.pages)-1]
+}
+
+// Text draws the text with its baseline starting at the point. The characters
+// outside of the Windows-1252 encoding are drawn as question marks.
+func (d *Document) Text(x, y float64, font Font, size float64, text string) {
+	fmt.Fprintf(d.page(), "BT /F%d %s Tf %s %s Td (%s) Tj ET\n", font, num(size), num(x), num(PageHeight-y), escape(encode(text)))
+}
+
+// TextRight draws the text with its baseline ending at the point.
+func (d *Document) TextRight(x, y float64, font Font, size float64, text string) {
+	d.Text(x-TextWidth(font, size, text), y, font, size, text)
+}
+
+// Line draws a line in the gray level, from 0 for black to 1 for white.
+func (d *Document) Line(x1, y1, x2, y2, width, gray float64) {
+	fmt.Fprintf(d.page(), "%s G %s w %s %s m %s %s l S\n", num(gray), num(width), num(x1), num(PageHeight-y1), num(x2), num(PageHeight-y2))
+}
+
+// Rect fills the rectangle, from its top left corner, in the gray level.
+func (d *Document) Rect(x, y, width, height, gray float64) {
+	fmt.Fprintf(d.page(), "%s g %s %s %s %s re f 0 g\n", num(gray), num(x), num(PageHeight-y-height), num(width), num(height))
+}
+
+// WriteTo writes the document, with at least one page, to the writer.
+func (d *Document) WriteTo(w io.Writer) (int64, error) {
+	d.page()
+
+	// The objects are numbered from 1: the catalog, the page tree, the
+	// information dictionary, the fonts, then each page and its content.
+	const (
+		catalog = 1 + iota
+		pageTree
+		info
+		fonts
+	)
+	firstPage := fonts + len(fontNames)
+
+	var out bytes.Buffer
+	out.WriteString("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+	offsets := []int{0}
+	object := func(content string, stream []byte) {
+		offsets = append(offsets, out.Len())
+		fmt.Fprintf(&out, "%d 0 obj\n%s\n", len(offsets)-1, content)
+		if stream != nil {
+			out.WriteString("stream\n")
+			out.Write(stream)
+			out.WriteString("\nendstream\n")
+		}
+		out.WriteString("endobj\n")
+	}
+
+	object(fmt.Sprintf("<< /Type /Catalog /Pages %d 0 R >>", pageTree), nil)
+
+	kids := make([]string, len(d.pages))
+	for i := range d.pages {
+		kids[i] = fmt.Sprintf("%d 0 R", firstPage+2*i)
+	}
+	object(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d /MediaBox [0 0 %s %s] >>",
+		strings.Join(kids, " "), len(d.pages), num(PageWidth), num(PageHeight)), nil)
+
+	object(fmt.Sprintf("<< /Title (%s) /Producer (dimdim) >>", escape(encode(d.Title))), nil)
+
+	resources := make([]string, len(fontNames))
+	for i, name := range fontNames {
+		object(fmt.Sprintf("<< /Type /Font /Subtype /Type1 /BaseFont /%s /Encoding /WinAnsiEncoding >>", name), nil)
+		resources[i] = fmt.Sprintf("/F%d %d 0 R", i, fonts+i)
+	}
+
+	for i, content := range d.pages {
+		var stream bytes.Buffer
+		zw := zlib.NewWriter(&stream)
+		if _, err := zw.Write(content.Bytes()); err != nil {
+			return 0, fmt.Errorf("failed to compress the page %d: %w", i+1, err)
+		}
+		if err := zw.Close(); err != nil {
+			return 0, fmt.Errorf("failed to compress the page %d: %w", i+1, err)
+		}
+
+		object(fmt.Sprintf("<< /Type /Page /Parent %d 0 R /Resources << /Font << %s >> >> /Contents %d 0 R >>",
+			pageTree, strings.Join(resources, " "), firstPage+2*i+1), nil)
+		object(fmt.Sprintf("<< /Length %d /Filter /FlateDecode >>", stream.Len()), stream.Bytes())
+	}
+
+	xref := out.Len()
+	fmt.Fprintf(&out, "xref\n0 %d\n0000000000 65535 f \n", len(offsets))
+	for _, offset := range offsets[1:] {
+		fmt.Fprintf(&out, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&out, "trailer\n<< /Size %d /Root %d 0 R /Info %d 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets), catalog, info, xref)
+
+	return out.WriteTo(w)
+}
+
+// TextWidth returns the width of the text in the font and size, in points.
+func TextWidth(font Font, size float64, text string) float64 {
+	widths := helveticaWidths
+	if font == Bold {
+		widths = helveticaBoldWidths
+	}
+
+	total := 0
+	for _, b := range encode(text) {
+		total += width(widths, b)
+	}
+
+	return float64(total) * size / 1000
+}
+
+// encode encodes the text in Windows-1252, the WinAnsiEncoding of the fonts.
+func encode(text string) []byte {
+	encoded := make([]byte, 0, len(text))
+	for _, r := range text {
+		switch r {
+		// The narrow spaces some locales use to group digits.
+		case '\u202f', '\u2009', '\u2007':
+			r = ' '
+		}
+
+		b, ok := charmap.Windows1252.EncodeRune(r)
+		if !ok || b < ' ' {
+			b = '?'
+		}
+		encoded = append(encoded, b)
+	}
+
+	return encoded
+}
+
+// escape escapes the encoded text for a PDF string.
+func escape(text []byte) string {
+	var sb strings.Builder
+	for _, b := range text {
+		switch b {
+		case '\\', '(', ')':
+			sb.WriteByte('\\')
+		}
+		sb.WriteByte(b)
+	}
+
+	return sb.String()
+}
+
+// num formats the number with two decimals.
+func num(f float64) string {
+	return strconv.FormatFloat(f, 'f', 2, 64)
+}
@@ -0,0 +1,112 @@
+package pdf_test
+
+import (
+	"bytes"
+	"compress/zlib"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/garnizeH/dimdim/pkg/pdf"
+)
+
+var (
+	objectPattern = regexp.MustCompile(`(?m)^(\d+) 0 obj$`)
+	streamPattern = regexp.MustCompile(`(?s)stream\n(.*?)\nendstream`)
+)
+
+func TestDocumentWriteTo(t *testing.T) {
+	doc := pdf.New("Relatório (2026)")
+	doc.Text(40, 60, pdf.Bold, 16, "Relatório de contas")
+	doc.TextRight(555, 60, pdf.Regular, 10, `R$ 1.500,00 (a\b) 日本`)
+	doc.Line(40, 70, 555, 70, 0.5, 0.8)
+	doc.AddPage()
+	doc.Rect(40, 80, 100, 20, 0.5)
+
+	var buf bytes.Buffer
+	if _, err := doc.WriteTo(&buf); err != nil {
+		t.Fatalf("Document.WriteTo() error = %v", err)
+	}
+	out := buf.Bytes()
+
+	if !bytes.HasPrefix(out, []byte("%PDF-1.4\n")) || !bytes.HasSuffix(out, []byte("%%EOF\n")) {
+		t.Fatalf("Document.WriteTo() = %q, want a PDF document", out)
+	}
+	if got := doc.Pages(); got != 2 {
+		t.Errorf("Document.Pages() = %d, want 2", got)
+	}
+	if !bytes.Contains(out, []byte("/Count 2")) {
+		t.Errorf("Document.WriteTo() = %q, want 2 pages in the page tree", out)
+	}
+	if !bytes.Contains(out, []byte("/Title (Relat\xf3rio \\(2026\\))")) {
+		t.Errorf("Document.WriteTo() = %q, want the title encoded and escaped", out)
+	}
+
+	// Every object starts at the offset given by the cross-reference table.
+	xref := bytes.LastIndex(out, []byte("startxref\n"))
+	start, err := strconv.Atoi(strings.Fields(string(out[xref:]))[1])
+	if err != nil || !bytes.HasPrefix(out[start:], []byte("xref\n")) {
+		t.Fatalf("startxref = %d, want the offset of the cross-reference table", start)
+	}
+	entries := strings.Split(string(out[start:]), "\n")[3:]
+	objects := objectPattern.FindAllSubmatchIndex(out, -1)
+	for i, m := range objects {
+		offset, err := strconv.Atoi(entries[i][:10])
+		if err != nil || offset != m[0] {
+			t.Errorf("object %s is at %d, the cross-reference table says %q", out[m[2]:m[3]], m[0], entries[i])
+		}
+	}
+
+	var contents []string
+	for _, m := range streamPattern.FindAllSubmatch(out, -1) {
+		r, err := zlib.NewReader(bytes.NewReader(m[1]))
+		if err != nil {
+			t.Fatalf("failed to decompress the page: %v", err)
+		}
+		content, err := io.ReadAll(r)
+		if err != nil {
+			t.Fatalf("failed to decompress the page: %v", err)
+		}
+		contents = append(contents, string(content))
+	}
+	if len(contents) != 2 {
+		t.Fatalf("Document.WriteTo() has %d page contents, want 2", len(contents))
+	}
+
+	for _, want := range []string{
+		"BT /F1 16.00 Tf 40.00 781.89 Td (Relat\xf3rio de contas) Tj ET",
+		"(R$ 1.500,00 \\(a\\\\b\\) ??) Tj",
+		"0.80 G 0.50 w 40.00 771.89 m 555.00 771.89 l S",
+	} {
+		if !strings.Contains(contents[0], want) {
+			t.Errorf("first page = %q, want it to contain %q", contents[0], want)
+		}
+	}
+	if want := "0.50 g 40.00 741.89 100.00 20.00 re f"; !strings.Contains(contents[1], want) {
+		t.Errorf("second page = %q, want it to contain %q", contents[1], want)
+	}
+}
+
+func TestTextWidth(t *testing.T) {
+	tests := []struct {
+		name string
+		font pdf.Font
+		size float64
+		text string
+		want float64
+	}{
+		{name: "regular", font: pdf.Regular, size: 10, text: "Hi 1", want: 10 * (722 + 222 + 278 + 556) / 1000.0},
+		{name: "bold", font: pdf.Bold, size: 10, text: "Hi 1", want: 10 * (722 + 278 + 278 + 556) / 1000.0},
+		{name: "accents", font: pdf.Regular, size: 1000, text: "çã", want: 500 + 556},
+		{name: "empty", font: pdf.Regular, size: 12},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := pdf.TextWidth(tt.font, tt.size, tt.text); got != tt.want {
+				t.Errorf("TextWidth() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,257 @@
+// Package pix parses the Pix copy-and-paste codes (pix copia e cola), the
+// payload of the Pix QR codes in the EMV merchant-presented format of the
+// BR Code.
+package pix
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/garnizeH/dimdim/pkg/money"
+)
+
+const (
+	// gui is the globally unique identifier of the Pix merchant account information.
+	gui = "br.gov.bcb.pix"
+	// currencyReal is the ISO 4217 numeric code of the real.
+	currencyReal = "986"
+	// noTxID is the transaction id of the payloads without one.
+	noTxID = "***"
+	// crcField is the id and the length of the CRC field, the last one of the payload.
+	crcField  = "6304"
+	crcLength = 4
+)
+
+// The ids of the payload fields.
+const (
+	idPayloadFormat        = "00"
+	idMerchantAccountFirst = 26
+	idMerchantAccountLast  = 51
+	idCurrency             = "53"
+	idAmount               = "54"
+	idMerchantName         = "59"
+	idMerchantCity         = "60"
+	idAdditionalData       = "62"
+
+	// the fields of the merchant account information
+	idGUI         = "00"
+	idKey         = "01"
+	idDescription = "02"
+	idURL         = "25"
+
+	// the fields of the additional data
+	idTxID = "05"
+)
+
+var (
+	ErrInvalidCode     = errors.New("invalid pix code")
+	ErrInvalidChecksum = errors.New("invalid pix code checksum")
+)
+
+// Payment is the information encoded in a Pix code.
+type Payment struct {
+	// Payload is the copy-and-paste code.
+	Payload string
+	// Key is the Pix key of the receiver, empty in the dynamic codes.
+	Key string
+	// URL is the location of the payment of the dynamic codes.
+	URL         string
+	Description string
+	// MerchantName is the name of the receiver.
+	MerchantName string
+	MerchantCity string
+	// Amount is zero when the code leaves the amount to the payer.
+	Amount money.Money
+	// TxID is the identifier of the transaction chosen by the receiver, if any.
+	TxID string
+}
+
+// Parse parses and validates a Pix copy-and-paste code.
+func Parse(code string) (Payment, error) {
+	payload := strings.TrimSpace(code)
+	if len(payload) < len(crcField)+crcLength {
+		return Payment{}, ErrInvalidCode
+	}
+
+	data, crc := payload[:len(payload)-crcLength], payload[len(payload)-crcLength:]
+	if !strings.HasSuffix(data, crcField) {
+		return Payment{}, ErrInvalidCode
+	}
+	if !strings.EqualFold(crc, fmt.Sprintf("%04X", crc16(data))) {
+		return Payment{}, ErrInvalidChecksum
+	}
+
+	fields, err := parseFields(payload)
+	if err != nil {
+		return Payment{}, err
+	}
+	if len(fields) == 0 || fields[0].id != idPayloadFormat || fields[0].value != "01" {
+		return Payment{}, ErrInvalidCode
+	}
+
+	p := Payment{Payload: payload}
+	found := false
+	for _, f := range fields {
+		switch f.id {
+		case idCurrency:
+			if f.value != currencyReal {
+				return Payment{}, ErrInvalidCode
+			}
+		case idAmount:
+			amount, err := parseAmount(f.value)
+			if err != nil {
+				return Payment{}, err
+			}
+			p.Amount = amount
+		case idMerchantName:
+			p.MerchantName = strings.TrimSpace(f.value)
+		case idMerchantCity:
+			p.MerchantCity = strings.TrimSpace(f.value)
+		case idAdditionalData:
+			sub, err := parseFields(f.value)
+			if err != nil {
+				return Payment{}, err
+			}
+			if txID := sub.get(idTxID); txID != noTxID {
+				p.TxID = txID
+			}
+		default:
+			id, err := strconv.Atoi(f.id)
+			if err != nil || id < idMerchantAccountFirst || id > idMerchantAccountLast || found {
+				continue
+			}
+
+			sub, err := parseFields(f.value)
+			if err != nil {
+				return Payment{}, err
+			}
+			if !strings.EqualFold(sub.get(idGUI), gui) {
+				continue
+			}
+			p.Key = sub.get(idKey)
+			p.Description = sub.get(idDescription)
+			p.URL = sub.get(idURL)
+			found = true
+		}
+	}
+
+	if !found || (p.Key == "" && p.URL == "") || p.MerchantName == "" {
+		return Payment{}, ErrInvalidCode
+	}
+	if p.Amount.Currency == "" {
+		p.Amount = money.New(0, "BRL")
+	}
+
+	return p, nil
+}
+
+// Dynamic reports whether the payment is fetched from the URL of the receiver
+// instead of encoded in the code.
+func (p Payment) Dynamic() bool {
+	return p.URL != ""
+}
+
+type field struct {
+	id    string
+	value string
+}
+
+type fields []field
+
+// parseFields splits the value in the fields of two digits of id, two digits
+// of length and the value. The length counts the characters of the value, not
+// its bytes, so the accented names and cities are read whole.
+func parseFields(s string) (fields, error) {
+	var fs fields
+	for len(s) > 0 {
+		if len(s) < 4 {
+			return nil, ErrInvalidCode
+		}
+
+		length, err := strconv.Atoi(s[2:4])
+		if err != nil || length == 0 {
+			return nil, ErrInvalidCode
+		}
+		end := runeOffset(s[4:], length)
+		if end < 0 {
+			return nil, ErrInvalidCode
+		}
+		fs = append(fs, field{id: s[:2], value: s[4 : 4+end]})
+		s = s[4+end:]
+	}
+
+	return fs, nil
+}
+
+// runeOffset returns the byte offset of the end of the first n characters of
+// s, or -1 when s is shorter.
+func runeOffset(s string, n int) int {
+	for i := range s {
+		if n == 0 {
+			return i
+		}
+		n--
+	}
+	if n == 0 {
+		return len(s)
+	}
+
+	return -1
+}
+
+func (fs fields) get(id string) string {
+	for _, f := range fs {
+		if f.id == id {
+			return f.value
+		}
+	}
+
+	return ""
+}
+
+// parseAmount parses the amount in the format of the payload, the digits with
+// an optional dot and up to two decimal digits.
+func parseAmount(s string) (money.Money, error) {
+	integer, fraction, _ := strings.Cut(s, ".")
+	if integer == "" || len(fraction) > 2 || !digits(integer) || !digits(fraction) {
+		return money.Money{}, ErrInvalidCode
+	}
+	fraction += strings.Repeat("0", 2-len(fraction))
+
+	amount, err := strconv.ParseInt(integer+fraction, 10, 64)
+	if err != nil {
+		return money.Money{}, ErrInvalidCode
+	}
+
+	return money.New(amount, "BRL"), nil
+}
+
+func digits(s string) bool {
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+
+	return true
+}
+
+// crc16 is the CRC-16/CCITT-FALSE of the payload, with the polynomial 0x1021
+// and the initial value 0xFFFF.
+func crc16(s string) uint16 {
+	crc := uint16(0xFFFF)
+	for i := range len(s) {
+		crc ^= uint16(s[i]) << 8
+		for range 8 {
+			if crc&0x8000 != 0 {
+				crc = crc<<1 ^ 0x1021
+			} else {
+				crc <<= 1
+			}
+		}
+	}
+
+	return crc
+}
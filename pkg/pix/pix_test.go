@@ -0,0 +1,153 @@
+package pix_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/garnizeH/dimdim/pkg/money"
+	"github.com/garnizeH/dimdim/pkg/pix"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name    string
+		code    string
+		want    pix.Payment
+		wantErr error
+	}{
+		{
+			name: "static without amount",
+			code: "00020126580014br.gov.bcb.pix0136123e4567-e12b-12d1-a456-4266554400005204000053039865802BR5913Fulano de Tal6008BRASILIA62070503***63041D3D",
+			want: pix.Payment{
+				Payload:      "00020126580014br.gov.bcb.pix0136123e4567-e12b-12d1-a456-4266554400005204000053039865802BR5913Fulano de Tal6008BRASILIA62070503***63041D3D",
+				Key:          "123e4567-e12b-12d1-a456-426655440000",
+				MerchantName: "Fulano de Tal",
+				MerchantCity: "BRASILIA",
+				Amount:       money.New(0, "BRL"),
+			},
+		},
+		{
+			name: "static with amount, description and transaction id",
+			code: "  00020101021126560014br.gov.bcb.pix0118fulano@example.com0212Aluguel maio52040000530398654071250.005802BR5913Fulano de Tal6009SAO PAULO62130509ALUGUEL0563047D58\n",
+			want: pix.Payment{
+				Payload:      "00020101021126560014br.gov.bcb.pix0118fulano@example.com0212Aluguel maio52040000530398654071250.005802BR5913Fulano de Tal6009SAO PAULO62130509ALUGUEL0563047D58",
+				Key:          "fulano@example.com",
+				Description:  "Aluguel maio",
+				MerchantName: "Fulano de Tal",
+				MerchantCity: "SAO PAULO",
+				Amount:       money.New(125000, "BRL"),
+				TxID:         "ALUGUEL05",
+			},
+		},
+		{
+			name: "amount without decimals",
+			code: "00020126400014br.gov.bcb.pix0118fulano@example.com5204000053039865402105802BR5913Fulano de Tal6009SAO PAULO62070503***630433E1",
+			want: pix.Payment{
+				Payload:      "00020126400014br.gov.bcb.pix0118fulano@example.com5204000053039865402105802BR5913Fulano de Tal6009SAO PAULO62070503***630433E1",
+				Key:          "fulano@example.com",
+				MerchantName: "Fulano de Tal",
+				MerchantCity: "SAO PAULO",
+				Amount:       money.New(1000, "BRL"),
+			},
+		},
+		{
+			name: "dynamic",
+			code: "00020101021226800014BR.GOV.BCB.PIX2558pix.example.com/qr/v2/9d36b84f-c70b-478f-b95c-12729b90ca255204000053039865802BR5912Loja Exemplo6014RIO DE JANEIRO62070503***63041229",
+			want: pix.Payment{
+				Payload:      "00020101021226800014BR.GOV.BCB.PIX2558pix.example.com/qr/v2/9d36b84f-c70b-478f-b95c-12729b90ca255204000053039865802BR5912Loja Exemplo6014RIO DE JANEIRO62070503***63041229",
+				URL:          "pix.example.com/qr/v2/9d36b84f-c70b-478f-b95c-12729b90ca25",
+				MerchantName: "Loja Exemplo",
+				MerchantCity: "RIO DE JANEIRO",
+				Amount:       money.New(0, "BRL"),
+			},
+		},
+		{
+			name: "accented name and city",
+			code: "00020126400014br.gov.bcb.pix0118fulano@example.com5204000053039865802BR5917João da Conceição6015SÃO JOSÉ DO RIO62070503***630461D3",
+			want: pix.Payment{
+				Payload:      "00020126400014br.gov.bcb.pix0118fulano@example.com5204000053039865802BR5917João da Conceição6015SÃO JOSÉ DO RIO62070503***630461D3",
+				Key:          "fulano@example.com",
+				MerchantName: "João da Conceição",
+				MerchantCity: "SÃO JOSÉ DO RIO",
+				Amount:       money.New(0, "BRL"),
+			},
+		},
+		{
+			name: "lowercase checksum",
+			code: "00020126580014br.gov.bcb.pix0136123e4567-e12b-12d1-a456-4266554400005204000053039865802BR5913Fulano de Tal6008BRASILIA62070503***63041d3d",
+			want: pix.Payment{
+				Payload:      "00020126580014br.gov.bcb.pix0136123e4567-e12b-12d1-a456-4266554400005204000053039865802BR5913Fulano de Tal6008BRASILIA62070503***63041d3d",
+				Key:          "123e4567-e12b-12d1-a456-426655440000",
+				MerchantName: "Fulano de Tal",
+				MerchantCity: "BRASILIA",
+				Amount:       money.New(0, "BRL"),
+			},
+		},
+		{
+			name:    "checksum",
+			code:    "00020126580014br.gov.bcb.pix0136123e4567-e12b-12d1-a456-4266554400005204000053039865802BR5913Fulano de Tal6008BRASILIA62070503***63041D3E",
+			wantErr: pix.ErrInvalidChecksum,
+		},
+		{
+			name:    "changed content",
+			code:    "00020126580014br.gov.bcb.pix0136123e4567-e12b-12d1-a456-4266554400005204000053039865802BR5913Fulano de Sal6008BRASILIA62070503***63041D3D",
+			wantErr: pix.ErrInvalidChecksum,
+		},
+		{
+			name:    "not a pix account",
+			code:    "00020126230014br.com.example0101x5204000053039865802BR5913Fulano de Tal6009SAO PAULO6304AB64",
+			wantErr: pix.ErrInvalidCode,
+		},
+		{
+			name:    "no key nor url",
+			code:    "00020126310014br.gov.bcb.pix0209sem chave5204000053039865802BR5913Fulano de Tal6009SAO PAULO62070503***630488DF",
+			wantErr: pix.ErrInvalidCode,
+		},
+		{
+			name:    "foreign currency",
+			code:    "00020126400014br.gov.bcb.pix0118fulano@example.com5204000053038405802BR5913Fulano de Tal6009SAO PAULO62070503***63042BF0",
+			wantErr: pix.ErrInvalidCode,
+		},
+		{
+			name:    "amount with comma",
+			code:    "00020126400014br.gov.bcb.pix0118fulano@example.com520400005303986540512,505802BR5913Fulano de Tal6009SAO PAULO62070503***6304B9EB",
+			wantErr: pix.ErrInvalidCode,
+		},
+		{
+			name:    "no merchant name",
+			code:    "00020126400014br.gov.bcb.pix0118fulano@example.com5204000053039865802BR6009SAO PAULO62070503***6304A118",
+			wantErr: pix.ErrInvalidCode,
+		},
+		{
+			name:    "payload format",
+			code:    "00020226400014br.gov.bcb.pix0118fulano@example.com5204000053039865802BR5913Fulano de Tal6009SAO PAULO62070503***6304D08E",
+			wantErr: pix.ErrInvalidCode,
+		},
+		{
+			name:    "field longer than the payload",
+			code:    "0002012699br.gov.bcb.pix630439B2",
+			wantErr: pix.ErrInvalidCode,
+		},
+		{
+			name:    "boleto",
+			code:    "00190.50095 40144.816069 06809.350314 3 37370000000100",
+			wantErr: pix.ErrInvalidCode,
+		},
+		{
+			name:    "empty",
+			code:    "",
+			wantErr: pix.ErrInvalidCode,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := pix.Parse(tt.code)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Parse() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("Parse() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
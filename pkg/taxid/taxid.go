@@ -0,0 +1,142 @@
+// Package taxid validates the brazilian taxpayer ids, the CPF of the people
+// and the CNPJ of the companies, including the alphanumeric CNPJ issued since
+// July 2026.
+package taxid
+
+import (
+	"errors"
+	"strings"
+)
+
+const (
+	cpfLength  = 11
+	cnpjLength = 14
+)
+
+var ErrInvalidTaxID = errors.New("invalid CPF or CNPJ")
+
+type Kind int
+
+const (
+	KindCPF Kind = iota + 1
+	KindCNPJ
+)
+
+func (k Kind) String() string {
+	switch k {
+	case KindCPF:
+		return "CPF"
+	case KindCNPJ:
+		return "CNPJ"
+	default:
+		return ""
+	}
+}
+
+// TaxID is a valid CPF or CNPJ.
+type TaxID struct {
+	Kind Kind
+	// Number is the id without the punctuation, in upper case.
+	Number string
+}
+
+var (
+	cpfWeights  = []int{11, 10, 9, 8, 7, 6, 5, 4, 3, 2}
+	cnpjWeights = []int{6, 5, 4, 3, 2, 9, 8, 7, 6, 5, 4, 3, 2}
+)
+
+// Parse parses and validates a CPF or a CNPJ, with or without the punctuation.
+func Parse(s string) (TaxID, error) {
+	number := strings.Map(func(r rune) rune {
+		switch r {
+		case ' ', '.', '-', '/':
+			return -1
+		}
+		return r
+	}, strings.ToUpper(strings.TrimSpace(s)))
+
+	switch len(number) {
+	case cpfLength:
+		if !digits(number) || !checkDigits(number, cpfWeights) {
+			return TaxID{}, ErrInvalidTaxID
+		}
+		return TaxID{Kind: KindCPF, Number: number}, nil
+	case cnpjLength:
+		if !alphanumeric(number[:12]) || !digits(number[12:]) || !checkDigits(number, cnpjWeights) {
+			return TaxID{}, ErrInvalidTaxID
+		}
+		return TaxID{Kind: KindCNPJ, Number: number}, nil
+	default:
+		return TaxID{}, ErrInvalidTaxID
+	}
+}
+
+// String returns the id with the usual punctuation, e.g. 123.456.789-09 or
+// 12.345.678/0001-95.
+func (id TaxID) String() string {
+	n := id.Number
+	switch id.Kind {
+	case KindCPF:
+		return n[0:3] + "." + n[3:6] + "." + n[6:9] + "-" + n[9:11]
+	case KindCNPJ:
+		return n[0:2] + "." + n[2:5] + "." + n[5:8] + "/" + n[8:12] + "-" + n[12:14]
+	default:
+		return n
+	}
+}
+
+// checkDigits validates the two last check digits of the number, computed
+// with mod 11 over the weights, the first one ignoring the first weight. The
+// ids with a single repeated character are rejected, as they pass the check.
+func checkDigits(number string, weights []int) bool {
+	if strings.Count(number, number[:1]) == len(number) {
+		return false
+	}
+
+	n := len(number) - 2
+	for i := range 2 {
+		if checkDigit(number[:n+i], weights[1-i:]) != value(number[n+i]) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func checkDigit(s string, weights []int) int {
+	sum := 0
+	for i := range len(s) {
+		sum += value(s[i]) * weights[i]
+	}
+	if r := sum % 11; r >= 2 {
+		return 11 - r
+	}
+
+	return 0
+}
+
+// value is the value of the character in the check digits, its ASCII code
+// minus 48, so the digits are themselves and the letters start at 17.
+func value(c byte) int {
+	return int(c) - '0'
+}
+
+func digits(s string) bool {
+	for i := range len(s) {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+
+	return true
+}
+
+func alphanumeric(s string) bool {
+	for i := range len(s) {
+		if (s[i] < '0' || s[i] > '9') && (s[i] < 'A' || s[i] > 'Z') {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,66 @@
+package taxid_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/garnizeH/dimdim/pkg/taxid"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name       string
+		s          string
+		want       taxid.TaxID
+		wantString string
+		wantErr    error
+	}{
+		{
+			name:       "cpf",
+			s:          "529.982.247-25",
+			want:       taxid.TaxID{Kind: taxid.KindCPF, Number: "52998224725"},
+			wantString: "529.982.247-25",
+		},
+		{
+			name:       "cpf without punctuation",
+			s:          " 52998224725 ",
+			want:       taxid.TaxID{Kind: taxid.KindCPF, Number: "52998224725"},
+			wantString: "529.982.247-25",
+		},
+		{
+			name:       "cnpj",
+			s:          "11.222.333/0001-81",
+			want:       taxid.TaxID{Kind: taxid.KindCNPJ, Number: "11222333000181"},
+			wantString: "11.222.333/0001-81",
+		},
+		{
+			name:       "alphanumeric cnpj",
+			s:          "12.abc.345/01de-35",
+			want:       taxid.TaxID{Kind: taxid.KindCNPJ, Number: "12ABC34501DE35"},
+			wantString: "12.ABC.345/01DE-35",
+		},
+		{name: "cpf first check digit", s: "529.982.247-35", wantErr: taxid.ErrInvalidTaxID},
+		{name: "cpf second check digit", s: "529.982.247-24", wantErr: taxid.ErrInvalidTaxID},
+		{name: "cpf with letters", s: "529.982.24A-25", wantErr: taxid.ErrInvalidTaxID},
+		{name: "repeated digits", s: "111.111.111-11", wantErr: taxid.ErrInvalidTaxID},
+		{name: "cnpj check digit", s: "11.222.333/0001-82", wantErr: taxid.ErrInvalidTaxID},
+		{name: "cnpj with letters in the check digits", s: "12.ABC.345/01DE-3A", wantErr: taxid.ErrInvalidTaxID},
+		{name: "repeated zeros", s: "00.000.000/0000-00", wantErr: taxid.ErrInvalidTaxID},
+		{name: "wrong length", s: "1234", wantErr: taxid.ErrInvalidTaxID},
+		{name: "empty", s: "", wantErr: taxid.ErrInvalidTaxID},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := taxid.Parse(tt.s)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Parse(%q) error = %v, wantErr %v", tt.s, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("Parse(%q) = %+v, want %+v", tt.s, got, tt.want)
+			}
+			if err == nil && got.String() != tt.wantString {
+				t.Errorf("TaxID.String() = %q, want %q", got.String(), tt.wantString)
+			}
+		})
+	}
+}
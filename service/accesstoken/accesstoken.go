@@ -0,0 +1,254 @@
+package accesstoken
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/garnizeH/dimdim/storage"
+	"github.com/garnizeH/dimdim/storage/datastore"
+)
+
+const (
+	// Prefix starts every token, so leaked tokens are easy to find in code
+	// and logs.
+	Prefix = "dimdim_"
+
+	ScopeRead  = "read"
+	ScopeWrite = "write"
+
+	// MaxTokens bounds the tokens of a user, expired ones included.
+	MaxTokens = 10
+	// MaxNameLength bounds the length of the names of the tokens.
+	MaxNameLength = 50
+	// MaxLifetime bounds the lifetime of the tokens, which always expire.
+	MaxLifetime = 365 * 24 * time.Hour
+
+	// touchInterval is how often the last use of a token is recorded, so the
+	// requests of a script do not all write to the database.
+	touchInterval = time.Minute
+)
+
+// Scopes are the scopes of the tokens, each one allowing the ones before it.
+var Scopes = []string{ScopeRead, ScopeWrite}
+
+var (
+	ErrInvalidToken    = errors.New("invalid access token")
+	ErrTokenNotFound   = errors.New("access token not found")
+	ErrInvalidName     = errors.New("invalid access token name")
+	ErrInvalidScope    = errors.New("invalid access token scope")
+	ErrInvalidLifetime = errors.New("invalid access token lifetime")
+	ErrTooManyTokens   = errors.New("too many access tokens")
+)
+
+type Service struct {
+	db *storage.DB[datastore.Queries]
+}
+
+func New(db *storage.DB[datastore.Queries]) *Service {
+	return &Service{
+		db: db,
+	}
+}
+
+// Token is a personal access token of the API. Only the hash of the secret is
+// stored, the secret itself is shown once when the token is created.
+type Token struct {
+	ID         int64
+	Email      string
+	Name       string
+	Scope      string
+	ExpiresAt  time.Time
+	LastUsedAt time.Time
+	CreatedAt  time.Time
+}
+
+// Allows reports whether the token can be used for the scope.
+func (t Token) Allows(scope string) bool {
+	return slices.Index(Scopes, t.Scope) >= slices.Index(Scopes, scope)
+}
+
+// Expired reports whether the token can no longer be used at now.
+func (t Token) Expired(now time.Time) bool {
+	return !now.Before(t.ExpiresAt)
+}
+
+// Create creates a token of the user and returns it with its secret.
+func (s *Service) Create(
+	ctx context.Context,
+	email string,
+	name string,
+	scope string,
+	lifetime time.Duration,
+) (Token, string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" || utf8.RuneCountInString(name) > MaxNameLength {
+		return Token{}, "", ErrInvalidName
+	}
+	if !slices.Contains(Scopes, scope) {
+		return Token{}, "", ErrInvalidScope
+	}
+	if lifetime <= 0 || lifetime > MaxLifetime {
+		return Token{}, "", ErrInvalidLifetime
+	}
+
+	secret := Prefix + rand.Text()
+	var row datastore.AccessToken
+	if err := s.db.Write(ctx, func(queries *datastore.Queries) error {
+		count, err := queries.CountAccessTokensByEmail(ctx, email)
+		if err != nil {
+			return fmt.Errorf("failed to count the access tokens in the database: %w", err)
+		}
+		if count >= MaxTokens {
+			return ErrTooManyTokens
+		}
+
+		row, err = queries.CreateAccessToken(ctx, datastore.CreateAccessTokenParams{
+			Email:     email,
+			Name:      name,
+			Hash:      hash(secret),
+			Scope:     scope,
+			ExpiresAt: time.Now().Add(lifetime).UTC().UnixMilli(),
+		})
+		if err != nil {
+			return fmt.Errorf("failed to create the access token in the database: %w", err)
+		}
+
+		return nil
+	}); err != nil {
+		return Token{}, "", err
+	}
+
+	return newToken(row), secret, nil
+}
+
+// List returns the tokens of the user, the latest first.
+func (s *Service) List(ctx context.Context, email string) ([]Token, error) {
+	var rows []datastore.AccessToken
+	if err := s.db.Read(ctx, func(queries *datastore.Queries) error {
+		var err error
+		rows, err = queries.ListAccessTokensByEmail(ctx, email)
+		if err != nil {
+			return fmt.Errorf("failed to list the access tokens from the database: %w", err)
+		}
+
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	tokens := make([]Token, len(rows))
+	for i, row := range rows {
+		tokens[i] = newToken(row)
+	}
+
+	return tokens, nil
+}
+
+// Revoke deletes the token of the user, which stops working at once.
+func (s *Service) Revoke(ctx context.Context, email string, id int64) error {
+	return s.db.Write(ctx, func(queries *datastore.Queries) error {
+		n, err := queries.DeleteAccessToken(ctx, datastore.DeleteAccessTokenParams{
+			ID:    id,
+			Email: email,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to delete the access token in the database: %w", err)
+		}
+		if n == 0 {
+			return ErrTokenNotFound
+		}
+
+		return nil
+	})
+}
+
+// Authenticate returns the token of the secret when it is not expired and its
+// user can still sign in, recording its use.
+func (s *Service) Authenticate(ctx context.Context, secret string, now time.Time) (Token, error) {
+	if !strings.HasPrefix(secret, Prefix) {
+		return Token{}, ErrInvalidToken
+	}
+
+	var row datastore.AccessToken
+	if err := s.db.Read(ctx, func(queries *datastore.Queries) error {
+		var err error
+		row, err = queries.GetAccessTokenNotExpired(ctx, datastore.GetAccessTokenNotExpiredParams{
+			Hash:      hash(secret),
+			ExpiresAt: now.UTC().UnixMilli(),
+		})
+		if err != nil {
+			if storage.NoRows(err) {
+				return ErrInvalidToken
+			}
+
+			return fmt.Errorf("failed to get the access token from the database: %w", err)
+		}
+
+		// The deleted users are not found.
+		user, err := queries.GetUser(ctx, row.Email)
+		if err != nil {
+			if storage.NoRows(err) {
+				return ErrInvalidToken
+			}
+
+			return fmt.Errorf("failed to get the user from the database: %w", err)
+		}
+		if user.DisabledAt > 0 {
+			return ErrInvalidToken
+		}
+
+		return nil
+	}); err != nil {
+		return Token{}, err
+	}
+
+	if now.UnixMilli()-row.LastUsedAt >= touchInterval.Milliseconds() {
+		row.LastUsedAt = now.UTC().UnixMilli()
+		if err := s.db.Write(ctx, func(queries *datastore.Queries) error {
+			return queries.TouchAccessToken(ctx, datastore.TouchAccessTokenParams{
+				LastUsedAt: row.LastUsedAt,
+				ID:         row.ID,
+			})
+		}); err != nil {
+			return Token{}, fmt.Errorf("failed to record the use of the access token in the database: %w", err)
+		}
+	}
+
+	return newToken(row), nil
+}
+
+// hash returns the hash stored for the secret. The secrets are random, so a
+// fast hash is enough and, unlike a salted one, lets the tokens be looked up
+// by it.
+func hash(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
+
+func newToken(row datastore.AccessToken) Token {
+	return Token{
+		ID:         row.ID,
+		Email:      row.Email,
+		Name:       row.Name,
+		Scope:      row.Scope,
+		ExpiresAt:  time.UnixMilli(row.ExpiresAt).UTC(),
+		LastUsedAt: timeFromMilli(row.LastUsedAt),
+		CreatedAt:  time.UnixMilli(row.CreatedAt).UTC(),
+	}
+}
+
+func timeFromMilli(ms int64) time.Time {
+	if ms == 0 {
+		return time.Time{}
+	}
+
+	return time.UnixMilli(ms).UTC()
+}
@@ -0,0 +1,137 @@
+package accesstoken_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/garnizeH/dimdim/service/accesstoken"
+	"github.com/garnizeH/dimdim/storage/datastore"
+)
+
+const email = "someone@example.com"
+
+func TestServiceCreate(t *testing.T) {
+	svc := accesstoken.New(datastore.NewDBForTest(t, email))
+
+	tests := []struct {
+		name      string
+		tokenName string
+		scope     string
+		lifetime  time.Duration
+		wantErr   error
+	}{
+		{name: "valid", tokenName: "backup script", scope: accesstoken.ScopeRead, lifetime: 24 * time.Hour},
+		{name: "empty name", tokenName: " ", scope: accesstoken.ScopeRead, lifetime: 24 * time.Hour, wantErr: accesstoken.ErrInvalidName},
+		{name: "long name", tokenName: strings.Repeat("a", accesstoken.MaxNameLength+1), scope: accesstoken.ScopeRead, lifetime: 24 * time.Hour, wantErr: accesstoken.ErrInvalidName},
+		{name: "unknown scope", tokenName: "script", scope: "admin", lifetime: 24 * time.Hour, wantErr: accesstoken.ErrInvalidScope},
+		{name: "no expiration", tokenName: "script", scope: accesstoken.ScopeWrite, lifetime: 0, wantErr: accesstoken.ErrInvalidLifetime},
+		{name: "too long", tokenName: "script", scope: accesstoken.ScopeWrite, lifetime: accesstoken.MaxLifetime + time.Hour, wantErr: accesstoken.ErrInvalidLifetime},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			token, secret, err := svc.Create(context.Background(), email, tt.tokenName, tt.scope, tt.lifetime)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Service.Create() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+
+			if !strings.HasPrefix(secret, accesstoken.Prefix) || token.Name != tt.tokenName || token.Scope != tt.scope || token.Email != email {
+				t.Errorf("Service.Create() = %+v, %q", token, secret)
+			}
+		})
+	}
+}
+
+func TestServiceAuthenticate(t *testing.T) {
+	db := datastore.NewDBForTest(t, email)
+	svc := accesstoken.New(db)
+	ctx := context.Background()
+	now := time.Now()
+
+	token, secret, err := svc.Create(ctx, email, "script", accesstoken.ScopeWrite, time.Hour)
+	if err != nil {
+		t.Fatalf("Service.Create() error = %v", err)
+	}
+
+	got, err := svc.Authenticate(ctx, secret, now)
+	if err != nil {
+		t.Fatalf("Service.Authenticate() error = %v", err)
+	}
+	if got.ID != token.ID || !got.Allows(accesstoken.ScopeRead) || !got.Allows(accesstoken.ScopeWrite) || got.LastUsedAt.IsZero() {
+		t.Errorf("Service.Authenticate() = %+v", got)
+	}
+
+	tests := []struct {
+		name   string
+		secret string
+		now    time.Time
+	}{
+		{name: "other secret", secret: accesstoken.Prefix + "AAAAAAAAAAAAAAAAAAAAAAAAAA", now: now},
+		{name: "no prefix", secret: strings.TrimPrefix(secret, accesstoken.Prefix), now: now},
+		{name: "expired", secret: secret, now: now.Add(time.Hour + time.Minute)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := svc.Authenticate(ctx, tt.secret, tt.now); !errors.Is(err, accesstoken.ErrInvalidToken) {
+				t.Errorf("Service.Authenticate() error = %v, want %v", err, accesstoken.ErrInvalidToken)
+			}
+		})
+	}
+
+	// The tokens of the disabled users stop working.
+	if err := db.Write(ctx, func(queries *datastore.Queries) error {
+		_, err := queries.SetUserDisabledAt(ctx, datastore.SetUserDisabledAtParams{DisabledAt: now.UnixMilli(), Email: email})
+		return err
+	}); err != nil {
+		t.Fatalf("failed to disable the user: %v", err)
+	}
+	if _, err := svc.Authenticate(ctx, secret, now); !errors.Is(err, accesstoken.ErrInvalidToken) {
+		t.Errorf("Service.Authenticate() of a disabled user error = %v, want %v", err, accesstoken.ErrInvalidToken)
+	}
+}
+
+func TestServiceRevoke(t *testing.T) {
+	svc := accesstoken.New(datastore.NewDBForTest(t, email))
+	ctx := context.Background()
+
+	token, secret, err := svc.Create(ctx, email, "script", accesstoken.ScopeRead, time.Hour)
+	if err != nil {
+		t.Fatalf("Service.Create() error = %v", err)
+	}
+	if token.Allows(accesstoken.ScopeWrite) {
+		t.Errorf("a read token allows writing")
+	}
+
+	if err := svc.Revoke(ctx, "other@example.com", token.ID); !errors.Is(err, accesstoken.ErrTokenNotFound) {
+		t.Errorf("Service.Revoke() of another user error = %v, want %v", err, accesstoken.ErrTokenNotFound)
+	}
+	if err := svc.Revoke(ctx, email, token.ID); err != nil {
+		t.Fatalf("Service.Revoke() error = %v", err)
+	}
+	if _, err := svc.Authenticate(ctx, secret, time.Now()); !errors.Is(err, accesstoken.ErrInvalidToken) {
+		t.Errorf("Service.Authenticate() of a revoked token error = %v, want %v", err, accesstoken.ErrInvalidToken)
+	}
+
+	for i := range accesstoken.MaxTokens {
+		if _, _, err := svc.Create(ctx, email, fmt.Sprintf("script %d", i), accesstoken.ScopeRead, time.Hour); err != nil {
+			t.Fatalf("Service.Create() error = %v", err)
+		}
+	}
+	if _, _, err := svc.Create(ctx, email, "one more", accesstoken.ScopeRead, time.Hour); !errors.Is(err, accesstoken.ErrTooManyTokens) {
+		t.Errorf("Service.Create() error = %v, want %v", err, accesstoken.ErrTooManyTokens)
+	}
+
+	tokens, err := svc.List(ctx, email)
+	if err != nil {
+		t.Fatalf("Service.List() error = %v", err)
+	}
+	if len(tokens) != accesstoken.MaxTokens {
+		t.Errorf("Service.List() returned %d tokens, want %d", len(tokens), accesstoken.MaxTokens)
+	}
+}
@@ -0,0 +1,507 @@
+// Package backup exports the data of a user to a versioned ZIP file and
+// restores it into an empty account, on this or another instance.
+//
+// The file holds dimdim.json, the bundle read by the import, and a CSV file
+// for each of its lists, for spreadsheets. The format is described in the
+// README.
+package backup
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/garnizeH/dimdim/pkg/money"
+	"github.com/garnizeH/dimdim/service/bill"
+	"github.com/garnizeH/dimdim/service/payee"
+	"github.com/garnizeH/dimdim/service/user"
+	"github.com/garnizeH/dimdim/storage"
+	"github.com/garnizeH/dimdim/storage/datastore"
+)
+
+const (
+	// Format identifies the bundle of the backups.
+	Format = "dimdim"
+	// Version is the version of the bundle written by the export, the import
+	// reads the versions up to it.
+	Version = 1
+
+	// BundleFile is the name of the bundle inside the backup file.
+	BundleFile = "dimdim.json"
+)
+
+var (
+	ErrInvalidBackup      = errors.New("invalid backup file")
+	ErrUnsupportedVersion = errors.New("unsupported backup version")
+	ErrAccountNotEmpty    = errors.New("backups can only be imported into an account without bills or payees")
+)
+
+// Bundle is all the data of the user in the backup. The ids are the ones of
+// the exporting instance, only used to link the records of the bundle.
+type Bundle struct {
+	Format      string      `json:"format"`
+	Version     int         `json:"version"`
+	ExportedAt  string      `json:"exported_at"`
+	Preferences Preferences `json:"preferences"`
+	Payees      []Payee     `json:"payees"`
+	Bills       []Bill      `json:"bills"`
+}
+
+type Preferences struct {
+	Locale   string `json:"locale"`
+	Timezone string `json:"timezone"`
+	Currency string `json:"currency"`
+	// FirstDayOfWeek is 0 for Sunday to 6 for Saturday.
+	FirstDayOfWeek int    `json:"first_day_of_week"`
+	DateFormat     string `json:"date_format"`
+}
+
+type Payee struct {
+	ID        int64   `json:"id"`
+	Name      string  `json:"name"`
+	City      string  `json:"city,omitempty"`
+	TaxID     string  `json:"tax_id,omitempty"`
+	CreatedAt string  `json:"created_at"`
+	Aliases   []Alias `json:"aliases"`
+}
+
+type Alias struct {
+	Kind  string `json:"kind"`
+	Value string `json:"value"`
+}
+
+type Bill struct {
+	ID int64 `json:"id"`
+	// PayeeID is the id of a payee of the bundle, or zero.
+	PayeeID int64  `json:"payee_id,omitempty"`
+	Name    string `json:"name"`
+	// Amount is a decimal number with a dot, e.g. 1500.00.
+	Amount     string `json:"amount"`
+	Currency   string `json:"currency"`
+	DueOn      string `json:"due_on"`
+	RemindDays int64  `json:"remind_days"`
+	RemindedAt string `json:"reminded_at,omitempty"`
+	PaidAt     string `json:"paid_at,omitempty"`
+	CreatedAt  string `json:"created_at"`
+	Barcode    string `json:"barcode,omitempty"`
+	Pix        string `json:"pix,omitempty"`
+}
+
+// Summary is the number of records restored by an import.
+type Summary struct {
+	Payees  int
+	Aliases int
+	Bills   int
+}
+
+type Service struct {
+	db *storage.DB[datastore.Queries]
+}
+
+func New(db *storage.DB[datastore.Queries]) *Service {
+	return &Service{
+		db: db,
+	}
+}
+
+// Export writes the backup of the user to the writer.
+func (s *Service) Export(ctx context.Context, email string, w io.Writer) error {
+	var (
+		prefs   user.Preferences
+		payees  []datastore.Payee
+		aliases []datastore.PayeeAlias
+		bills   []datastore.Bill
+	)
+	if err := s.db.Read(ctx, func(queries *datastore.Queries) error {
+		var err error
+		prefs, err = user.GetPreferences(ctx, queries, email)
+		if err != nil {
+			return fmt.Errorf("failed to get the user preferences from the database: %w", err)
+		}
+
+		payees, err = queries.ListPayeesByEmail(ctx, email)
+		if err != nil {
+			return fmt.Errorf("failed to list the payees from the database: %w", err)
+		}
+
+		aliases, err = queries.ListPayeeAliasesByEmail(ctx, email)
+		if err != nil {
+			return fmt.Errorf("failed to list the payee aliases from the database: %w", err)
+		}
+
+		bills, err = queries.ListBillsByEmail(ctx, email)
+		if err != nil {
+			return fmt.Errorf("failed to list the bills from the database: %w", err)
+		}
+
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	b := newBundle(time.Now(), prefs, payees, aliases, bills)
+
+	zw := zip.NewWriter(w)
+	err := errors.Join(
+		writeJSON(zw, BundleFile, b),
+		writeCSV(zw, "payees.csv", payeeRecords(b.Payees)),
+		writeCSV(zw, "payee_aliases.csv", aliasRecords(b.Payees)),
+		writeCSV(zw, "bills.csv", billRecords(b.Bills)),
+	)
+	if err = errors.Join(err, zw.Close()); err != nil {
+		return fmt.Errorf("failed to write the backup: %w", err)
+	}
+
+	return nil
+}
+
+func newBundle(
+	now time.Time,
+	prefs user.Preferences,
+	payees []datastore.Payee,
+	aliases []datastore.PayeeAlias,
+	bills []datastore.Bill,
+) Bundle {
+	b := Bundle{
+		Format:     Format,
+		Version:    Version,
+		ExportedAt: now.UTC().Format(time.RFC3339),
+		Preferences: Preferences{
+			Locale:         prefs.Locale,
+			Timezone:       prefs.Timezone,
+			Currency:       prefs.Currency,
+			FirstDayOfWeek: int(prefs.FirstDayOfWeek),
+			DateFormat:     prefs.DateFormat,
+		},
+		Payees: make([]Payee, len(payees)),
+		Bills:  make([]Bill, len(bills)),
+	}
+
+	index := make(map[int64]int, len(payees))
+	for i, p := range payees {
+		index[p.ID] = i
+		b.Payees[i] = Payee{
+			ID:        p.ID,
+			Name:      p.Name,
+			City:      p.City,
+			TaxID:     p.TaxID,
+			CreatedAt: formatTime(p.CreatedAt),
+			Aliases:   []Alias{},
+		}
+	}
+	for _, a := range aliases {
+		if i, ok := index[a.PayeeID]; ok {
+			b.Payees[i].Aliases = append(b.Payees[i].Aliases, Alias{Kind: a.Kind, Value: a.Value})
+		}
+	}
+
+	for i, row := range bills {
+		// The bills keep no link to the payees deleted since.
+		payeeID := row.PayeeID
+		if _, ok := index[payeeID]; !ok {
+			payeeID = 0
+		}
+
+		b.Bills[i] = Bill{
+			ID:         row.ID,
+			PayeeID:    payeeID,
+			Name:       row.Name,
+			Amount:     money.FormatDecimal("", row.Amount, money.Exponent(row.Currency)),
+			Currency:   row.Currency,
+			DueOn:      row.DueOn,
+			RemindDays: row.RemindDays,
+			RemindedAt: formatTime(row.RemindedAt),
+			PaidAt:     formatTime(row.PaidAt),
+			CreatedAt:  formatTime(row.CreatedAt),
+			Barcode:    row.Barcode,
+			Pix:        row.Pix,
+		}
+	}
+
+	return b
+}
+
+// Import restores the backup read from the reader into the account of the
+// user, which must have no bills or payees. The records get new ids and the
+// preferences of the user are replaced, all or nothing.
+func (s *Service) Import(ctx context.Context, email string, r io.ReaderAt, size int64) (Summary, error) {
+	b, err := readBundle(r, size)
+	if err != nil {
+		return Summary{}, err
+	}
+
+	var summary Summary
+	err = s.db.Write(ctx, func(queries *datastore.Queries) error {
+		if _, err := queries.GetUser(ctx, email); err != nil {
+			if storage.NoRows(err) {
+				return user.ErrEmailNotFound
+			}
+
+			return fmt.Errorf("failed to get the user from the database: %w", err)
+		}
+
+		bills, err := queries.ListBillsByEmail(ctx, email)
+		if err != nil {
+			return fmt.Errorf("failed to list the bills from the database: %w", err)
+		}
+		payees, err := queries.ListPayeesByEmail(ctx, email)
+		if err != nil {
+			return fmt.Errorf("failed to list the payees from the database: %w", err)
+		}
+		if len(bills) > 0 || len(payees) > 0 {
+			return ErrAccountNotEmpty
+		}
+
+		if err := user.SavePreferences(ctx, queries, email, user.Preferences{
+			Locale:         b.Preferences.Locale,
+			Timezone:       b.Preferences.Timezone,
+			Currency:       b.Preferences.Currency,
+			FirstDayOfWeek: time.Weekday(b.Preferences.FirstDayOfWeek),
+			DateFormat:     b.Preferences.DateFormat,
+		}); err != nil {
+			return err
+		}
+
+		// ids maps the ids of the payees of the bundle to the new ones.
+		ids := make(map[int64]int64, len(b.Payees))
+		for _, p := range b.Payees {
+			if _, ok := ids[p.ID]; ok || p.ID <= 0 {
+				return ErrInvalidBackup
+			}
+
+			name, taxID, err := payee.Validate(p.Name, p.TaxID)
+			if err != nil {
+				return ErrInvalidBackup
+			}
+			createdAt, err := parseTime(p.CreatedAt)
+			if err != nil {
+				return err
+			}
+
+			row, err := queries.ImportPayee(ctx, datastore.ImportPayeeParams{
+				Email:     email,
+				Name:      name,
+				City:      strings.TrimSpace(p.City),
+				TaxID:     taxID,
+				CreatedAt: orNow(createdAt),
+			})
+			if err != nil {
+				return fmt.Errorf("failed to create the payee in the database: %w", err)
+			}
+			ids[p.ID] = row.ID
+			summary.Payees++
+
+			for _, a := range p.Aliases {
+				value := strings.TrimSpace(a.Value)
+				if a.Kind == payee.KindDescription {
+					value = payee.Normalize(value)
+				}
+				if !slices.Contains(payee.AliasKinds, a.Kind) || value == "" {
+					return ErrInvalidBackup
+				}
+
+				n, err := queries.CreatePayeeAlias(ctx, datastore.CreatePayeeAliasParams{
+					Email:   email,
+					PayeeID: row.ID,
+					Kind:    a.Kind,
+					Value:   value,
+				})
+				if err != nil {
+					return fmt.Errorf("failed to create the payee alias in the database: %w", err)
+				}
+				summary.Aliases += int(n)
+			}
+		}
+
+		for _, bl := range b.Bills {
+			params, err := importBill(email, bl, ids)
+			if err != nil {
+				return err
+			}
+
+			if err := queries.ImportBill(ctx, params); err != nil {
+				return fmt.Errorf("failed to create the bill in the database: %w", err)
+			}
+			summary.Bills++
+		}
+
+		return nil
+	})
+	if err != nil {
+		return Summary{}, err
+	}
+
+	return summary, nil
+}
+
+// importBill validates the bill of the bundle, linking it to the new id of
+// its payee.
+func importBill(email string, b Bill, ids map[int64]int64) (datastore.ImportBillParams, error) {
+	name := strings.TrimSpace(b.Name)
+	amount, err := money.Parse(b.Amount, b.Currency)
+	if err != nil || name == "" || b.RemindDays < 0 || b.RemindDays > bill.MaxRemindDays {
+		return datastore.ImportBillParams{}, ErrInvalidBackup
+	}
+	if _, err := time.Parse(bill.DueLayout, b.DueOn); err != nil {
+		return datastore.ImportBillParams{}, ErrInvalidBackup
+	}
+
+	payeeID, ok := ids[b.PayeeID]
+	if b.PayeeID != 0 && !ok {
+		return datastore.ImportBillParams{}, ErrInvalidBackup
+	}
+
+	var times [3]int64
+	for i, s := range []string{b.RemindedAt, b.PaidAt, b.CreatedAt} {
+		if times[i], err = parseTime(s); err != nil {
+			return datastore.ImportBillParams{}, err
+		}
+	}
+
+	return datastore.ImportBillParams{
+		Email:      email,
+		Name:       name,
+		Amount:     amount.Amount,
+		Currency:   amount.Currency,
+		DueOn:      b.DueOn,
+		RemindDays: b.RemindDays,
+		RemindedAt: times[0],
+		PaidAt:     times[1],
+		CreatedAt:  orNow(times[2]),
+		Barcode:    strings.TrimSpace(b.Barcode),
+		Pix:        strings.TrimSpace(b.Pix),
+		PayeeID:    payeeID,
+	}, nil
+}
+
+// readBundle reads the bundle of the backup file, checking its version.
+func readBundle(r io.ReaderAt, size int64) (Bundle, error) {
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return Bundle{}, ErrInvalidBackup
+	}
+
+	f, err := zr.Open(BundleFile)
+	if err != nil {
+		return Bundle{}, ErrInvalidBackup
+	}
+	defer f.Close()
+
+	var b Bundle
+	if err := json.NewDecoder(f).Decode(&b); err != nil || b.Format != Format {
+		return Bundle{}, ErrInvalidBackup
+	}
+	if b.Version < 1 || b.Version > Version {
+		return Bundle{}, ErrUnsupportedVersion
+	}
+
+	return b, nil
+}
+
+func writeJSON(zw *zip.Writer, name string, v any) error {
+	w, err := zw.Create(name)
+	if err != nil {
+		return err
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+func writeCSV(zw *zip.Writer, name string, records [][]string) error {
+	w, err := zw.Create(name)
+	if err != nil {
+		return err
+	}
+
+	cw := csv.NewWriter(w)
+	if err := cw.WriteAll(records); err != nil {
+		return err
+	}
+
+	return cw.Error()
+}
+
+func payeeRecords(payees []Payee) [][]string {
+	records := [][]string{{"id", "name", "city", "tax_id", "created_at"}}
+	for _, p := range payees {
+		records = append(records, []string{strconv.FormatInt(p.ID, 10), p.Name, p.City, p.TaxID, p.CreatedAt})
+	}
+
+	return records
+}
+
+func aliasRecords(payees []Payee) [][]string {
+	records := [][]string{{"payee_id", "kind", "value"}}
+	for _, p := range payees {
+		for _, a := range p.Aliases {
+			records = append(records, []string{strconv.FormatInt(p.ID, 10), a.Kind, a.Value})
+		}
+	}
+
+	return records
+}
+
+func billRecords(bills []Bill) [][]string {
+	records := [][]string{{"id", "payee_id", "name", "amount", "currency", "due_on", "remind_days", "reminded_at", "paid_at", "created_at", "barcode", "pix"}}
+	for _, b := range bills {
+		records = append(records, []string{
+			strconv.FormatInt(b.ID, 10),
+			strconv.FormatInt(b.PayeeID, 10),
+			b.Name,
+			b.Amount,
+			b.Currency,
+			b.DueOn,
+			strconv.FormatInt(b.RemindDays, 10),
+			b.RemindedAt,
+			b.PaidAt,
+			b.CreatedAt,
+			b.Barcode,
+			b.Pix,
+		})
+	}
+
+	return records
+}
+
+// formatTime formats the unix milliseconds timestamps stored in the database.
+func formatTime(ms int64) string {
+	if ms == 0 {
+		return ""
+	}
+
+	return time.UnixMilli(ms).UTC().Format(time.RFC3339Nano)
+}
+
+// parseTime parses the timestamps of the bundle to unix milliseconds, zero
+// when empty.
+func parseTime(s string) (int64, error) {
+	if s == "" {
+		return 0, nil
+	}
+
+	t, err := time.Parse(time.RFC3339Nano, s)
+	if err != nil {
+		return 0, ErrInvalidBackup
+	}
+
+	return t.UnixMilli(), nil
+}
+
+// orNow returns the timestamp, or the current time when zero.
+func orNow(ms int64) int64 {
+	if ms == 0 {
+		return time.Now().UTC().UnixMilli()
+	}
+
+	return ms
+}
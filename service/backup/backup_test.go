@@ -0,0 +1,274 @@
+package backup_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/garnizeH/dimdim/service/backup"
+	"github.com/garnizeH/dimdim/service/user"
+	"github.com/garnizeH/dimdim/storage"
+	"github.com/garnizeH/dimdim/storage/datastore"
+)
+
+const (
+	source = "source@example.com"
+	target = "target@example.com"
+)
+
+// paidAt is the time the rent was paid, kept by the backup.
+var paidAt = time.Date(2026, time.March, 5, 13, 0, 0, 0, time.UTC)
+
+// newTestDB creates the source user, with a payee, its aliases and bills, and
+// the empty target user.
+func newTestDB(t *testing.T) *storage.DB[datastore.Queries] {
+	t.Helper()
+
+	db := datastore.NewDBForTest(t, source, target)
+	if err := db.Write(context.Background(), func(queries *datastore.Queries) error {
+		ctx := context.Background()
+		if _, err := queries.UpsertUserPreferences(ctx, datastore.UpsertUserPreferencesParams{
+			Email:          source,
+			Locale:         "en",
+			Timezone:       "Europe/Lisbon",
+			Currency:       "EUR",
+			FirstDayOfWeek: int64(time.Monday),
+			DateFormat:     "YYYY-MM-DD",
+		}); err != nil {
+			return err
+		}
+
+		// A deleted payee keeps the ids of the payees apart between the users.
+		deleted, err := queries.CreatePayee(ctx, datastore.CreatePayeeParams{Email: source, Name: "Old"})
+		if err != nil {
+			return err
+		}
+		if _, err := queries.DeletePayee(ctx, datastore.DeletePayeeParams{ID: deleted.ID, Email: source}); err != nil {
+			return err
+		}
+
+		landlord, err := queries.CreatePayee(ctx, datastore.CreatePayeeParams{Email: source, Name: "Landlord", TaxID: "11222333000181"})
+		if err != nil {
+			return err
+		}
+		for _, a := range []datastore.CreatePayeeAliasParams{
+			{Email: source, PayeeID: landlord.ID, Kind: "description", Value: "RENT"},
+			{Email: source, PayeeID: landlord.ID, Kind: "pix", Value: "landlord@example.com"},
+		} {
+			if _, err := queries.CreatePayeeAlias(ctx, a); err != nil {
+				return err
+			}
+		}
+
+		for _, b := range []datastore.CreateBillParams{
+			{Email: source, Name: "rent", Amount: 150000, Currency: "EUR", DueOn: "2026-03-05", RemindDays: 3, PayeeID: landlord.ID},
+			{Email: source, Name: "power", Amount: 4590, Currency: "EUR", DueOn: "2026-03-10", RemindDays: 1},
+		} {
+			if _, err := queries.CreateBill(ctx, b); err != nil {
+				return err
+			}
+		}
+
+		bills, err := queries.ListBillsByEmail(ctx, source)
+		if err != nil {
+			return err
+		}
+		_, err = queries.SetBillPaid(ctx, datastore.SetBillPaidParams{PaidAt: paidAt.UnixMilli(), ID: bills[0].ID, Email: source})
+		return err
+	}); err != nil {
+		t.Fatalf("failed to create the users: %v", err)
+	}
+
+	return db
+}
+
+func TestServiceExportImport(t *testing.T) {
+	db := newTestDB(t)
+	svc := backup.New(db)
+	ctx := context.Background()
+
+	var buf bytes.Buffer
+	if err := svc.Export(ctx, source, &buf); err != nil {
+		t.Fatalf("Service.Export() error = %v", err)
+	}
+
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("failed to read the backup: %v", err)
+	}
+	var names []string
+	for _, f := range zr.File {
+		names = append(names, f.Name)
+	}
+	if want := []string{backup.BundleFile, "payees.csv", "payee_aliases.csv", "bills.csv"}; len(names) != len(want) {
+		t.Errorf("backup files = %v, want %v", names, want)
+	}
+
+	summary, err := svc.Import(ctx, target, bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("Service.Import() error = %v", err)
+	}
+	if want := (backup.Summary{Payees: 1, Aliases: 2, Bills: 2}); summary != want {
+		t.Errorf("Service.Import() = %+v, want %+v", summary, want)
+	}
+
+	if err := db.Read(ctx, func(queries *datastore.Queries) error {
+		prefs, err := user.GetPreferences(ctx, queries, target)
+		if err != nil {
+			return err
+		}
+		if prefs.Timezone != "Europe/Lisbon" || prefs.Currency != "EUR" || prefs.FirstDayOfWeek != time.Monday {
+			t.Errorf("imported preferences = %+v", prefs)
+		}
+
+		payees, err := queries.ListPayeesByEmail(ctx, target)
+		if err != nil {
+			return err
+		}
+		if len(payees) != 1 || payees[0].Name != "Landlord" || payees[0].TaxID != "11222333000181" {
+			t.Fatalf("imported payees = %+v", payees)
+		}
+
+		aliases, err := queries.ListPayeeAliasesByEmail(ctx, target)
+		if err != nil {
+			return err
+		}
+		for _, a := range aliases {
+			if a.PayeeID != payees[0].ID {
+				t.Errorf("alias %q links the payee %d, want %d", a.Value, a.PayeeID, payees[0].ID)
+			}
+		}
+
+		bills, err := queries.ListBillsByEmail(ctx, target)
+		if err != nil {
+			return err
+		}
+		if len(bills) != 2 {
+			t.Fatalf("imported %d bills, want 2", len(bills))
+		}
+		for _, b := range bills {
+			switch b.Name {
+			case "rent":
+				if b.PayeeID != payees[0].ID || b.Amount != 150000 || b.PaidAt != paidAt.UnixMilli() || b.RemindDays != 3 {
+					t.Errorf("imported rent = %+v", b)
+				}
+			case "power":
+				if b.PayeeID != 0 || b.Amount != 4590 || b.PaidAt != 0 || b.DueOn != "2026-03-10" {
+					t.Errorf("imported power = %+v", b)
+				}
+			}
+		}
+
+		return nil
+	}); err != nil {
+		t.Fatalf("failed to read the imported data: %v", err)
+	}
+
+	// The account is no longer empty.
+	if _, err := svc.Import(ctx, target, bytes.NewReader(buf.Bytes()), int64(buf.Len())); !errors.Is(err, backup.ErrAccountNotEmpty) {
+		t.Errorf("Service.Import() error = %v, want %v", err, backup.ErrAccountNotEmpty)
+	}
+}
+
+// newBackup returns a backup file with the bundle.
+func newBackup(t *testing.T, b backup.Bundle) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	w, err := zw.Create(backup.BundleFile)
+	if err != nil {
+		t.Fatalf("failed to create the bundle: %v", err)
+	}
+	if err := json.NewEncoder(w).Encode(b); err != nil {
+		t.Fatalf("failed to write the bundle: %v", err)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatalf("failed to write the backup: %v", err)
+	}
+
+	return buf.Bytes()
+}
+
+func TestServiceImportInvalid(t *testing.T) {
+	prefs := backup.Preferences{Locale: "en", Timezone: "UTC", Currency: "BRL", DateFormat: "DD/MM/YYYY"}
+	payees := []backup.Payee{{ID: 7, Name: "Landlord"}}
+
+	tests := []struct {
+		name    string
+		email   string
+		data    []byte
+		wantErr error
+	}{
+		{
+			name:    "not a zip file",
+			email:   target,
+			data:    []byte("name,amount\nrent,1500\n"),
+			wantErr: backup.ErrInvalidBackup,
+		},
+		{
+			name:    "other format",
+			email:   target,
+			data:    newBackup(t, backup.Bundle{Format: "other", Version: 1, Preferences: prefs}),
+			wantErr: backup.ErrInvalidBackup,
+		},
+		{
+			name:    "newer version",
+			email:   target,
+			data:    newBackup(t, backup.Bundle{Format: backup.Format, Version: backup.Version + 1, Preferences: prefs}),
+			wantErr: backup.ErrUnsupportedVersion,
+		},
+		{
+			name:  "bill of an unknown payee",
+			email: target,
+			data: newBackup(t, backup.Bundle{Format: backup.Format, Version: 1, Preferences: prefs, Payees: payees, Bills: []backup.Bill{
+				{ID: 1, PayeeID: 8, Name: "rent", Amount: "1500.00", Currency: "BRL", DueOn: "2026-03-05"},
+			}}),
+			wantErr: backup.ErrInvalidBackup,
+		},
+		{
+			name:  "invalid amount",
+			email: target,
+			data: newBackup(t, backup.Bundle{Format: backup.Format, Version: 1, Preferences: prefs, Payees: payees, Bills: []backup.Bill{
+				{ID: 1, PayeeID: 7, Name: "rent", Amount: "1500.001", Currency: "BRL", DueOn: "2026-03-05"},
+			}}),
+			wantErr: backup.ErrInvalidBackup,
+		},
+		{
+			name:    "invalid preferences",
+			email:   target,
+			data:    newBackup(t, backup.Bundle{Format: backup.Format, Version: 1, Preferences: backup.Preferences{Locale: "xx"}}),
+			wantErr: user.ErrInvalidPreferences,
+		},
+		{
+			name:    "unknown user",
+			email:   "nobody@example.com",
+			data:    newBackup(t, backup.Bundle{Format: backup.Format, Version: 1, Preferences: prefs}),
+			wantErr: user.ErrEmailNotFound,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := newTestDB(t)
+			_, err := backup.New(db).Import(context.Background(), tt.email, bytes.NewReader(tt.data), int64(len(tt.data)))
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Service.Import() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			// Nothing is imported from an invalid backup.
+			if err := db.Read(context.Background(), func(queries *datastore.Queries) error {
+				payees, err := queries.ListPayeesByEmail(context.Background(), target)
+				if err == nil && len(payees) > 0 {
+					t.Errorf("imported %d payees from an invalid backup", len(payees))
+				}
+				return err
+			}); err != nil {
+				t.Fatalf("failed to read the payees: %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,395 @@
+package bill
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/garnizeH/dimdim/embeded"
+	"github.com/garnizeH/dimdim/pkg/boleto"
+	"github.com/garnizeH/dimdim/pkg/logger"
+	"github.com/garnizeH/dimdim/pkg/mailer"
+	"github.com/garnizeH/dimdim/pkg/money"
+	"github.com/garnizeH/dimdim/pkg/pix"
+	"github.com/garnizeH/dimdim/service/notification"
+	"github.com/garnizeH/dimdim/service/outbox"
+	"github.com/garnizeH/dimdim/service/payee"
+	"github.com/garnizeH/dimdim/service/user"
+	"github.com/garnizeH/dimdim/storage"
+	"github.com/garnizeH/dimdim/storage/datastore"
+)
+
+const (
+	// DueLayout is the layout of the due dates, stored as plain dates.
+	DueLayout = "2006-01-02"
+
+	MaxRemindDays = 30
+	maxNameLength = 100
+
+	// link is the page shown by the reminders.
+	link = "/bills"
+)
+
+var (
+	ErrBillNotFound = errors.New("bill not found")
+	ErrInvalidBill  = errors.New("invalid bill")
+)
+
+type Bill struct {
+	ID         int64
+	Name       string
+	Amount     money.Money
+	DueOn      time.Time
+	RemindDays int
+	RemindedAt time.Time
+	PaidAt     time.Time
+	// Boleto is the boleto used to pay the bill, if any.
+	Boleto boleto.Boleto
+	// Pix is the Pix code used to pay the bill, if any.
+	Pix     pix.Payment
+	PayeeID int64
+}
+
+func (b Bill) Paid() bool {
+	return !b.PaidAt.IsZero()
+}
+
+// Overdue reports whether the bill is not paid and the due date is before the day of now.
+func (b Bill) Overdue(now time.Time) bool {
+	return !b.Paid() && b.DueOn.Before(startOfDay(now.In(b.DueOn.Location())))
+}
+
+type Service struct {
+	log    *logger.Logger
+	mailer *mailer.Mailer
+	db     *storage.DB[datastore.Queries]
+}
+
+func New(log *logger.Logger, mailer *mailer.Mailer, db *storage.DB[datastore.Queries]) *Service {
+	return &Service{
+		log:    log,
+		mailer: mailer,
+		db:     db,
+	}
+}
+
+func (s *Service) CreateBill(
+	ctx context.Context,
+	email string,
+	name string,
+	amount money.Money,
+	dueOn string,
+	remindDays int,
+	barcode string,
+	pixCode string,
+) (Bill, error) {
+	name = strings.TrimSpace(name)
+	if name == "" || utf8.RuneCountInString(name) > maxNameLength {
+		return Bill{}, ErrInvalidBill
+	}
+	if amount.Amount <= 0 || !slices.Contains(user.Currencies, amount.Currency) {
+		return Bill{}, ErrInvalidBill
+	}
+	if _, err := time.Parse(DueLayout, dueOn); err != nil {
+		return Bill{}, ErrInvalidBill
+	}
+	if remindDays < 0 || remindDays > MaxRemindDays {
+		return Bill{}, ErrInvalidBill
+	}
+	if barcode != "" {
+		b, err := boleto.Parse(barcode)
+		if err != nil {
+			return Bill{}, err
+		}
+		barcode = b.Barcode
+	}
+	var p pix.Payment
+	if pixCode != "" {
+		if barcode != "" {
+			return Bill{}, ErrInvalidBill
+		}
+
+		var err error
+		p, err = pix.Parse(pixCode)
+		if err != nil {
+			return Bill{}, err
+		}
+	}
+
+	var (
+		row   datastore.Bill
+		prefs user.Preferences
+	)
+	if err := s.db.Write(ctx, func(queries *datastore.Queries) error {
+		var (
+			payeeID int64
+			err     error
+		)
+		if p.Key != "" {
+			payeeID, err = payee.Remember(ctx, queries, email, p)
+		} else {
+			payeeID, err = payee.Resolve(ctx, queries, email, name)
+		}
+		if err != nil {
+			return err
+		}
+
+		row, err = queries.CreateBill(ctx, datastore.CreateBillParams{
+			Email:      email,
+			Name:       name,
+			Amount:     amount.Amount,
+			Currency:   amount.Currency,
+			DueOn:      dueOn,
+			RemindDays: int64(remindDays),
+			Barcode:    barcode,
+			Pix:        p.Payload,
+			PayeeID:    payeeID,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to create the bill in the database: %w", err)
+		}
+
+		prefs, err = user.GetPreferences(ctx, queries, email)
+		if err != nil {
+			return fmt.Errorf("failed to get the user preferences from the database: %w", err)
+		}
+
+		return nil
+	}); err != nil {
+		return Bill{}, err
+	}
+
+	return newBill(row, prefs.Location()), nil
+}
+
+// ListBills returns the bills of the user, the ones to pay first, with the due
+// dates in the time zone of the user.
+func (s *Service) ListBills(ctx context.Context, email string) ([]Bill, error) {
+	var (
+		rows  []datastore.Bill
+		prefs user.Preferences
+	)
+	if err := s.db.Read(ctx, func(queries *datastore.Queries) error {
+		var err error
+		rows, err = queries.ListBillsByEmail(ctx, email)
+		if err != nil {
+			return fmt.Errorf("failed to list the bills from the database: %w", err)
+		}
+
+		prefs, err = user.GetPreferences(ctx, queries, email)
+		if err != nil {
+			return fmt.Errorf("failed to get the user preferences from the database: %w", err)
+		}
+
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	loc := prefs.Location()
+	bills := make([]Bill, len(rows))
+	for i, row := range rows {
+		bills[i] = newBill(row, loc)
+	}
+
+	return bills, nil
+}
+
+// MarkPaid records the payment of the bill.
+func (s *Service) MarkPaid(ctx context.Context, email string, id int64) error {
+	return s.db.Write(ctx, func(queries *datastore.Queries) error {
+		n, err := queries.SetBillPaid(ctx, datastore.SetBillPaidParams{
+			PaidAt: time.Now().UTC().UnixMilli(),
+			ID:     id,
+			Email:  email,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to set the bill as paid in the database: %w", err)
+		}
+		if n == 0 {
+			return ErrBillNotFound
+		}
+
+		return nil
+	})
+}
+
+func (s *Service) DeleteBill(ctx context.Context, email string, id int64) error {
+	return s.db.Write(ctx, func(queries *datastore.Queries) error {
+		n, err := queries.DeleteBill(ctx, datastore.DeleteBillParams{
+			ID:    id,
+			Email: email,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to delete the bill in the database: %w", err)
+		}
+		if n == 0 {
+			return ErrBillNotFound
+		}
+
+		return nil
+	})
+}
+
+// SendReminders notifies the users, in the notification center and by email,
+// of the unpaid bills entering the reminder window, in the time zone of each
+// user. The bills already overdue when first seen are skipped without notice.
+func (s *Service) SendReminders(ctx context.Context, baseURL string, now time.Time) error {
+	// the window is as long as the longest reminder, plus a day for the time zones ahead of UTC
+	until := now.UTC().AddDate(0, 0, MaxRemindDays+1).Format(DueLayout)
+
+	var rows []datastore.Bill
+	if err := s.db.Read(ctx, func(queries *datastore.Queries) error {
+		var err error
+		rows, err = queries.ListBillsToRemind(ctx, until)
+		if err != nil {
+			return fmt.Errorf("failed to list the bills to remind from the database: %w", err)
+		}
+
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	// Each bill is reminded in its own transaction, so the other writers are
+	// not held up by a long list of reminders.
+	reminded := 0
+	for _, row := range rows {
+		sent, err := s.remind(ctx, baseURL, row, now)
+		if err != nil {
+			return err
+		}
+		if sent {
+			reminded++
+		}
+	}
+
+	if reminded > 0 {
+		s.log.Info(ctx, "bills", "status", "reminders sent", "count", reminded)
+		s.mailer.Notify()
+	}
+
+	return nil
+}
+
+// remind marks the bill as reminded once its reminder is due, and queues the
+// notification and the email unless the bill is already overdue. It reports
+// whether they were queued.
+func (s *Service) remind(ctx context.Context, baseURL string, row datastore.Bill, now time.Time) (bool, error) {
+	sent := false
+	err := s.db.Write(ctx, func(queries *datastore.Queries) error {
+		// The bill or its user may have changed since the bills were listed.
+		current, err := queries.GetBill(ctx, datastore.GetBillParams{ID: row.ID, Email: row.Email})
+		if err != nil {
+			if storage.NoRows(err) {
+				return nil
+			}
+
+			return fmt.Errorf("failed to get the bill %d from the database: %w", row.ID, err)
+		}
+		if current.PaidAt > 0 || current.RemindedAt > 0 {
+			return nil
+		}
+
+		u, err := queries.GetUser(ctx, row.Email)
+		if err != nil {
+			if storage.NoRows(err) {
+				return nil
+			}
+
+			return fmt.Errorf("failed to get the user of the bill %d from the database: %w", row.ID, err)
+		}
+		if u.DisabledAt > 0 {
+			return nil
+		}
+
+		prefs, err := user.GetPreferences(ctx, queries, row.Email)
+		if err != nil {
+			return fmt.Errorf("failed to get the user preferences from the database: %w", err)
+		}
+
+		b := newBill(current, prefs.Location())
+		send, due := reminderDue(b, now)
+		if !due {
+			return nil
+		}
+
+		if err := queries.SetBillReminded(ctx, datastore.SetBillRemindedParams{
+			RemindedAt: now.UTC().UnixMilli(),
+			ID:         b.ID,
+		}); err != nil {
+			return fmt.Errorf("failed to set the bill %d as reminded in the database: %w", b.ID, err)
+		}
+		if !send {
+			return nil
+		}
+
+		amount := b.Amount.Format(prefs.Locale)
+		dueOn := prefs.FormatDate(b.DueOn)
+		message := embeded.Translate(prefs.Locale, "The bill %s of %s is due on %s.", b.Name, amount, dueOn)
+		if err := notification.Create(ctx, queries, row.Email, message, link); err != nil {
+			return err
+		}
+
+		mail := mailer.NewMailBillReminder(prefs.Locale, baseURL, row.Email, u.Name, b.Name, amount, dueOn)
+		if err := outbox.Queue(ctx, queries, mail); err != nil {
+			return err
+		}
+		sent = true
+
+		return nil
+	})
+
+	return sent, err
+}
+
+// reminderDue reports whether the reminder of the bill is due at now, and
+// whether it must be sent, as bills already overdue are not reminded.
+func reminderDue(b Bill, now time.Time) (send bool, due bool) {
+	today := startOfDay(now.In(b.DueOn.Location()))
+	if today.Before(b.DueOn.AddDate(0, 0, -b.RemindDays)) {
+		return false, false
+	}
+
+	return !today.After(b.DueOn), true
+}
+
+func newBill(row datastore.Bill, loc *time.Location) Bill {
+	dueOn, err := time.ParseInLocation(DueLayout, row.DueOn, loc)
+	if err != nil {
+		dueOn = time.Time{}
+	}
+
+	// The barcode and the Pix code were validated when the bill was created.
+	b, _ := boleto.Parse(row.Barcode)
+	p, _ := pix.Parse(row.Pix)
+
+	return Bill{
+		ID:         row.ID,
+		Name:       row.Name,
+		Amount:     money.New(row.Amount, row.Currency),
+		DueOn:      dueOn,
+		RemindDays: int(row.RemindDays),
+		RemindedAt: timeFromMilli(row.RemindedAt),
+		PaidAt:     timeFromMilli(row.PaidAt),
+		Boleto:     b,
+		Pix:        p,
+		PayeeID:    row.PayeeID,
+	}
+}
+
+func startOfDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
+
+func timeFromMilli(ms int64) time.Time {
+	if ms == 0 {
+		return time.Time{}
+	}
+
+	return time.UnixMilli(ms).UTC()
+}
@@ -0,0 +1,201 @@
+package bill_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/garnizeH/dimdim/pkg/boleto"
+	"github.com/garnizeH/dimdim/pkg/logger"
+	"github.com/garnizeH/dimdim/pkg/mailer"
+	"github.com/garnizeH/dimdim/pkg/money"
+	"github.com/garnizeH/dimdim/pkg/pix"
+	"github.com/garnizeH/dimdim/service/bill"
+	"github.com/garnizeH/dimdim/service/notification"
+	"github.com/garnizeH/dimdim/service/outbox"
+	"github.com/garnizeH/dimdim/storage"
+	"github.com/garnizeH/dimdim/storage/datastore"
+)
+
+const (
+	email = "someone@example.com"
+
+	pixCode        = "00020101021126560014br.gov.bcb.pix0118fulano@example.com0212Aluguel maio52040000530398654071250.005802BR5913Fulano de Tal6009SAO PAULO62130509ALUGUEL0563047D58"
+	dynamicPixCode = "00020101021226800014BR.GOV.BCB.PIX2558pix.example.com/qr/v2/9d36b84f-c70b-478f-b95c-12729b90ca255204000053039865802BR5912Loja Exemplo6014RIO DE JANEIRO62070503***63041229"
+	barcode        = "34191.23454 67890.123457 67890.123457 9 14420000012345"
+)
+
+func newTestService(db *storage.DB[datastore.Queries]) *bill.Service {
+	log := logger.New(io.Discard, logger.LevelError, "test", func(context.Context) string { return "" })
+	return bill.New(log, mailer.New("dimdim@example.com", mailer.NewMemoryTransport()), db)
+}
+
+func TestServiceCreateBill(t *testing.T) {
+	svc := newTestService(datastore.NewDBForTest(t, email))
+
+	tests := []struct {
+		name       string
+		billName   string
+		amount     money.Money
+		dueOn      string
+		remindDays int
+		barcode    string
+		pix        string
+		wantErr    error
+	}{
+		{name: "valid", billName: "Rent", amount: money.New(120000, "BRL"), dueOn: "2026-05-10", remindDays: 3},
+		{name: "empty name", billName: " ", amount: money.New(100, "BRL"), dueOn: "2026-05-10", wantErr: bill.ErrInvalidBill},
+		{name: "zero amount", billName: "Rent", amount: money.New(0, "BRL"), dueOn: "2026-05-10", wantErr: bill.ErrInvalidBill},
+		{name: "unknown currency", billName: "Rent", amount: money.New(100, "XYZ"), dueOn: "2026-05-10", wantErr: bill.ErrInvalidBill},
+		{name: "invalid due date", billName: "Rent", amount: money.New(100, "BRL"), dueOn: "10/05/2026", wantErr: bill.ErrInvalidBill},
+		{name: "boleto", billName: "Rent", amount: money.New(100, "BRL"), dueOn: "2026-05-10", barcode: barcode},
+		{name: "invalid boleto", billName: "Rent", amount: money.New(100, "BRL"), dueOn: "2026-05-10", barcode: "34191.23454", wantErr: boleto.ErrInvalidCode},
+		{name: "pix", billName: "Rent", amount: money.New(100, "BRL"), dueOn: "2026-05-10", pix: pixCode},
+		{name: "dynamic pix", billName: "Rent", amount: money.New(100, "BRL"), dueOn: "2026-05-10", pix: dynamicPixCode},
+		{name: "invalid pix", billName: "Rent", amount: money.New(100, "BRL"), dueOn: "2026-05-10", pix: pixCode[:len(pixCode)-1] + "9", wantErr: pix.ErrInvalidChecksum},
+		{name: "boleto and pix", billName: "Rent", amount: money.New(100, "BRL"), dueOn: "2026-05-10", barcode: barcode, pix: pixCode, wantErr: bill.ErrInvalidBill},
+		{name: "too many remind days", billName: "Rent", amount: money.New(100, "BRL"), dueOn: "2026-05-10", remindDays: 31, wantErr: bill.ErrInvalidBill},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := svc.CreateBill(context.Background(), email, tt.billName, tt.amount, tt.dueOn, tt.remindDays, tt.barcode, tt.pix)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Service.CreateBill() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestServiceCreateBillRemembersPayee(t *testing.T) {
+	ctx := context.Background()
+	svc := newTestService(datastore.NewDBForTest(t, email))
+
+	for _, code := range []string{pixCode, pixCode, dynamicPixCode} {
+		if _, err := svc.CreateBill(ctx, email, "Rent", money.New(125000, "BRL"), "2026-05-10", 3, "", code); err != nil {
+			t.Fatalf("Service.CreateBill() error = %v", err)
+		}
+	}
+
+	bills, err := svc.ListBills(ctx, email)
+	if err != nil {
+		t.Fatalf("Service.ListBills() error = %v", err)
+	}
+	if len(bills) != 3 {
+		t.Fatalf("Service.ListBills() returned %d bills, want 3", len(bills))
+	}
+	if bills[0].PayeeID == 0 || bills[1].PayeeID != bills[0].PayeeID {
+		t.Errorf("payees of the same Pix key = %d and %d, want the same payee", bills[0].PayeeID, bills[1].PayeeID)
+	}
+	if bills[0].Pix.Key != "fulano@example.com" {
+		t.Errorf("Bill.Pix.Key = %q, want %q", bills[0].Pix.Key, "fulano@example.com")
+	}
+	if bills[2].PayeeID != 0 {
+		t.Errorf("payee of the dynamic Pix code = %d, want none", bills[2].PayeeID)
+	}
+}
+
+func TestServiceSendReminders(t *testing.T) {
+	ctx := context.Background()
+	db := datastore.NewDBForTest(t, email, "disabled@example.com", "deleted@example.com")
+	svc := newTestService(db)
+
+	// 23:00 of May 6 in São Paulo, already May 7 in UTC.
+	now := time.Date(2026, time.May, 7, 2, 0, 0, 0, time.UTC)
+
+	bills := []struct {
+		name       string
+		dueOn      string
+		remindDays int
+	}{
+		{name: "window starts tomorrow", dueOn: "2026-05-10", remindDays: 3},
+		{name: "window starts today", dueOn: "2026-05-09", remindDays: 3},
+		{name: "due today", dueOn: "2026-05-06", remindDays: 0},
+		{name: "overdue", dueOn: "2026-05-01", remindDays: 3},
+		{name: "paid", dueOn: "2026-05-08", remindDays: 3},
+	}
+	for _, b := range bills {
+		if _, err := svc.CreateBill(ctx, email, b.name, money.New(1000, "BRL"), b.dueOn, b.remindDays, "", ""); err != nil {
+			t.Fatalf("Service.CreateBill(%q) error = %v", b.name, err)
+		}
+	}
+
+	list, err := svc.ListBills(ctx, email)
+	if err != nil {
+		t.Fatalf("Service.ListBills() error = %v", err)
+	}
+	for _, b := range list {
+		if b.Name == "paid" {
+			if err := svc.MarkPaid(ctx, email, b.ID); err != nil {
+				t.Fatalf("Service.MarkPaid() error = %v", err)
+			}
+			if err := svc.MarkPaid(ctx, email, b.ID); !errors.Is(err, bill.ErrBillNotFound) {
+				t.Errorf("Service.MarkPaid() twice error = %v, want %v", err, bill.ErrBillNotFound)
+			}
+		}
+	}
+
+	// The bills of the disabled and deleted users are not reminded.
+	if err := db.Write(ctx, func(queries *datastore.Queries) error {
+		for _, other := range []string{"disabled@example.com", "deleted@example.com"} {
+			if _, err := queries.CreateBill(ctx, datastore.CreateBillParams{
+				Email:    other,
+				Name:     "due today",
+				Amount:   1000,
+				Currency: "BRL",
+				DueOn:    "2026-05-06",
+			}); err != nil {
+				return err
+			}
+		}
+
+		if _, err := queries.SetUserDisabledAt(ctx, datastore.SetUserDisabledAtParams{
+			DisabledAt: now.UnixMilli(),
+			Email:      "disabled@example.com",
+		}); err != nil {
+			return err
+		}
+		return queries.DeleteUser(ctx, "deleted@example.com")
+	}); err != nil {
+		t.Fatalf("failed to create the bills of the other users: %v", err)
+	}
+
+	// The second run must not remind the same bills again.
+	for range 2 {
+		if err := svc.SendReminders(ctx, "http://localhost:3000", now); err != nil {
+			t.Fatalf("Service.SendReminders() error = %v", err)
+		}
+	}
+
+	notifications, err := notification.New(db).List(ctx, email)
+	if err != nil {
+		t.Fatalf("notification.Service.List() error = %v", err)
+	}
+	var got []string
+	for _, n := range notifications {
+		got = append(got, n.Message)
+	}
+	slices.Sort(got)
+	want := []string{
+		"The bill due today of R$10.00 is due on 2026-05-06.",
+		"The bill window starts today of R$10.00 is due on 2026-05-09.",
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("notifications = %q, want %q", got, want)
+	}
+
+	msgs, err := outbox.New(nil, db).Pending(ctx, time.Now(), 10)
+	if err != nil {
+		t.Fatalf("outbox.Service.Pending() error = %v", err)
+	}
+	if len(msgs) != len(want) {
+		t.Errorf("outbox has %d messages, want %d", len(msgs), len(want))
+	}
+	for _, msg := range msgs {
+		if msg.Template != "bill-reminder" || msg.To != email {
+			t.Errorf("outbox message = %s to %s, want bill-reminder to %s", msg.Template, msg.To, email)
+		}
+	}
+}
@@ -0,0 +1,143 @@
+package bill
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/garnizeH/dimdim/service/user"
+	"github.com/garnizeH/dimdim/storage/datastore"
+)
+
+const (
+	// MaxSavedSearches bounds the saved searches of a user, all of them
+	// loaded on every page for the menu.
+	MaxSavedSearches = 20
+	// MaxSavedSearchName bounds the length of the names of the saved searches.
+	MaxSavedSearchName = 50
+)
+
+var (
+	ErrSavedSearchNotFound  = errors.New("saved search not found")
+	ErrInvalidSavedSearch   = errors.New("invalid saved search")
+	ErrSavedSearchExists    = errors.New("a saved search with this name already exists")
+	ErrTooManySavedSearches = errors.New("too many saved searches")
+)
+
+// SavedSearch is a search of the bills saved with a name, shown in the menu
+// and, when pinned, on the home page.
+type SavedSearch struct {
+	ID     int64
+	Name   string
+	Query  string
+	Pinned bool
+}
+
+// SaveSearch saves the query with the name, after checking that it parses in
+// the currency of the user.
+func (s *Service) SaveSearch(ctx context.Context, email, name, query string, pinned bool) error {
+	name = strings.TrimSpace(name)
+	query = strings.TrimSpace(query)
+	if name == "" || len([]rune(name)) > MaxSavedSearchName || query == "" {
+		return ErrInvalidSavedSearch
+	}
+
+	return s.db.Write(ctx, func(queries *datastore.Queries) error {
+		prefs, err := user.GetPreferences(ctx, queries, email)
+		if err != nil {
+			return fmt.Errorf("failed to get the user preferences from the database: %w", err)
+		}
+		if _, err := ParseQuery(query, prefs.Currency); err != nil {
+			return err
+		}
+
+		count, err := queries.CountSavedSearchesByEmail(ctx, email)
+		if err != nil {
+			return fmt.Errorf("failed to count the saved searches in the database: %w", err)
+		}
+		if count >= MaxSavedSearches {
+			return ErrTooManySavedSearches
+		}
+
+		n, err := queries.CreateSavedSearch(ctx, datastore.CreateSavedSearchParams{
+			Email:  email,
+			Name:   name,
+			Query:  query,
+			Pinned: pinned,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to create the saved search in the database: %w", err)
+		}
+		if n == 0 {
+			return ErrSavedSearchExists
+		}
+
+		return nil
+	})
+}
+
+// ListSavedSearches returns the saved searches of the user by name.
+func (s *Service) ListSavedSearches(ctx context.Context, email string) ([]SavedSearch, error) {
+	var rows []datastore.SavedSearch
+	if err := s.db.Read(ctx, func(queries *datastore.Queries) error {
+		var err error
+		rows, err = queries.ListSavedSearchesByEmail(ctx, email)
+		if err != nil {
+			return fmt.Errorf("failed to list the saved searches from the database: %w", err)
+		}
+
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	searches := make([]SavedSearch, len(rows))
+	for i, row := range rows {
+		searches[i] = SavedSearch{
+			ID:     row.ID,
+			Name:   row.Name,
+			Query:  row.Query,
+			Pinned: row.Pinned,
+		}
+	}
+
+	return searches, nil
+}
+
+// PinSearch pins the saved search to the home page, or unpins it.
+func (s *Service) PinSearch(ctx context.Context, email string, id int64, pinned bool) error {
+	return s.db.Write(ctx, func(queries *datastore.Queries) error {
+		n, err := queries.SetSavedSearchPinned(ctx, datastore.SetSavedSearchPinnedParams{
+			Pinned: pinned,
+			ID:     id,
+			Email:  email,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to pin the saved search in the database: %w", err)
+		}
+		if n == 0 {
+			return ErrSavedSearchNotFound
+		}
+
+		return nil
+	})
+}
+
+// DeleteSavedSearch deletes the saved search, leaving the bills untouched.
+func (s *Service) DeleteSavedSearch(ctx context.Context, email string, id int64) error {
+	return s.db.Write(ctx, func(queries *datastore.Queries) error {
+		n, err := queries.DeleteSavedSearch(ctx, datastore.DeleteSavedSearchParams{
+			ID:    id,
+			Email: email,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to delete the saved search in the database: %w", err)
+		}
+		if n == 0 {
+			return ErrSavedSearchNotFound
+		}
+
+		return nil
+	})
+}
@@ -0,0 +1,236 @@
+package bill
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/garnizeH/dimdim/pkg/money"
+	"github.com/garnizeH/dimdim/service/user"
+	"github.com/garnizeH/dimdim/storage/datastore"
+)
+
+// maxSearchResults bounds the bills returned by a search.
+const maxSearchResults = 200
+
+// The status of the bills searched with status:.
+const (
+	StatusPaid    = "paid"
+	StatusUnpaid  = "unpaid"
+	StatusOverdue = "overdue"
+)
+
+var (
+	ErrInvalidQuery  = errors.New("invalid search")
+	ErrUnknownFilter = errors.New("unknown search filter")
+)
+
+// Query is a search of the bills, parsed by ParseQuery.
+type Query struct {
+	// Text are the words and quoted phrases searched in the names of the bills
+	// and of their payees, as prefixes and regardless of accents.
+	Text []string
+	// Payee are the words searched only in the names of the payees.
+	Payee    []string
+	Currency string
+	// MinAmount and MaxAmount bound the amount, both included, in the smallest
+	// unit of the currency.
+	MinAmount int64
+	MaxAmount int64
+	// After and Before bound the due date, both included, in the DueLayout.
+	After  string
+	Before string
+	Status string
+}
+
+// ParseQuery parses the search typed by the user: words, "quoted phrases" and
+// the filters amount:>100 (or >=, <, <= and =), payee:name, currency:BRL,
+// after:2026-03-01, before:2026-03-31 and status:paid (or unpaid and overdue).
+// The amounts are in the currency of the search, or the given one.
+func ParseQuery(input, currency string) (Query, error) {
+	q := Query{
+		MaxAmount: math.MaxInt64,
+	}
+
+	var amounts []string
+	for _, term := range splitQuery(input) {
+		key, value, ok := strings.Cut(term, ":")
+		if !ok || strings.HasPrefix(term, `"`) {
+			q.Text = append(q.Text, strings.Trim(term, `"`))
+			continue
+		}
+
+		value = strings.Trim(value, `"`)
+		if value == "" {
+			return Query{}, ErrInvalidQuery
+		}
+
+		switch key = strings.ToLower(key); key {
+		case "amount":
+			amounts = append(amounts, value)
+		case "payee":
+			q.Payee = append(q.Payee, value)
+		case "currency":
+			q.Currency = strings.ToUpper(value)
+			if !slices.Contains(user.Currencies, q.Currency) {
+				return Query{}, ErrInvalidQuery
+			}
+		case "after", "before":
+			if _, err := time.Parse(DueLayout, value); err != nil {
+				return Query{}, ErrInvalidQuery
+			}
+			if key == "after" {
+				q.After = value
+			} else {
+				q.Before = value
+			}
+		case "status":
+			q.Status = strings.ToLower(value)
+			if q.Status != StatusPaid && q.Status != StatusUnpaid && q.Status != StatusOverdue {
+				return Query{}, ErrInvalidQuery
+			}
+		default:
+			return Query{}, ErrUnknownFilter
+		}
+	}
+
+	// The amounts are parsed last, in the currency of the search.
+	if q.Currency != "" {
+		currency = q.Currency
+	}
+	for _, value := range amounts {
+		op := strings.TrimRightFunc(value, func(r rune) bool { return !strings.ContainsRune("<>=", r) })
+		amount, err := money.Parse(value[len(op):], currency)
+		if err != nil || amount.Amount < 0 {
+			return Query{}, ErrInvalidQuery
+		}
+
+		n := amount.Amount
+		switch op {
+		case ">":
+			q.MinAmount = max(q.MinAmount, n+1)
+		case ">=":
+			q.MinAmount = max(q.MinAmount, n)
+		case "<":
+			q.MaxAmount = min(q.MaxAmount, n-1)
+		case "<=":
+			q.MaxAmount = min(q.MaxAmount, n)
+		case "", "=":
+			q.MinAmount, q.MaxAmount = max(q.MinAmount, n), min(q.MaxAmount, n)
+		default:
+			return Query{}, ErrInvalidQuery
+		}
+	}
+
+	return q, nil
+}
+
+// splitQuery splits the input on the spaces outside of double quotes.
+func splitQuery(input string) []string {
+	quoted := false
+	return strings.FieldsFunc(input, func(r rune) bool {
+		if r == '"' {
+			quoted = !quoted
+		}
+		return !quoted && unicode.IsSpace(r)
+	})
+}
+
+// match returns the full-text query of the words, all of them required.
+func (q Query) match() string {
+	var terms []string
+	for _, text := range q.Text {
+		terms = appendMatch(terms, "", text)
+	}
+	for _, text := range q.Payee {
+		terms = appendMatch(terms, "payee:", text)
+	}
+
+	return strings.Join(terms, " ")
+}
+
+// appendMatch appends the words of the text as prefixes, in a phrase when
+// there are many. Only the letters and digits are kept, so the text cannot
+// use the operators of the full-text queries, which are also lowercased.
+func appendMatch(terms []string, column, text string) []string {
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	switch {
+	case len(words) == 0:
+		return terms
+	case len(words) == 1 || column != "":
+		for _, w := range words {
+			terms = append(terms, column+w+"*")
+		}
+		return terms
+	default:
+		return append(terms, `"`+strings.Join(words, " ")+`"*`)
+	}
+}
+
+// Search returns the bills of the user matching the query, the latest due
+// first, with the due dates in the time zone of the user.
+func (s *Service) Search(ctx context.Context, email string, q Query) ([]Bill, error) {
+	params := datastore.SearchBillsParams{
+		Email:      email,
+		Match:      q.match(),
+		Currency:   q.Currency,
+		MinAmount:  q.MinAmount,
+		MaxAmount:  q.MaxAmount,
+		DueFrom:    q.After,
+		DueUntil:   q.Before,
+		PaidFrom:   0,
+		PaidUntil:  math.MaxInt64,
+		MaxResults: maxSearchResults,
+	}
+	if params.DueUntil == "" {
+		params.DueUntil = "9999-12-31"
+	}
+	switch q.Status {
+	case StatusPaid:
+		params.PaidFrom = 1
+	case StatusUnpaid, StatusOverdue:
+		params.PaidUntil = 0
+	}
+
+	var (
+		rows  []datastore.Bill
+		prefs user.Preferences
+	)
+	if err := s.db.Read(ctx, func(queries *datastore.Queries) error {
+		var err error
+		prefs, err = user.GetPreferences(ctx, queries, email)
+		if err != nil {
+			return fmt.Errorf("failed to get the user preferences from the database: %w", err)
+		}
+
+		// The overdue bills are due before the day of the user.
+		if q.Status == StatusOverdue {
+			yesterday := time.Now().In(prefs.Location()).AddDate(0, 0, -1).Format(DueLayout)
+			params.DueUntil = min(params.DueUntil, yesterday)
+		}
+
+		rows, err = queries.SearchBills(ctx, params)
+		if err != nil {
+			return fmt.Errorf("failed to search the bills in the database: %w", err)
+		}
+
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	loc := prefs.Location()
+	bills := make([]Bill, len(rows))
+	for i, row := range rows {
+		bills[i] = newBill(row, loc)
+	}
+
+	return bills, nil
+}
@@ -0,0 +1,249 @@
+package bill_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"testing"
+
+	"github.com/garnizeH/dimdim/service/bill"
+	"github.com/garnizeH/dimdim/storage/datastore"
+)
+
+func TestParseQuery(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    bill.Query
+		wantErr error
+	}{
+		{
+			name:  "empty",
+			input: "  ",
+			want:  bill.Query{MaxAmount: math.MaxInt64},
+		},
+		{
+			name:  "words and phrases",
+			input: `rent "light co" água`,
+			want:  bill.Query{Text: []string{"rent", "light co", "água"}, MaxAmount: math.MaxInt64},
+		},
+		{
+			name:  "amount range",
+			input: "amount:>100 amount:<=250.50",
+			want:  bill.Query{MinAmount: 10001, MaxAmount: 25050},
+		},
+		{
+			name:  "exact amount in the currency of the search",
+			input: "amount:100 currency:usd",
+			want:  bill.Query{Currency: "USD", MinAmount: 10000, MaxAmount: 10000},
+		},
+		{
+			name:  "filters",
+			input: `payee:"light co" after:2026-03-01 Before:2026-03-31 status:Overdue`,
+			want:  bill.Query{Payee: []string{"light co"}, MaxAmount: math.MaxInt64, After: "2026-03-01", Before: "2026-03-31", Status: bill.StatusOverdue},
+		},
+		{name: "invalid amount", input: "amount:>abc", wantErr: bill.ErrInvalidQuery},
+		{name: "invalid operator", input: "amount:=>100", wantErr: bill.ErrInvalidQuery},
+		{name: "invalid date", input: "before:31/03/2026", wantErr: bill.ErrInvalidQuery},
+		{name: "invalid status", input: "status:late", wantErr: bill.ErrInvalidQuery},
+		{name: "invalid currency", input: "currency:XYZ", wantErr: bill.ErrInvalidQuery},
+		{name: "empty filter", input: "payee:", wantErr: bill.ErrInvalidQuery},
+		{name: "unknown filter", input: "tag:home", wantErr: bill.ErrUnknownFilter},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := bill.ParseQuery(tt.input, "BRL")
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("ParseQuery() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+
+			if !slices.Equal(got.Text, tt.want.Text) || !slices.Equal(got.Payee, tt.want.Payee) ||
+				got.Currency != tt.want.Currency || got.MinAmount != tt.want.MinAmount || got.MaxAmount != tt.want.MaxAmount ||
+				got.After != tt.want.After || got.Before != tt.want.Before || got.Status != tt.want.Status {
+				t.Errorf("ParseQuery() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestServiceSearch(t *testing.T) {
+	db := datastore.NewDBForTest(t, email)
+	svc := newTestService(db)
+	ctx := context.Background()
+
+	var light datastore.Payee
+	if err := db.Write(ctx, func(queries *datastore.Queries) error {
+		var err error
+		light, err = queries.CreatePayee(ctx, datastore.CreatePayeeParams{Email: email, Name: "Companhia de Energia"})
+		if err != nil {
+			return err
+		}
+
+		for _, b := range []datastore.CreateBillParams{
+			{Email: email, Name: "Conta de luz março", Amount: 18990, Currency: "BRL", DueOn: "2026-03-10", PayeeID: light.ID},
+			{Email: email, Name: "Conta de luz abril", Amount: 21050, Currency: "BRL", DueOn: "2026-04-10", PayeeID: light.ID},
+			{Email: email, Name: "Aluguel", Amount: 150000, Currency: "BRL", DueOn: "2026-04-05"},
+			{Email: email, Name: "Hosting", Amount: 2000, Currency: "USD", DueOn: "2026-04-01"},
+		} {
+			if _, err := queries.CreateBill(ctx, b); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}); err != nil {
+		t.Fatalf("failed to create the bills: %v", err)
+	}
+
+	bills, err := svc.ListBills(ctx, email)
+	if err != nil {
+		t.Fatalf("Service.ListBills() error = %v", err)
+	}
+	ids := make(map[string]int64)
+	for _, b := range bills {
+		ids[b.Name] = b.ID
+	}
+	if err := svc.MarkPaid(ctx, email, ids["Conta de luz março"]); err != nil {
+		t.Fatalf("Service.MarkPaid() error = %v", err)
+	}
+
+	search := func(input string) []string {
+		t.Helper()
+
+		q, err := bill.ParseQuery(input, "BRL")
+		if err != nil {
+			t.Fatalf("ParseQuery(%q) error = %v", input, err)
+		}
+		found, err := svc.Search(ctx, email, q)
+		if err != nil {
+			t.Fatalf("Service.Search(%q) error = %v", input, err)
+		}
+
+		names := make([]string, len(found))
+		for i, b := range found {
+			names[i] = b.Name
+		}
+		return names
+	}
+
+	tests := []struct {
+		input string
+		want  []string
+	}{
+		{input: "", want: []string{"Conta de luz abril", "Aluguel", "Hosting", "Conta de luz março"}},
+		{input: "marco", want: []string{"Conta de luz março"}},
+		{input: "LUZ", want: []string{"Conta de luz abril", "Conta de luz março"}},
+		{input: `"conta de lu"`, want: []string{"Conta de luz abril", "Conta de luz março"}},
+		{input: "energ", want: []string{"Conta de luz abril", "Conta de luz março"}},
+		{input: "payee:energia", want: []string{"Conta de luz abril", "Conta de luz março"}},
+		{input: "payee:aluguel", want: []string{}},
+		{input: "amount:>=200 amount:<1500", want: []string{"Conta de luz abril"}},
+		{input: "currency:USD amount:20", want: []string{"Hosting"}},
+		{input: "after:2026-04-01 before:2026-04-05", want: []string{"Aluguel", "Hosting"}},
+		{input: "status:paid", want: []string{"Conta de luz março"}},
+		{input: "luz status:unpaid", want: []string{"Conta de luz abril"}},
+		{input: "status:overdue after:2026-01-01 before:2026-12-31", want: []string{"Conta de luz abril", "Aluguel", "Hosting"}},
+		{input: `"*" OR NEAR`, want: []string{}},
+	}
+	for _, tt := range tests {
+		if got := search(tt.input); !slices.Equal(got, tt.want) {
+			t.Errorf("Service.Search(%q) = %v, want %v", tt.input, got, tt.want)
+		}
+	}
+
+	// The index follows the renamed payees and the deleted bills.
+	if err := db.Write(ctx, func(queries *datastore.Queries) error {
+		if _, err := queries.UpdatePayee(ctx, datastore.UpdatePayeeParams{Name: "Distribuidora", ID: light.ID, Email: email}); err != nil {
+			return err
+		}
+		return nil
+	}); err != nil {
+		t.Fatalf("failed to rename the payee: %v", err)
+	}
+	if err := svc.DeleteBill(ctx, email, ids["Conta de luz abril"]); err != nil {
+		t.Fatalf("Service.DeleteBill() error = %v", err)
+	}
+
+	if got := search("energia"); len(got) != 0 {
+		t.Errorf("Service.Search(energia) = %v after renaming the payee, want none", got)
+	}
+	if got, want := search("distribuidora"), []string{"Conta de luz março"}; !slices.Equal(got, want) {
+		t.Errorf("Service.Search(distribuidora) = %v, want %v", got, want)
+	}
+}
+
+func TestServiceSavedSearches(t *testing.T) {
+	svc := newTestService(datastore.NewDBForTest(t, email))
+	ctx := context.Background()
+
+	if err := svc.SaveSearch(ctx, email, " Power ", "luz status:unpaid", true); err != nil {
+		t.Fatalf("Service.SaveSearch() error = %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		saveName string
+		query    string
+		wantErr  error
+	}{
+		{name: "same name", saveName: "Power", query: "energia", wantErr: bill.ErrSavedSearchExists},
+		{name: "empty name", saveName: " ", query: "energia", wantErr: bill.ErrInvalidSavedSearch},
+		{name: "empty query", saveName: "Empty", query: " ", wantErr: bill.ErrInvalidSavedSearch},
+		{name: "invalid query", saveName: "Tags", query: "tag:home", wantErr: bill.ErrUnknownFilter},
+		{name: "other name", saveName: "Large", query: "amount:>1000"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := svc.SaveSearch(ctx, email, tt.saveName, tt.query, false); !errors.Is(err, tt.wantErr) {
+				t.Errorf("Service.SaveSearch() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	searches, err := svc.ListSavedSearches(ctx, email)
+	if err != nil {
+		t.Fatalf("Service.ListSavedSearches() error = %v", err)
+	}
+	if len(searches) != 2 {
+		t.Fatalf("Service.ListSavedSearches() = %+v, want 2 searches", searches)
+	}
+	want := []bill.SavedSearch{
+		{ID: searches[0].ID, Name: "Large", Query: "amount:>1000"},
+		{ID: searches[1].ID, Name: "Power", Query: "luz status:unpaid", Pinned: true},
+	}
+	if !slices.Equal(searches, want) {
+		t.Fatalf("Service.ListSavedSearches() = %+v, want %+v", searches, want)
+	}
+
+	if err := svc.PinSearch(ctx, email, searches[1].ID, false); err != nil {
+		t.Errorf("Service.PinSearch() error = %v", err)
+	}
+	if err := svc.DeleteSavedSearch(ctx, email, searches[0].ID); err != nil {
+		t.Errorf("Service.DeleteSavedSearch() error = %v", err)
+	}
+	if err := svc.DeleteSavedSearch(ctx, "other@example.com", searches[1].ID); !errors.Is(err, bill.ErrSavedSearchNotFound) {
+		t.Errorf("Service.DeleteSavedSearch() of another user error = %v, want %v", err, bill.ErrSavedSearchNotFound)
+	}
+
+	searches, err = svc.ListSavedSearches(ctx, email)
+	if err != nil {
+		t.Fatalf("Service.ListSavedSearches() error = %v", err)
+	}
+	if len(searches) != 1 || searches[0].Name != "Power" || searches[0].Pinned {
+		t.Errorf("Service.ListSavedSearches() = %+v, want Power unpinned", searches)
+	}
+
+	for i := len(searches); i < bill.MaxSavedSearches; i++ {
+		if err := svc.SaveSearch(ctx, email, fmt.Sprintf("Search %d", i), "luz", false); err != nil {
+			t.Fatalf("Service.SaveSearch() error = %v", err)
+		}
+	}
+	if err := svc.SaveSearch(ctx, email, "One more", "luz", false); !errors.Is(err, bill.ErrTooManySavedSearches) {
+		t.Errorf("Service.SaveSearch() error = %v, want %v", err, bill.ErrTooManySavedSearches)
+	}
+}
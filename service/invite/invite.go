@@ -0,0 +1,100 @@
+package invite
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/garnizeH/dimdim/storage"
+	"github.com/garnizeH/dimdim/storage/datastore"
+	"github.com/google/uuid"
+)
+
+const tokenInvite = "INVITE"
+
+var ErrInvalidLifetime = errors.New("invalid invite lifetime")
+
+type Service struct {
+	db *storage.DB[datastore.Queries]
+}
+
+func New(db *storage.DB[datastore.Queries]) *Service {
+	return &Service{
+		db: db,
+	}
+}
+
+// Invite is a single-use code that allows signing up when the registration is invite-only.
+// An invite bound to an email can only be used to sign up with that email.
+type Invite struct {
+	Code      string
+	Email     string
+	ExpiresAt time.Time
+}
+
+// URL returns the signup link with the invite code filled in.
+func (i Invite) URL(baseURL string) string {
+	return baseURL + "/auth/signup?invite=" + url.QueryEscape(i.Code)
+}
+
+func (s *Service) CreateInvite(
+	ctx context.Context,
+	email string,
+	lifetime time.Duration,
+) (Invite, error) {
+	if lifetime <= 0 {
+		return Invite{}, ErrInvalidLifetime
+	}
+
+	invite := Invite{
+		Code:      uuid.New().String(),
+		Email:     email,
+		ExpiresAt: time.Now().Add(lifetime).UTC(),
+	}
+	if err := s.db.Write(ctx, func(queries *datastore.Queries) error {
+		if err := queries.CreateToken(ctx, datastore.CreateTokenParams{
+			Token:     invite.Code,
+			Type:      tokenInvite,
+			Email:     invite.Email,
+			ExpiresAt: invite.ExpiresAt.UnixMilli(),
+		}); err != nil {
+			return fmt.Errorf("failed to create the invite token in the database: %w", err)
+		}
+
+		return nil
+	}); err != nil {
+		return Invite{}, err
+	}
+
+	return invite, nil
+}
+
+func (s *Service) ListInvites(ctx context.Context) ([]Invite, error) {
+	var tokens []datastore.Token
+	if err := s.db.Read(ctx, func(queries *datastore.Queries) error {
+		var err error
+		tokens, err = queries.ListInviteTokensNotExpired(ctx, time.Now().UTC().UnixMilli())
+		return err
+	}); err != nil {
+		return nil, err
+	}
+
+	invites := make([]Invite, len(tokens))
+	for i, t := range tokens {
+		invites[i] = Invite{
+			Code:      t.Token,
+			Email:     t.Email,
+			ExpiresAt: time.UnixMilli(t.ExpiresAt).UTC(),
+		}
+	}
+
+	return invites, nil
+}
+
+func (s *Service) RevokeInvite(ctx context.Context, code string) error {
+	return s.db.Write(ctx, func(queries *datastore.Queries) error {
+		return queries.DeleteInviteToken(ctx, code)
+	})
+}
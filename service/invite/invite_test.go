@@ -0,0 +1,55 @@
+package invite_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/garnizeH/dimdim/service/invite"
+	"github.com/garnizeH/dimdim/storage"
+	"github.com/garnizeH/dimdim/storage/datastore"
+)
+
+func TestServiceInvites(t *testing.T) {
+	svc := invite.New(storage.NewDBForTest(t, datastore.Migrations, datastore.Factory))
+	ctx := context.Background()
+
+	if _, err := svc.CreateInvite(ctx, "", 0); !errors.Is(err, invite.ErrInvalidLifetime) {
+		t.Errorf("Service.CreateInvite() without a lifetime error = %v, want %v", err, invite.ErrInvalidLifetime)
+	}
+
+	open, err := svc.CreateInvite(ctx, "", time.Hour)
+	if err != nil {
+		t.Fatalf("Service.CreateInvite() error = %v", err)
+	}
+	bound, err := svc.CreateInvite(ctx, "someone@example.com", 2*time.Hour)
+	if err != nil {
+		t.Fatalf("Service.CreateInvite() error = %v", err)
+	}
+	if open.Code == "" || open.Code == bound.Code {
+		t.Fatalf("Service.CreateInvite() codes = %q and %q, want distinct codes", open.Code, bound.Code)
+	}
+	if got, want := open.URL("http://localhost:3000"), "http://localhost:3000/auth/signup?invite="+open.Code; got != want {
+		t.Errorf("Invite.URL() = %q, want %q", got, want)
+	}
+
+	invites, err := svc.ListInvites(ctx)
+	if err != nil {
+		t.Fatalf("Service.ListInvites() error = %v", err)
+	}
+	if len(invites) != 2 || invites[0].Code != open.Code || invites[1].Email != "someone@example.com" {
+		t.Fatalf("Service.ListInvites() = %+v, want the open and the bound invites", invites)
+	}
+
+	if err := svc.RevokeInvite(ctx, open.Code); err != nil {
+		t.Fatalf("Service.RevokeInvite() error = %v", err)
+	}
+	invites, err = svc.ListInvites(ctx)
+	if err != nil {
+		t.Fatalf("Service.ListInvites() error = %v", err)
+	}
+	if len(invites) != 1 || invites[0].Code != bound.Code {
+		t.Errorf("Service.ListInvites() after the revocation = %+v, want the bound invite", invites)
+	}
+}
@@ -0,0 +1,96 @@
+package notification
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/garnizeH/dimdim/storage"
+	"github.com/garnizeH/dimdim/storage/datastore"
+)
+
+// listLimit is the number of notifications shown in the notification center.
+const listLimit = 50
+
+// Create stores the notification using the queries of the caller transaction,
+// so the user is only notified if the transaction commits.
+func Create(ctx context.Context, queries *datastore.Queries, email, message, link string) error {
+	if err := queries.CreateNotification(ctx, datastore.CreateNotificationParams{
+		Email:   email,
+		Message: message,
+		Link:    link,
+	}); err != nil {
+		return fmt.Errorf("failed to create the notification in the database: %w", err)
+	}
+
+	return nil
+}
+
+type Notification struct {
+	ID        int64
+	Message   string
+	Link      string
+	CreatedAt time.Time
+	Read      bool
+}
+
+// Service is the in-app notification center of the users.
+type Service struct {
+	db *storage.DB[datastore.Queries]
+}
+
+func New(db *storage.DB[datastore.Queries]) *Service {
+	return &Service{
+		db: db,
+	}
+}
+
+// List returns the most recent notifications of the user.
+func (s *Service) List(ctx context.Context, email string) ([]Notification, error) {
+	var rows []datastore.Notification
+	if err := s.db.Read(ctx, func(queries *datastore.Queries) error {
+		var err error
+		rows, err = queries.ListNotifications(ctx, datastore.ListNotificationsParams{
+			Email: email,
+			Limit: listLimit,
+		})
+		return err
+	}); err != nil {
+		return nil, fmt.Errorf("failed to list the notifications from the database: %w", err)
+	}
+
+	notifications := make([]Notification, len(rows))
+	for i, row := range rows {
+		notifications[i] = Notification{
+			ID:        row.ID,
+			Message:   row.Message,
+			Link:      row.Link,
+			CreatedAt: time.UnixMilli(row.CreatedAt).UTC(),
+			Read:      row.ReadAt > 0,
+		}
+	}
+
+	return notifications, nil
+}
+
+func (s *Service) CountUnread(ctx context.Context, email string) (int64, error) {
+	var count int64
+	if err := s.db.Read(ctx, func(queries *datastore.Queries) error {
+		var err error
+		count, err = queries.CountUnreadNotifications(ctx, email)
+		return err
+	}); err != nil {
+		return 0, fmt.Errorf("failed to count the unread notifications in the database: %w", err)
+	}
+
+	return count, nil
+}
+
+func (s *Service) MarkAllRead(ctx context.Context, email string) error {
+	return s.db.Write(ctx, func(queries *datastore.Queries) error {
+		return queries.MarkNotificationsRead(ctx, datastore.MarkNotificationsReadParams{
+			ReadAt: time.Now().UTC().UnixMilli(),
+			Email:  email,
+		})
+	})
+}
@@ -0,0 +1,218 @@
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/garnizeH/dimdim/pkg/mailer"
+	"github.com/garnizeH/dimdim/storage"
+	"github.com/garnizeH/dimdim/storage/datastore"
+)
+
+var ErrMessageNotFound = errors.New("message not found or already sent")
+
+// Queue stores the message in the outbox using the queries of the caller
+// transaction, so the message is only delivered if the transaction commits.
+func Queue(ctx context.Context, queries *datastore.Queries, msg mailer.Message) error {
+	data, err := json.Marshal(msg.Data)
+	if err != nil {
+		return fmt.Errorf("failed to encode the %s message data: %w", msg.Template, err)
+	}
+
+	if err := queries.CreateOutboxMessage(ctx, datastore.CreateOutboxMessageParams{
+		Template:      msg.Template,
+		Subject:       msg.Subject,
+		Recipient:     msg.To,
+		Data:          string(data),
+		NextAttemptAt: time.Now().UTC().UnixMilli(),
+	}); err != nil {
+		return fmt.Errorf("failed to queue the %s message in the database: %w", msg.Template, err)
+	}
+
+	return nil
+}
+
+// Service is the outbox storage used by the mailer dispatcher and the admin console.
+type Service struct {
+	mailer *mailer.Mailer
+	db     *storage.DB[datastore.Queries]
+}
+
+func New(mailer *mailer.Mailer, db *storage.DB[datastore.Queries]) *Service {
+	return &Service{
+		mailer: mailer,
+		db:     db,
+	}
+}
+
+func (s *Service) Pending(ctx context.Context, now time.Time, limit int) ([]mailer.Message, error) {
+	var rows []datastore.Outbox
+	if err := s.db.Read(ctx, func(queries *datastore.Queries) error {
+		var err error
+		rows, err = queries.ListPendingOutboxMessages(ctx, datastore.ListPendingOutboxMessagesParams{
+			NextAttemptAt: now.UTC().UnixMilli(),
+			Limit:         int64(limit),
+		})
+		return err
+	}); err != nil {
+		return nil, err
+	}
+
+	msgs := make([]mailer.Message, 0, len(rows))
+	for _, row := range rows {
+		var data map[string]string
+		if err := json.Unmarshal([]byte(row.Data), &data); err != nil {
+			// A message that cannot be decoded is given up, so it does not block
+			// the ones queued after it.
+			lastErr := fmt.Sprintf("failed to decode the message data: %v", err)
+			if err := s.MarkFailed(ctx, row.ID, row.Attempts+1, lastErr, now, true); err != nil {
+				return nil, err
+			}
+
+			continue
+		}
+
+		msgs = append(msgs, mailer.Message{
+			ID:       row.ID,
+			Template: row.Template,
+			Subject:  row.Subject,
+			To:       row.Recipient,
+			Data:     data,
+			Attempts: row.Attempts,
+		})
+	}
+
+	return msgs, nil
+}
+
+func (s *Service) MarkSent(ctx context.Context, id int64, sentAt time.Time) error {
+	return s.db.Write(ctx, func(queries *datastore.Queries) error {
+		return queries.SetOutboxMessageSent(ctx, datastore.SetOutboxMessageSentParams{
+			SentAt: sentAt.UTC().UnixMilli(),
+			ID:     id,
+		})
+	})
+}
+
+func (s *Service) MarkFailed(
+	ctx context.Context,
+	id int64,
+	attempts int64,
+	lastErr string,
+	nextAttemptAt time.Time,
+	final bool,
+) error {
+	var failedAt int64
+	if final {
+		failedAt = time.Now().UTC().UnixMilli()
+	}
+
+	return s.db.Write(ctx, func(queries *datastore.Queries) error {
+		return queries.SetOutboxMessageAttempt(ctx, datastore.SetOutboxMessageAttemptParams{
+			Attempts:      attempts,
+			LastError:     lastErr,
+			NextAttemptAt: nextAttemptAt.UTC().UnixMilli(),
+			FailedAt:      failedAt,
+			ID:            id,
+		})
+	})
+}
+
+// Entry is the view of an outbox message shown to the administrators.
+type Entry struct {
+	ID            int64
+	Template      string
+	Subject       string
+	Recipient     string
+	Attempts      int64
+	LastError     string
+	CreatedAt     time.Time
+	NextAttemptAt time.Time
+	SentAt        time.Time
+	FailedAt      time.Time
+}
+
+func (e Entry) Status() string {
+	switch {
+	case !e.SentAt.IsZero():
+		return "sent"
+	case !e.FailedAt.IsZero():
+		return "failed"
+	case e.Attempts > 0:
+		return "retrying"
+	default:
+		return "pending"
+	}
+}
+
+// List returns the most recent messages of the outbox.
+func (s *Service) List(ctx context.Context, limit int) ([]Entry, error) {
+	var rows []datastore.Outbox
+	if err := s.db.Read(ctx, func(queries *datastore.Queries) error {
+		var err error
+		rows, err = queries.ListOutboxMessages(ctx, int64(limit))
+		return err
+	}); err != nil {
+		return nil, err
+	}
+
+	entries := make([]Entry, len(rows))
+	for i, row := range rows {
+		entries[i] = Entry{
+			ID:            row.ID,
+			Template:      row.Template,
+			Subject:       row.Subject,
+			Recipient:     row.Recipient,
+			Attempts:      row.Attempts,
+			LastError:     row.LastError,
+			CreatedAt:     timeFromMilli(row.CreatedAt),
+			NextAttemptAt: timeFromMilli(row.NextAttemptAt),
+			SentAt:        timeFromMilli(row.SentAt),
+			FailedAt:      timeFromMilli(row.FailedAt),
+		}
+	}
+
+	return entries, nil
+}
+
+// Retry schedules a message not yet sent to be delivered right away with a fresh attempts count.
+func (s *Service) Retry(ctx context.Context, id int64) error {
+	if err := s.db.Write(ctx, func(queries *datastore.Queries) error {
+		n, err := queries.RetryOutboxMessage(ctx, datastore.RetryOutboxMessageParams{
+			NextAttemptAt: time.Now().UTC().UnixMilli(),
+			ID:            id,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to retry the message in the database: %w", err)
+		}
+		if n == 0 {
+			return ErrMessageNotFound
+		}
+
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	s.mailer.Notify()
+
+	return nil
+}
+
+// Purge removes the messages sent or given up before the given time.
+func (s *Service) Purge(ctx context.Context, before time.Time) error {
+	return s.db.Write(ctx, func(queries *datastore.Queries) error {
+		return queries.PurgeOutboxMessages(ctx, before.UTC().UnixMilli())
+	})
+}
+
+func timeFromMilli(ms int64) time.Time {
+	if ms == 0 {
+		return time.Time{}
+	}
+
+	return time.UnixMilli(ms).UTC()
+}
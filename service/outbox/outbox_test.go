@@ -0,0 +1,117 @@
+package outbox_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/garnizeH/dimdim/pkg/mailer"
+	"github.com/garnizeH/dimdim/service/outbox"
+	"github.com/garnizeH/dimdim/storage"
+	"github.com/garnizeH/dimdim/storage/datastore"
+)
+
+const email = "someone@example.com"
+
+func queue(t *testing.T, db *storage.DB[datastore.Queries], msgs ...mailer.Message) {
+	t.Helper()
+
+	if err := db.Write(context.Background(), func(queries *datastore.Queries) error {
+		for _, msg := range msgs {
+			if err := outbox.Queue(context.Background(), queries, msg); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}); err != nil {
+		t.Fatalf("outbox.Queue() error = %v", err)
+	}
+}
+
+func statuses(t *testing.T, svc *outbox.Service) map[string]string {
+	t.Helper()
+
+	entries, err := svc.List(context.Background(), 10)
+	if err != nil {
+		t.Fatalf("Service.List() error = %v", err)
+	}
+
+	got := map[string]string{}
+	for _, e := range entries {
+		got[e.Template] = e.Status()
+	}
+
+	return got
+}
+
+func TestServicePending(t *testing.T) {
+	db := storage.NewDBForTest(t, datastore.Migrations, datastore.Factory)
+	m := mailer.New("dimdim@example.com", mailer.NewMemoryTransport())
+	svc := outbox.New(m, db)
+	ctx := context.Background()
+
+	queue(t, db,
+		mailer.NewMailSignup("en", "http://localhost", email, "Someone", "token"),
+		mailer.NewMailPassword("en", "http://localhost", email, "Someone", "token"),
+	)
+	now := time.Now()
+	// A message whose data cannot be decoded does not block the others.
+	if err := db.Write(ctx, func(queries *datastore.Queries) error {
+		return queries.CreateOutboxMessage(ctx, datastore.CreateOutboxMessageParams{
+			Template:      "broken",
+			Subject:       "Broken",
+			Recipient:     email,
+			Data:          "{",
+			NextAttemptAt: now.UnixMilli(),
+		})
+	}); err != nil {
+		t.Fatalf("failed to queue the broken message: %v", err)
+	}
+
+	msgs, err := svc.Pending(ctx, now, 10)
+	if err != nil {
+		t.Fatalf("Service.Pending() error = %v", err)
+	}
+	if len(msgs) != 2 || msgs[0].Template != "signup" || msgs[0].To != email || msgs[0].Data["Name"] != "Someone" {
+		t.Fatalf("Service.Pending() = %+v", msgs)
+	}
+	if got := statuses(t, svc)["broken"]; got != "failed" {
+		t.Errorf("the broken message status = %q, want failed", got)
+	}
+
+	if err := svc.MarkSent(ctx, msgs[0].ID, now); err != nil {
+		t.Fatalf("Service.MarkSent() error = %v", err)
+	}
+	next := now.Add(time.Minute)
+	if err := svc.MarkFailed(ctx, msgs[1].ID, 1, "connection refused", next, false); err != nil {
+		t.Fatalf("Service.MarkFailed() error = %v", err)
+	}
+
+	if msgs, err := svc.Pending(ctx, now, 10); err != nil || len(msgs) != 0 {
+		t.Errorf("Service.Pending() before the next attempt = %+v, %v, want none", msgs, err)
+	}
+	msgs, err = svc.Pending(ctx, next, 10)
+	if err != nil || len(msgs) != 1 || msgs[0].Attempts != 1 {
+		t.Fatalf("Service.Pending() at the next attempt = %+v, %v", msgs, err)
+	}
+
+	got := statuses(t, svc)
+	if got["signup"] != "sent" || got["password"] != "retrying" {
+		t.Errorf("statuses = %v, want signup sent and password retrying", got)
+	}
+
+	if err := svc.Retry(ctx, msgs[0].ID); err != nil {
+		t.Fatalf("Service.Retry() error = %v", err)
+	}
+	if msgs, err := svc.Pending(ctx, time.Now(), 10); err != nil || len(msgs) != 1 || msgs[0].Attempts != 0 {
+		t.Errorf("Service.Pending() after the retry = %+v, %v", msgs, err)
+	}
+
+	if err := svc.Purge(ctx, now.Add(time.Hour)); err != nil {
+		t.Fatalf("Service.Purge() error = %v", err)
+	}
+	if got := statuses(t, svc); len(got) != 1 || got["password"] != "pending" {
+		t.Errorf("statuses after the purge = %v, want only the password message", got)
+	}
+}
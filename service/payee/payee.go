@@ -0,0 +1,485 @@
+package payee
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/garnizeH/dimdim/pkg/pix"
+	"github.com/garnizeH/dimdim/pkg/taxid"
+	"github.com/garnizeH/dimdim/storage"
+	"github.com/garnizeH/dimdim/storage/datastore"
+	"golang.org/x/text/runes"
+	"golang.org/x/text/transform"
+	"golang.org/x/text/unicode/norm"
+)
+
+const (
+	// KindDescription is the kind of the alias of the normalized descriptions of the payee.
+	KindDescription = "description"
+	// KindPix is the kind of the alias of the Pix keys of the payee.
+	KindPix = "pix"
+
+	maxNameLength = 100
+	// maxPrefixLength is the longest prefix before an asterisk treated as the
+	// code of a payment processor, e.g. PAG* or IFD*.
+	maxPrefixLength = 10
+)
+
+// AliasKinds are the kinds of the aliases of the payees.
+var AliasKinds = []string{KindDescription, KindPix}
+
+var (
+	ErrPayeeNotFound = errors.New("payee not found")
+	ErrInvalidPayee  = errors.New("invalid payee")
+	ErrInvalidAlias  = errors.New("invalid alias")
+	ErrAliasInUse    = errors.New("alias already in use")
+	ErrMergeSelf     = errors.New("cannot merge a payee into itself")
+)
+
+// Normalize reduces a description to the form matched against the aliases of
+// the payees, in upper case, without accents, punctuation or the prefix of the
+// payment processor, so "PAG*Ifood São Paulo" becomes "IFOOD SAO PAULO".
+func Normalize(description string) string {
+	s, _, err := transform.String(transform.Chain(norm.NFD, runes.Remove(runes.In(unicode.Mn)), norm.NFC), description)
+	if err != nil {
+		s = description
+	}
+	s = strings.ToUpper(s)
+
+	if prefix, rest, ok := strings.Cut(s, "*"); ok && utf8.RuneCountInString(strings.TrimSpace(prefix)) <= maxPrefixLength {
+		s = rest
+	}
+
+	return strings.Join(strings.FieldsFunc(s, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}), " ")
+}
+
+// Resolve returns the payee whose alias is the longest match of the start of
+// the normalized description, or zero when none matches. It uses the queries
+// of the caller transaction.
+func Resolve(ctx context.Context, queries *datastore.Queries, email, description string) (int64, error) {
+	aliases, err := queries.ListPayeeAliasesByEmail(ctx, email)
+	if err != nil {
+		return 0, fmt.Errorf("failed to list the payee aliases from the database: %w", err)
+	}
+
+	return resolve(aliases, Normalize(description)), nil
+}
+
+func resolve(aliases []datastore.PayeeAlias, normalized string) int64 {
+	var (
+		payeeID int64
+		longest int
+	)
+	for _, a := range aliases {
+		if a.Kind != KindDescription || len(a.Value) <= longest {
+			continue
+		}
+		if normalized == a.Value || strings.HasPrefix(normalized, a.Value+" ") {
+			payeeID, longest = a.PayeeID, len(a.Value)
+		}
+	}
+
+	return payeeID
+}
+
+// Remember returns the payee of the Pix key of the payment, creating it with
+// the name and the city of the receiver on the first payment to the key. It
+// uses the queries of the caller transaction, so the payee is only created
+// with the record paid to it.
+func Remember(ctx context.Context, queries *datastore.Queries, email string, p pix.Payment) (int64, error) {
+	alias, err := queries.GetPayeeAlias(ctx, datastore.GetPayeeAliasParams{
+		Email: email,
+		Kind:  KindPix,
+		Value: p.Key,
+	})
+	if err == nil {
+		return alias.PayeeID, nil
+	}
+	if !storage.NoRows(err) {
+		return 0, fmt.Errorf("failed to get the payee alias from the database: %w", err)
+	}
+
+	// The keys of the people and companies are their CPF or CNPJ.
+	var taxID string
+	if id, err := taxid.Parse(p.Key); err == nil {
+		taxID = id.Number
+	}
+
+	payee, err := queries.CreatePayee(ctx, datastore.CreatePayeeParams{
+		Email: email,
+		Name:  p.MerchantName,
+		City:  p.MerchantCity,
+		TaxID: taxID,
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to create the payee in the database: %w", err)
+	}
+
+	if _, err := createAlias(ctx, queries, email, payee.ID, KindPix, p.Key); err != nil {
+		return 0, err
+	}
+	// The name is remembered when not already an alias of another payee.
+	if _, err := createAlias(ctx, queries, email, payee.ID, KindDescription, Normalize(p.MerchantName)); err != nil {
+		return 0, err
+	}
+
+	return payee.ID, nil
+}
+
+// createAlias reports whether the alias was created, as it is not when the
+// value is already an alias of a payee.
+func createAlias(ctx context.Context, queries *datastore.Queries, email string, payeeID int64, kind, value string) (bool, error) {
+	if value == "" {
+		return false, nil
+	}
+
+	n, err := queries.CreatePayeeAlias(ctx, datastore.CreatePayeeAliasParams{
+		Email:   email,
+		PayeeID: payeeID,
+		Kind:    kind,
+		Value:   value,
+	})
+	if err != nil {
+		return false, fmt.Errorf("failed to create the payee alias in the database: %w", err)
+	}
+
+	return n > 0, nil
+}
+
+// assignBills links the bills without a payee to the payees matching their names.
+func assignBills(ctx context.Context, queries *datastore.Queries, email string) error {
+	bills, err := queries.ListBillsWithoutPayee(ctx, email)
+	if err != nil {
+		return fmt.Errorf("failed to list the bills without payee from the database: %w", err)
+	}
+	if len(bills) == 0 {
+		return nil
+	}
+
+	aliases, err := queries.ListPayeeAliasesByEmail(ctx, email)
+	if err != nil {
+		return fmt.Errorf("failed to list the payee aliases from the database: %w", err)
+	}
+
+	for _, b := range bills {
+		payeeID := resolve(aliases, Normalize(b.Name))
+		if payeeID == 0 {
+			continue
+		}
+
+		if err := queries.SetBillPayee(ctx, datastore.SetBillPayeeParams{
+			PayeeID: payeeID,
+			ID:      b.ID,
+		}); err != nil {
+			return fmt.Errorf("failed to set the payee of the bill %d in the database: %w", b.ID, err)
+		}
+	}
+
+	return nil
+}
+
+type Alias struct {
+	ID    int64
+	Value string
+}
+
+type Payee struct {
+	ID    int64
+	Name  string
+	City  string
+	TaxID taxid.TaxID
+	// Aliases are the normalized descriptions resolved to the payee.
+	Aliases []Alias
+	PixKeys []Alias
+}
+
+// Service is the registry of the payees of the users, the people and
+// companies they pay.
+type Service struct {
+	db *storage.DB[datastore.Queries]
+}
+
+func New(db *storage.DB[datastore.Queries]) *Service {
+	return &Service{
+		db: db,
+	}
+}
+
+// List returns the payees of the user with their aliases and Pix keys.
+func (s *Service) List(ctx context.Context, email string) ([]Payee, error) {
+	var (
+		rows    []datastore.Payee
+		aliases []datastore.PayeeAlias
+	)
+	if err := s.db.Read(ctx, func(queries *datastore.Queries) error {
+		var err error
+		rows, err = queries.ListPayeesByEmail(ctx, email)
+		if err != nil {
+			return fmt.Errorf("failed to list the payees from the database: %w", err)
+		}
+
+		aliases, err = queries.ListPayeeAliasesByEmail(ctx, email)
+		if err != nil {
+			return fmt.Errorf("failed to list the payee aliases from the database: %w", err)
+		}
+
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	payees := make([]Payee, len(rows))
+	index := make(map[int64]*Payee, len(rows))
+	for i, row := range rows {
+		// The tax id was validated when stored.
+		id, _ := taxid.Parse(row.TaxID)
+		payees[i] = Payee{
+			ID:    row.ID,
+			Name:  row.Name,
+			City:  row.City,
+			TaxID: id,
+		}
+		index[row.ID] = &payees[i]
+	}
+	for _, a := range aliases {
+		p, ok := index[a.PayeeID]
+		if !ok {
+			continue
+		}
+
+		alias := Alias{ID: a.ID, Value: a.Value}
+		switch a.Kind {
+		case KindDescription:
+			p.Aliases = append(p.Aliases, alias)
+		case KindPix:
+			p.PixKeys = append(p.PixKeys, alias)
+		}
+	}
+
+	return payees, nil
+}
+
+// Create creates the payee with its name as the first alias, and links to it
+// the bills without payee matching the name.
+func (s *Service) Create(ctx context.Context, email, name, taxID string) error {
+	name, taxID, err := Validate(name, taxID)
+	if err != nil {
+		return err
+	}
+
+	return s.db.Write(ctx, func(queries *datastore.Queries) error {
+		payee, err := queries.CreatePayee(ctx, datastore.CreatePayeeParams{
+			Email: email,
+			Name:  name,
+			TaxID: taxID,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to create the payee in the database: %w", err)
+		}
+
+		if _, err := createAlias(ctx, queries, email, payee.ID, KindDescription, Normalize(name)); err != nil {
+			return err
+		}
+
+		return assignBills(ctx, queries, email)
+	})
+}
+
+func (s *Service) Update(ctx context.Context, email string, id int64, name, taxID string) error {
+	name, taxID, err := Validate(name, taxID)
+	if err != nil {
+		return err
+	}
+
+	return s.db.Write(ctx, func(queries *datastore.Queries) error {
+		n, err := queries.UpdatePayee(ctx, datastore.UpdatePayeeParams{
+			Name:  name,
+			TaxID: taxID,
+			ID:    id,
+			Email: email,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to update the payee in the database: %w", err)
+		}
+		if n == 0 {
+			return ErrPayeeNotFound
+		}
+
+		return nil
+	})
+}
+
+// Delete deletes the payee and its aliases, unlinking its bills.
+func (s *Service) Delete(ctx context.Context, email string, id int64) error {
+	return s.db.Write(ctx, func(queries *datastore.Queries) error {
+		n, err := queries.DeletePayee(ctx, datastore.DeletePayeeParams{
+			ID:    id,
+			Email: email,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to delete the payee in the database: %w", err)
+		}
+		if n == 0 {
+			return ErrPayeeNotFound
+		}
+
+		if err := queries.DeletePayeeAliases(ctx, datastore.DeletePayeeAliasesParams{
+			PayeeID: id,
+			Email:   email,
+		}); err != nil {
+			return fmt.Errorf("failed to delete the payee aliases in the database: %w", err)
+		}
+
+		if err := queries.MoveBillsPayee(ctx, datastore.MoveBillsPayeeParams{
+			NewPayeeID: 0,
+			Email:      email,
+			OldPayeeID: id,
+		}); err != nil {
+			return fmt.Errorf("failed to unlink the bills of the payee in the database: %w", err)
+		}
+
+		return nil
+	})
+}
+
+// AddAlias adds the normalized description as an alias of the payee, and links
+// to it the bills without payee matching the alias.
+func (s *Service) AddAlias(ctx context.Context, email string, id int64, description string) error {
+	alias := Normalize(description)
+	if alias == "" || utf8.RuneCountInString(alias) > maxNameLength {
+		return ErrInvalidAlias
+	}
+
+	return s.db.Write(ctx, func(queries *datastore.Queries) error {
+		if _, err := getPayee(ctx, queries, email, id); err != nil {
+			return err
+		}
+
+		created, err := createAlias(ctx, queries, email, id, KindDescription, alias)
+		if err != nil {
+			return err
+		}
+		if !created {
+			return ErrAliasInUse
+		}
+
+		return assignBills(ctx, queries, email)
+	})
+}
+
+// RemoveAlias removes the alias or the Pix key from its payee, keeping the
+// bills already linked to the payee.
+func (s *Service) RemoveAlias(ctx context.Context, email string, aliasID int64) error {
+	return s.db.Write(ctx, func(queries *datastore.Queries) error {
+		n, err := queries.DeletePayeeAlias(ctx, datastore.DeletePayeeAliasParams{
+			ID:    aliasID,
+			Email: email,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to delete the payee alias in the database: %w", err)
+		}
+		if n == 0 {
+			return ErrInvalidAlias
+		}
+
+		return nil
+	})
+}
+
+// Merge merges the source payee into the target one: the aliases, the Pix keys
+// and the bills of the source move to the target, which keeps its name and
+// takes the tax id of the source when it has none, and the source is deleted.
+func (s *Service) Merge(ctx context.Context, email string, sourceID, targetID int64) error {
+	if sourceID == targetID {
+		return ErrMergeSelf
+	}
+
+	return s.db.Write(ctx, func(queries *datastore.Queries) error {
+		source, err := getPayee(ctx, queries, email, sourceID)
+		if err != nil {
+			return err
+		}
+		target, err := getPayee(ctx, queries, email, targetID)
+		if err != nil {
+			return err
+		}
+
+		if target.TaxID == "" && source.TaxID != "" {
+			if _, err := queries.UpdatePayee(ctx, datastore.UpdatePayeeParams{
+				Name:  target.Name,
+				TaxID: source.TaxID,
+				ID:    target.ID,
+				Email: email,
+			}); err != nil {
+				return fmt.Errorf("failed to update the payee in the database: %w", err)
+			}
+		}
+
+		if err := queries.MovePayeeAliases(ctx, datastore.MovePayeeAliasesParams{
+			NewPayeeID: target.ID,
+			Email:      email,
+			OldPayeeID: source.ID,
+		}); err != nil {
+			return fmt.Errorf("failed to move the payee aliases in the database: %w", err)
+		}
+
+		if err := queries.MoveBillsPayee(ctx, datastore.MoveBillsPayeeParams{
+			NewPayeeID: target.ID,
+			Email:      email,
+			OldPayeeID: source.ID,
+		}); err != nil {
+			return fmt.Errorf("failed to move the bills of the payee in the database: %w", err)
+		}
+
+		if _, err := queries.DeletePayee(ctx, datastore.DeletePayeeParams{
+			ID:    source.ID,
+			Email: email,
+		}); err != nil {
+			return fmt.Errorf("failed to delete the merged payee in the database: %w", err)
+		}
+
+		return nil
+	})
+}
+
+func getPayee(ctx context.Context, queries *datastore.Queries, email string, id int64) (datastore.Payee, error) {
+	payee, err := queries.GetPayee(ctx, datastore.GetPayeeParams{
+		ID:    id,
+		Email: email,
+	})
+	if err != nil {
+		if storage.NoRows(err) {
+			return datastore.Payee{}, ErrPayeeNotFound
+		}
+
+		return datastore.Payee{}, fmt.Errorf("failed to get the payee from the database: %w", err)
+	}
+
+	return payee, nil
+}
+
+// Validate returns the trimmed name and the tax id without punctuation.
+func Validate(name, taxID string) (string, string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" || utf8.RuneCountInString(name) > maxNameLength {
+		return "", "", ErrInvalidPayee
+	}
+
+	taxID = strings.TrimSpace(taxID)
+	if taxID == "" {
+		return name, "", nil
+	}
+
+	id, err := taxid.Parse(taxID)
+	if err != nil {
+		return "", "", err
+	}
+
+	return name, id.Number, nil
+}
@@ -0,0 +1,232 @@
+package payee_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/garnizeH/dimdim/pkg/logger"
+	"github.com/garnizeH/dimdim/pkg/mailer"
+	"github.com/garnizeH/dimdim/pkg/money"
+	"github.com/garnizeH/dimdim/pkg/taxid"
+	"github.com/garnizeH/dimdim/service/bill"
+	"github.com/garnizeH/dimdim/service/payee"
+	"github.com/garnizeH/dimdim/storage"
+	"github.com/garnizeH/dimdim/storage/datastore"
+)
+
+const email = "someone@example.com"
+
+func newTestBillService(db *storage.DB[datastore.Queries]) *bill.Service {
+	log := logger.New(io.Discard, logger.LevelError, "test", func(context.Context) string { return "" })
+	return bill.New(log, mailer.New("dimdim@example.com", mailer.NewMemoryTransport()), db)
+}
+
+// payeeIDs returns the ids of the payees of the user by name.
+func payeeIDs(t *testing.T, svc *payee.Service) map[string]int64 {
+	t.Helper()
+
+	payees, err := svc.List(context.Background(), email)
+	if err != nil {
+		t.Fatalf("Service.List() error = %v", err)
+	}
+
+	ids := make(map[string]int64, len(payees))
+	for _, p := range payees {
+		ids[p.Name] = p.ID
+	}
+
+	return ids
+}
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		description string
+		want        string
+	}{
+		{description: "PAG*IFOOD SAO PAULO BR", want: "IFOOD SAO PAULO BR"},
+		{description: "IFD*IFOOD", want: "IFOOD"},
+		{description: "PAYPAL *SPOTIFY", want: "SPOTIFY"},
+		{description: "Padaria São João", want: "PADARIA SAO JOAO"},
+		{description: "  mercado   livre.com  ", want: "MERCADO LIVRE COM"},
+		{description: "CONTA DE LUZ*ENEL", want: "CONTA DE LUZ ENEL"},
+		{description: "***", want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.description, func(t *testing.T) {
+			if got := payee.Normalize(tt.description); got != tt.want {
+				t.Errorf("Normalize(%q) = %q, want %q", tt.description, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestResolve(t *testing.T) {
+	ctx := context.Background()
+	db := datastore.NewDBForTest(t, email)
+	svc := payee.New(db)
+
+	for _, name := range []string{"iFood", "iFood Mercado"} {
+		if err := svc.Create(ctx, email, name, ""); err != nil {
+			t.Fatalf("Service.Create(%q) error = %v", name, err)
+		}
+	}
+	ids := payeeIDs(t, svc)
+
+	tests := []struct {
+		description string
+		want        int64
+	}{
+		{description: "PAG*IFOOD SAO PAULO BR", want: ids["iFood"]},
+		{description: "IFD*IFOOD", want: ids["iFood"]},
+		{description: "IFD*IFOOD MERCADO SP", want: ids["iFood Mercado"]},
+		{description: "IFOODS", want: 0},
+		{description: "Rent", want: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.description, func(t *testing.T) {
+			var got int64
+			if err := db.Read(ctx, func(queries *datastore.Queries) error {
+				var err error
+				got, err = payee.Resolve(ctx, queries, email, tt.description)
+				return err
+			}); err != nil {
+				t.Fatalf("Resolve() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Resolve(%q) = %d, want %d", tt.description, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestServiceCreate(t *testing.T) {
+	svc := payee.New(datastore.NewDBForTest(t, email))
+
+	tests := []struct {
+		name      string
+		payeeName string
+		taxID     string
+		wantErr   error
+	}{
+		{name: "valid", payeeName: "Landlord", taxID: "529.982.247-25"},
+		{name: "without tax id", payeeName: "Bakery"},
+		{name: "alphanumeric cnpj", payeeName: "Company", taxID: "12.ABC.345/01DE-35"},
+		{name: "empty name", payeeName: " ", wantErr: payee.ErrInvalidPayee},
+		{name: "invalid tax id", payeeName: "Someone", taxID: "529.982.247-24", wantErr: taxid.ErrInvalidTaxID},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := svc.Create(context.Background(), email, tt.payeeName, tt.taxID)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Service.Create() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestServiceAddAlias(t *testing.T) {
+	ctx := context.Background()
+	db := datastore.NewDBForTest(t, email)
+	svc := payee.New(db)
+	bills := newTestBillService(db)
+
+	// The bill is linked to the payee when the alias is added.
+	if _, err := bills.CreateBill(ctx, email, "NETFLIX.COM SP", money.New(5590, "BRL"), "2026-05-10", 3, "", ""); err != nil {
+		t.Fatalf("bill.Service.CreateBill() error = %v", err)
+	}
+	for _, name := range []string{"Streaming", "Other"} {
+		if err := svc.Create(ctx, email, name, ""); err != nil {
+			t.Fatalf("Service.Create(%q) error = %v", name, err)
+		}
+	}
+	ids := payeeIDs(t, svc)
+
+	if err := svc.AddAlias(ctx, email, ids["Streaming"], "netflix.com"); err != nil {
+		t.Fatalf("Service.AddAlias() error = %v", err)
+	}
+	if err := svc.AddAlias(ctx, email, ids["Other"], "Netflix com"); !errors.Is(err, payee.ErrAliasInUse) {
+		t.Errorf("Service.AddAlias() of a used alias error = %v, want %v", err, payee.ErrAliasInUse)
+	}
+	if err := svc.AddAlias(ctx, email, ids["Other"], "*"); !errors.Is(err, payee.ErrInvalidAlias) {
+		t.Errorf("Service.AddAlias() of an empty alias error = %v, want %v", err, payee.ErrInvalidAlias)
+	}
+	if err := svc.AddAlias(ctx, email, 999, "Netflix"); !errors.Is(err, payee.ErrPayeeNotFound) {
+		t.Errorf("Service.AddAlias() to an unknown payee error = %v, want %v", err, payee.ErrPayeeNotFound)
+	}
+
+	list, err := bills.ListBills(ctx, email)
+	if err != nil {
+		t.Fatalf("bill.Service.ListBills() error = %v", err)
+	}
+	if list[0].PayeeID != ids["Streaming"] {
+		t.Errorf("Bill.PayeeID = %d, want %d", list[0].PayeeID, ids["Streaming"])
+	}
+}
+
+func TestServiceMerge(t *testing.T) {
+	ctx := context.Background()
+	db := datastore.NewDBForTest(t, email)
+	svc := payee.New(db)
+	bills := newTestBillService(db)
+
+	if err := svc.Create(ctx, email, "iFood", ""); err != nil {
+		t.Fatalf("Service.Create() error = %v", err)
+	}
+	if err := svc.Create(ctx, email, "IFD", "11.222.333/0001-81"); err != nil {
+		t.Fatalf("Service.Create() error = %v", err)
+	}
+	ids := payeeIDs(t, svc)
+	for _, name := range []string{"PAG*IFOOD SAO PAULO BR", "IFD*IFD SP"} {
+		if _, err := bills.CreateBill(ctx, email, name, money.New(4590, "BRL"), "2026-05-10", 3, "", ""); err != nil {
+			t.Fatalf("bill.Service.CreateBill(%q) error = %v", name, err)
+		}
+	}
+
+	if err := svc.Merge(ctx, email, ids["iFood"], ids["iFood"]); !errors.Is(err, payee.ErrMergeSelf) {
+		t.Errorf("Service.Merge() into itself error = %v, want %v", err, payee.ErrMergeSelf)
+	}
+	if err := svc.Merge(ctx, email, 999, ids["iFood"]); !errors.Is(err, payee.ErrPayeeNotFound) {
+		t.Errorf("Service.Merge() of an unknown payee error = %v, want %v", err, payee.ErrPayeeNotFound)
+	}
+	if err := svc.Merge(ctx, email, ids["IFD"], ids["iFood"]); err != nil {
+		t.Fatalf("Service.Merge() error = %v", err)
+	}
+
+	payees, err := svc.List(ctx, email)
+	if err != nil {
+		t.Fatalf("Service.List() error = %v", err)
+	}
+	if len(payees) != 1 || payees[0].ID != ids["iFood"] {
+		t.Fatalf("Service.List() = %+v, want only the merged payee", payees)
+	}
+	if got := payees[0].TaxID.Number; got != "11222333000181" {
+		t.Errorf("merged payee tax id = %q, want the one of the source", got)
+	}
+	if got := len(payees[0].Aliases); got != 2 {
+		t.Errorf("merged payee has %d aliases, want 2", got)
+	}
+
+	list, err := bills.ListBills(ctx, email)
+	if err != nil {
+		t.Fatalf("bill.Service.ListBills() error = %v", err)
+	}
+	for _, b := range list {
+		if b.PayeeID != ids["iFood"] {
+			t.Errorf("payee of the bill %q = %d, want %d", b.Name, b.PayeeID, ids["iFood"])
+		}
+	}
+
+	// The bills created after the merge resolve the aliases of the source.
+	if _, err := bills.CreateBill(ctx, email, "IFD*IFD", money.New(100, "BRL"), "2026-05-11", 3, "", ""); err != nil {
+		t.Fatalf("bill.Service.CreateBill() error = %v", err)
+	}
+	list, err = bills.ListBills(ctx, email)
+	if err != nil {
+		t.Fatalf("bill.Service.ListBills() error = %v", err)
+	}
+	if got := list[len(list)-1].PayeeID; got != ids["iFood"] {
+		t.Errorf("payee of the new bill = %d, want %d", got, ids["iFood"])
+	}
+}
@@ -0,0 +1,150 @@
+package report
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/garnizeH/dimdim/pkg/money"
+	"github.com/garnizeH/dimdim/service/user"
+	"github.com/garnizeH/dimdim/storage/datastore"
+)
+
+// The bounds of the months of a forecast.
+const (
+	MinForecastMonths = 3
+	MaxForecastMonths = 12
+)
+
+// ForecastFilter selects the unpaid bills of the forecast.
+type ForecastFilter struct {
+	// Months is the number of months of the forecast, the current one included.
+	Months   int
+	Currency string
+	// Balance is the money available today to pay the bills, in the currency.
+	Balance money.Money
+}
+
+// ForecastBill is an unpaid bill of the forecast.
+type ForecastBill struct {
+	ID    int64
+	Name  string
+	Payee string
+	// DueOn is the due date in the time zone of the user.
+	DueOn   time.Time
+	Overdue bool
+	Amount  money.Money
+	// Balance is what is left of the balance after paying this bill and the
+	// ones due before it.
+	Balance money.Money
+}
+
+// Forecast is the unpaid bills of the user by due date, and the balance left
+// after paying them.
+type Forecast struct {
+	Filter ForecastFilter
+	// Months total the bills by the month they are due, the overdue bills in
+	// the current month.
+	Months []Total
+	Bills  []ForecastBill
+	Total  money.Money
+	// Negative is the day of the first bill the balance cannot pay, today for
+	// the overdue bills, and zero when it pays all of them.
+	Negative time.Time
+}
+
+// UnpaidBills returns the forecast of the bills still to pay, the overdue ones
+// and the ones due until the end of the months of the filter from now.
+func (s *Service) UnpaidBills(ctx context.Context, email string, f ForecastFilter, now time.Time) (Forecast, error) {
+	if f.Months < MinForecastMonths || f.Months > MaxForecastMonths ||
+		!slices.Contains(user.Currencies, f.Currency) || f.Balance.Currency != f.Currency {
+		return Forecast{}, ErrInvalidFilter
+	}
+
+	var (
+		loc    *time.Location
+		rows   []datastore.Bill
+		payees []datastore.Payee
+	)
+	if err := s.db.Read(ctx, func(queries *datastore.Queries) error {
+		prefs, err := user.GetPreferences(ctx, queries, email)
+		if err != nil {
+			return fmt.Errorf("failed to get the user preferences from the database: %w", err)
+		}
+
+		loc = prefs.Location()
+		until := PeriodMonth.start(now.In(loc)).AddDate(0, f.Months, -1)
+		rows, err = queries.ListUnpaidBills(ctx, datastore.ListUnpaidBillsParams{
+			Email:    email,
+			Currency: f.Currency,
+			DueUntil: until.Format(DateLayout),
+		})
+		if err != nil {
+			return fmt.Errorf("failed to list the unpaid bills from the database: %w", err)
+		}
+
+		payees, err = queries.ListPayeesByEmail(ctx, email)
+		if err != nil {
+			return fmt.Errorf("failed to list the payees from the database: %w", err)
+		}
+
+		return nil
+	}); err != nil {
+		return Forecast{}, err
+	}
+
+	today := now.In(loc)
+	today = time.Date(today.Year(), today.Month(), today.Day(), 0, 0, 0, 0, loc)
+	fc := Forecast{
+		Filter: f,
+		Months: make([]Total, f.Months),
+		Total:  money.New(0, f.Currency),
+	}
+	start := PeriodMonth.start(today)
+	for i := range fc.Months {
+		fc.Months[i] = Total{
+			Start:  start,
+			Amount: money.New(0, f.Currency),
+		}
+		start = PeriodMonth.next(start)
+	}
+
+	names := make(map[int64]string, len(payees))
+	for _, p := range payees {
+		names[p.ID] = p.Name
+	}
+	balance := f.Balance.Amount
+	for _, row := range rows {
+		dueOn, err := time.ParseInLocation(DateLayout, row.DueOn, loc)
+		if err != nil {
+			return Forecast{}, fmt.Errorf("failed to parse the due date of the bill %d: %w", row.ID, err)
+		}
+
+		// The overdue bills are paid from the balance of today.
+		payOn := dueOn
+		if payOn.Before(today) {
+			payOn = today
+		}
+		month := (payOn.Year()-today.Year())*12 + int(payOn.Month()-today.Month())
+		fc.Months[month].Amount.Amount += row.Amount
+		fc.Months[month].Count++
+
+		balance -= row.Amount
+		if balance < 0 && fc.Negative.IsZero() {
+			fc.Negative = payOn
+		}
+		fc.Bills = append(fc.Bills, ForecastBill{
+			ID:      row.ID,
+			Name:    row.Name,
+			Payee:   names[row.PayeeID],
+			DueOn:   dueOn,
+			Overdue: dueOn.Before(today),
+			Amount:  money.New(row.Amount, f.Currency),
+			Balance: money.New(balance, f.Currency),
+		})
+		fc.Total.Amount += row.Amount
+	}
+
+	return fc, nil
+}
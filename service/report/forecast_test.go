@@ -0,0 +1,170 @@
+package report_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/garnizeH/dimdim/pkg/money"
+	"github.com/garnizeH/dimdim/service/report"
+	"github.com/garnizeH/dimdim/storage/datastore"
+)
+
+func TestServiceUnpaidBills(t *testing.T) {
+	// The bills of newTestDB are paid but one, due on 2026-01-01.
+	db, payeeID := newTestDB(t)
+	svc := report.New(db)
+
+	if err := db.Write(context.Background(), func(queries *datastore.Queries) error {
+		ctx := context.Background()
+		for _, b := range []datastore.CreateBillParams{
+			{Email: email, Name: "rent", Amount: 100000, Currency: "BRL", DueOn: "2026-02-15", PayeeID: payeeID},
+			{Email: email, Name: "power", Amount: 15000, Currency: "BRL", DueOn: "2026-03-10"},
+			{Email: email, Name: "insurance", Amount: 50000, Currency: "BRL", DueOn: "2026-04-30"},
+			{Email: email, Name: "after the forecast", Amount: 1000, Currency: "BRL", DueOn: "2026-05-01"},
+			{Email: email, Name: "hosting", Amount: 500, Currency: "USD", DueOn: "2026-02-20"},
+		} {
+			if _, err := queries.CreateBill(ctx, b); err != nil {
+				return err
+			}
+		}
+
+		paid, err := queries.CreateBill(ctx, datastore.CreateBillParams{Email: email, Name: "paid", Amount: 700, Currency: "BRL", DueOn: "2026-02-20"})
+		if err != nil {
+			return err
+		}
+		_, err = queries.SetBillPaid(ctx, datastore.SetBillPaidParams{PaidAt: time.Now().UnixMilli(), ID: paid.ID, Email: email})
+		return err
+	}); err != nil {
+		t.Fatalf("failed to create the bills: %v", err)
+	}
+
+	saoPaulo, err := time.LoadLocation("America/Sao_Paulo")
+	if err != nil {
+		t.Fatalf("failed to load the time zone: %v", err)
+	}
+	// Already 2026-02-11 in UTC.
+	now := time.Date(2026, time.February, 10, 22, 0, 0, 0, saoPaulo)
+
+	type bill struct {
+		name    string
+		payee   string
+		overdue bool
+		balance int64
+	}
+	tests := []struct {
+		name         string
+		filter       report.ForecastFilter
+		wantMonths   []int64
+		wantBills    []bill
+		wantNegative string
+		wantErr      error
+	}{
+		{
+			name:       "negative in the second month",
+			filter:     report.ForecastFilter{Months: 3, Currency: "BRL", Balance: money.New(110000, "BRL")},
+			wantMonths: []int64{100100, 15000, 50000},
+			wantBills: []bill{
+				{name: "unpaid", overdue: true, balance: 109900},
+				{name: "rent", payee: "Landlord", balance: 9900},
+				{name: "power", balance: -5100},
+				{name: "insurance", balance: -55100},
+			},
+			wantNegative: "2026-03-10",
+		},
+		{
+			name:       "enough balance",
+			filter:     report.ForecastFilter{Months: 4, Currency: "BRL", Balance: money.New(200000, "BRL")},
+			wantMonths: []int64{100100, 15000, 50000, 1000},
+			wantBills: []bill{
+				{name: "unpaid", overdue: true, balance: 199900},
+				{name: "rent", payee: "Landlord", balance: 99900},
+				{name: "power", balance: 84900},
+				{name: "insurance", balance: 34900},
+				{name: "after the forecast", balance: 33900},
+			},
+		},
+		{
+			name:       "other currency without balance",
+			filter:     report.ForecastFilter{Months: 3, Currency: "USD", Balance: money.New(0, "USD")},
+			wantMonths: []int64{500, 0, 0},
+			wantBills: []bill{
+				{name: "hosting", balance: -500},
+			},
+			wantNegative: "2026-02-20",
+		},
+		{
+			name:       "negative on the overdue bill",
+			filter:     report.ForecastFilter{Months: 3, Currency: "BRL", Balance: money.New(50, "BRL")},
+			wantMonths: []int64{100100, 15000, 50000},
+			wantBills: []bill{
+				{name: "unpaid", overdue: true, balance: -50},
+				{name: "rent", payee: "Landlord", balance: -100050},
+				{name: "power", balance: -115050},
+				{name: "insurance", balance: -165050},
+			},
+			wantNegative: "2026-02-10",
+		},
+		{
+			name:    "too few months",
+			filter:  report.ForecastFilter{Months: 2, Currency: "BRL", Balance: money.New(0, "BRL")},
+			wantErr: report.ErrInvalidFilter,
+		},
+		{
+			name:    "too many months",
+			filter:  report.ForecastFilter{Months: 13, Currency: "BRL", Balance: money.New(0, "BRL")},
+			wantErr: report.ErrInvalidFilter,
+		},
+		{
+			name:    "balance in another currency",
+			filter:  report.ForecastFilter{Months: 3, Currency: "BRL", Balance: money.New(0, "USD")},
+			wantErr: report.ErrInvalidFilter,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := svc.UnpaidBills(context.Background(), email, tt.filter, now)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Service.UnpaidBills() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+
+			if len(got.Months) != len(tt.wantMonths) {
+				t.Fatalf("Service.UnpaidBills() has %d months, want %d", len(got.Months), len(tt.wantMonths))
+			}
+			var total int64
+			for i, want := range tt.wantMonths {
+				m := got.Months[i]
+				wantStart := time.Date(2026, time.February+time.Month(i), 1, 0, 0, 0, 0, saoPaulo)
+				if !m.Start.Equal(wantStart) || m.Amount != money.New(want, tt.filter.Currency) {
+					t.Errorf("month %d = %s %v, want %s %d", i, m.Start.Format(report.DateLayout), m.Amount, wantStart.Format(report.DateLayout), want)
+				}
+				total += want
+			}
+			if got.Total != money.New(total, tt.filter.Currency) {
+				t.Errorf("total = %v, want %d", got.Total, total)
+			}
+
+			if len(got.Bills) != len(tt.wantBills) {
+				t.Fatalf("Service.UnpaidBills() has the bills %+v, want %v", got.Bills, tt.wantBills)
+			}
+			for i, want := range tt.wantBills {
+				b := got.Bills[i]
+				if b.Name != want.name || b.Payee != want.payee || b.Overdue != want.overdue || b.Balance != money.New(want.balance, tt.filter.Currency) {
+					t.Errorf("bill %d = %s %q %t %v, want %s %q %t %d", i, b.Name, b.Payee, b.Overdue, b.Balance, want.name, want.payee, want.overdue, want.balance)
+				}
+			}
+
+			negative := ""
+			if !got.Negative.IsZero() {
+				negative = got.Negative.Format(report.DateLayout)
+			}
+			if negative != tt.wantNegative {
+				t.Errorf("negative = %q, want %q", negative, tt.wantNegative)
+			}
+		})
+	}
+}
@@ -0,0 +1,225 @@
+package report
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/garnizeH/dimdim/pkg/money"
+	"github.com/garnizeH/dimdim/service/user"
+	"github.com/garnizeH/dimdim/storage"
+	"github.com/garnizeH/dimdim/storage/datastore"
+)
+
+const (
+	// DateLayout is the layout of the dates of the filter.
+	DateLayout = "2006-01-02"
+
+	// maxPeriods bounds the number of periods of a report, ten years of months.
+	maxPeriods = 120
+)
+
+var ErrInvalidFilter = errors.New("invalid report filter")
+
+// Period is the length of the periods the report is grouped by.
+type Period string
+
+const (
+	PeriodMonth   Period = "month"
+	PeriodQuarter Period = "quarter"
+	PeriodYear    Period = "year"
+)
+
+var Periods = []Period{PeriodMonth, PeriodQuarter, PeriodYear}
+
+// start returns the start of the period containing t.
+func (p Period) start(t time.Time) time.Time {
+	switch p {
+	case PeriodQuarter:
+		return time.Date(t.Year(), (t.Month()-1)/3*3+1, 1, 0, 0, 0, 0, t.Location())
+	case PeriodYear:
+		return time.Date(t.Year(), time.January, 1, 0, 0, 0, 0, t.Location())
+	default:
+		return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, t.Location())
+	}
+}
+
+// next returns the start of the period after the one starting at t.
+func (p Period) next(t time.Time) time.Time {
+	switch p {
+	case PeriodQuarter:
+		return t.AddDate(0, 3, 0)
+	case PeriodYear:
+		return t.AddDate(1, 0, 0)
+	default:
+		return t.AddDate(0, 1, 0)
+	}
+}
+
+// Filter selects the paid bills of the report, by the day they were paid in
+// the time zone of the user.
+type Filter struct {
+	// From and To are the first and the last days, in the DateLayout.
+	From     string
+	To       string
+	Period   Period
+	Currency string
+	// PayeeID selects the bills of a single payee when not zero.
+	PayeeID int64
+}
+
+// Total is the sum of the paid bills of a period.
+type Total struct {
+	// Start is the first day of the period in the time zone of the user.
+	Start  time.Time
+	Amount money.Money
+	Count  int
+}
+
+// PayeeTotal is the sum of the paid bills of a payee.
+type PayeeTotal struct {
+	// PayeeID is zero for the bills without payee.
+	PayeeID int64
+	Name    string
+	Amount  money.Money
+	Count   int
+	// Share is the ratio of the amount to the total of the report.
+	Share float64
+}
+
+// Report is the expenses of the user, the paid bills, over time and by payee.
+type Report struct {
+	Filter  Filter
+	Periods []Total
+	// Payees are ordered from the largest amount.
+	Payees []PayeeTotal
+	Total  money.Money
+	Count  int
+}
+
+type Service struct {
+	db *storage.DB[datastore.Queries]
+}
+
+func New(db *storage.DB[datastore.Queries]) *Service {
+	return &Service{
+		db: db,
+	}
+}
+
+// PaidBills returns the report of the bills paid in the filter.
+func (s *Service) PaidBills(ctx context.Context, email string, f Filter) (Report, error) {
+	if !slices.Contains(Periods, f.Period) || !slices.Contains(user.Currencies, f.Currency) {
+		return Report{}, ErrInvalidFilter
+	}
+
+	var (
+		from, to time.Time
+		rows     []datastore.Bill
+		payees   []datastore.Payee
+	)
+	if err := s.db.Read(ctx, func(queries *datastore.Queries) error {
+		prefs, err := user.GetPreferences(ctx, queries, email)
+		if err != nil {
+			return fmt.Errorf("failed to get the user preferences from the database: %w", err)
+		}
+
+		loc := prefs.Location()
+		from, err = time.ParseInLocation(DateLayout, f.From, loc)
+		if err != nil {
+			return ErrInvalidFilter
+		}
+		to, err = time.ParseInLocation(DateLayout, f.To, loc)
+		if err != nil || to.Before(from) {
+			return ErrInvalidFilter
+		}
+
+		rows, err = queries.ListPaidBills(ctx, datastore.ListPaidBillsParams{
+			Email:     email,
+			Currency:  f.Currency,
+			PaidFrom:  from.UTC().UnixMilli(),
+			PaidUntil: to.AddDate(0, 0, 1).UTC().UnixMilli(),
+		})
+		if err != nil {
+			return fmt.Errorf("failed to list the paid bills from the database: %w", err)
+		}
+
+		payees, err = queries.ListPayeesByEmail(ctx, email)
+		if err != nil {
+			return fmt.Errorf("failed to list the payees from the database: %w", err)
+		}
+
+		return nil
+	}); err != nil {
+		return Report{}, err
+	}
+
+	r := Report{
+		Filter: f,
+		Total:  money.New(0, f.Currency),
+	}
+	// The periods are indexed by the unix time of their start.
+	index := map[int64]int{}
+	for start := f.Period.start(from); !start.After(to); start = f.Period.next(start) {
+		if len(r.Periods) == maxPeriods {
+			return Report{}, ErrInvalidFilter
+		}
+
+		index[start.Unix()] = len(r.Periods)
+		r.Periods = append(r.Periods, Total{
+			Start:  start,
+			Amount: money.New(0, f.Currency),
+		})
+	}
+
+	names := make(map[int64]string, len(payees))
+	for _, p := range payees {
+		names[p.ID] = p.Name
+	}
+	byPayee := map[int64]*PayeeTotal{}
+	for _, row := range rows {
+		// The bills of deleted payees are reported without payee.
+		payeeID := row.PayeeID
+		if _, ok := names[payeeID]; !ok {
+			payeeID = 0
+		}
+		if f.PayeeID != 0 && payeeID != f.PayeeID {
+			continue
+		}
+
+		paidAt := time.UnixMilli(row.PaidAt).In(from.Location())
+		period := &r.Periods[index[f.Period.start(paidAt).Unix()]]
+		period.Amount.Amount += row.Amount
+		period.Count++
+
+		p, ok := byPayee[payeeID]
+		if !ok {
+			p = &PayeeTotal{
+				PayeeID: payeeID,
+				Name:    names[payeeID],
+				Amount:  money.New(0, f.Currency),
+			}
+			byPayee[payeeID] = p
+		}
+		p.Amount.Amount += row.Amount
+		p.Count++
+
+		r.Total.Amount += row.Amount
+		r.Count++
+	}
+
+	for _, p := range byPayee {
+		if r.Total.Amount != 0 {
+			p.Share = float64(p.Amount.Amount) / float64(r.Total.Amount)
+		}
+		r.Payees = append(r.Payees, *p)
+	}
+	slices.SortFunc(r.Payees, func(a, b PayeeTotal) int {
+		return cmp.Or(cmp.Compare(b.Amount.Amount, a.Amount.Amount), cmp.Compare(a.Name, b.Name))
+	})
+
+	return r, nil
+}
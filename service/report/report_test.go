@@ -0,0 +1,212 @@
+package report_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/garnizeH/dimdim/pkg/money"
+	"github.com/garnizeH/dimdim/service/report"
+	"github.com/garnizeH/dimdim/storage"
+	"github.com/garnizeH/dimdim/storage/datastore"
+)
+
+const email = "someone@example.com"
+
+// newTestDB creates the user, in São Paulo, with the bills paid at the given times.
+func newTestDB(t *testing.T) (*storage.DB[datastore.Queries], int64) {
+	t.Helper()
+
+	saoPaulo, err := time.LoadLocation("America/Sao_Paulo")
+	if err != nil {
+		t.Fatalf("failed to load the time zone: %v", err)
+	}
+
+	bills := []struct {
+		name     string
+		amount   int64
+		currency string
+		paidAt   time.Time
+		payee    bool
+	}{
+		// Already February in UTC.
+		{name: "rent", amount: 100000, currency: "BRL", paidAt: time.Date(2026, time.January, 31, 23, 30, 0, 0, saoPaulo), payee: true},
+		{name: "rent", amount: 100000, currency: "BRL", paidAt: time.Date(2026, time.February, 28, 10, 0, 0, 0, saoPaulo), payee: true},
+		{name: "power", amount: 15050, currency: "BRL", paidAt: time.Date(2026, time.February, 15, 10, 0, 0, 0, saoPaulo)},
+		{name: "power", amount: 14000, currency: "BRL", paidAt: time.Date(2026, time.April, 1, 0, 10, 0, 0, saoPaulo)},
+		{name: "hosting", amount: 500, currency: "USD", paidAt: time.Date(2026, time.February, 1, 10, 0, 0, 0, saoPaulo)},
+		{name: "later", amount: 100, currency: "BRL", paidAt: time.Date(2026, time.May, 1, 0, 0, 0, 0, saoPaulo)},
+		{name: "unpaid", amount: 100, currency: "BRL"},
+	}
+
+	var payeeID int64
+	db := datastore.NewDBForTest(t, email)
+	if err := db.Write(context.Background(), func(queries *datastore.Queries) error {
+		ctx := context.Background()
+		payee, err := queries.CreatePayee(ctx, datastore.CreatePayeeParams{Email: email, Name: "Landlord"})
+		if err != nil {
+			return err
+		}
+		payeeID = payee.ID
+
+		for _, b := range bills {
+			if _, err := queries.CreateBill(ctx, datastore.CreateBillParams{
+				Email:    email,
+				Name:     b.name,
+				Amount:   b.amount,
+				Currency: b.currency,
+				DueOn:    "2026-01-01",
+			}); err != nil {
+				return err
+			}
+		}
+
+		rows, err := queries.ListBillsByEmail(ctx, email)
+		if err != nil {
+			return err
+		}
+		for i, row := range rows {
+			b := bills[i]
+			if b.payee {
+				if err := queries.SetBillPayee(ctx, datastore.SetBillPayeeParams{PayeeID: payeeID, ID: row.ID}); err != nil {
+					return err
+				}
+			}
+			if b.paidAt.IsZero() {
+				continue
+			}
+			if _, err := queries.SetBillPaid(ctx, datastore.SetBillPaidParams{
+				PaidAt: b.paidAt.UTC().UnixMilli(),
+				ID:     row.ID,
+				Email:  email,
+			}); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}); err != nil {
+		t.Fatalf("failed to create the bills: %v", err)
+	}
+
+	return db, payeeID
+}
+
+func TestServicePaidBills(t *testing.T) {
+	db, payeeID := newTestDB(t)
+	svc := report.New(db)
+
+	type period struct {
+		start  string
+		amount int64
+		count  int
+	}
+	tests := []struct {
+		name        string
+		filter      report.Filter
+		wantPeriods []period
+		wantPayees  []int64
+		wantTotal   int64
+		wantErr     error
+	}{
+		{
+			name:   "months",
+			filter: report.Filter{From: "2026-01-01", To: "2026-04-30", Period: report.PeriodMonth, Currency: "BRL"},
+			wantPeriods: []period{
+				{start: "2026-01-01", amount: 100000, count: 1},
+				{start: "2026-02-01", amount: 115050, count: 2},
+				{start: "2026-03-01"},
+				{start: "2026-04-01", amount: 14000, count: 1},
+			},
+			wantPayees: []int64{payeeID, 0},
+			wantTotal:  229050,
+		},
+		{
+			name:   "quarters from the middle of a quarter",
+			filter: report.Filter{From: "2026-02-01", To: "2026-04-01", Period: report.PeriodQuarter, Currency: "BRL"},
+			wantPeriods: []period{
+				{start: "2026-01-01", amount: 115050, count: 2},
+				{start: "2026-04-01", amount: 14000, count: 1},
+			},
+			wantPayees: []int64{payeeID, 0},
+			wantTotal:  129050,
+		},
+		{
+			name:   "year of a payee",
+			filter: report.Filter{From: "2026-01-01", To: "2026-12-31", Period: report.PeriodYear, Currency: "BRL", PayeeID: payeeID},
+			wantPeriods: []period{
+				{start: "2026-01-01", amount: 200000, count: 2},
+			},
+			wantPayees: []int64{payeeID},
+			wantTotal:  200000,
+		},
+		{
+			name:   "other currency",
+			filter: report.Filter{From: "2026-01-01", To: "2026-02-28", Period: report.PeriodMonth, Currency: "USD"},
+			wantPeriods: []period{
+				{start: "2026-01-01"},
+				{start: "2026-02-01", amount: 500, count: 1},
+			},
+			wantPayees: []int64{0},
+			wantTotal:  500,
+		},
+		{
+			name:    "to before from",
+			filter:  report.Filter{From: "2026-02-01", To: "2026-01-31", Period: report.PeriodMonth, Currency: "BRL"},
+			wantErr: report.ErrInvalidFilter,
+		},
+		{
+			name:    "too many periods",
+			filter:  report.Filter{From: "2000-01-01", To: "2026-01-31", Period: report.PeriodMonth, Currency: "BRL"},
+			wantErr: report.ErrInvalidFilter,
+		},
+		{
+			name:    "unknown period",
+			filter:  report.Filter{From: "2026-01-01", To: "2026-01-31", Period: "week", Currency: "BRL"},
+			wantErr: report.ErrInvalidFilter,
+		},
+		{
+			name:    "invalid date",
+			filter:  report.Filter{From: "01/01/2026", To: "2026-01-31", Period: report.PeriodMonth, Currency: "BRL"},
+			wantErr: report.ErrInvalidFilter,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := svc.PaidBills(context.Background(), email, tt.filter)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Service.PaidBills() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+
+			if len(got.Periods) != len(tt.wantPeriods) {
+				t.Fatalf("Service.PaidBills() has %d periods, want %d", len(got.Periods), len(tt.wantPeriods))
+			}
+			for i, want := range tt.wantPeriods {
+				p := got.Periods[i]
+				if start := p.Start.Format(report.DateLayout); start != want.start || p.Amount != money.New(want.amount, tt.filter.Currency) || p.Count != want.count {
+					t.Errorf("period %d = %s %v %d, want %s %d %d", i, start, p.Amount, p.Count, want.start, want.amount, want.count)
+				}
+			}
+
+			var payees []int64
+			for _, p := range got.Payees {
+				payees = append(payees, p.PayeeID)
+			}
+			if len(payees) != len(tt.wantPayees) {
+				t.Fatalf("payees = %v, want %v", payees, tt.wantPayees)
+			}
+			for i := range payees {
+				if payees[i] != tt.wantPayees[i] {
+					t.Errorf("payees = %v, want %v", payees, tt.wantPayees)
+				}
+			}
+			if got.Total != money.New(tt.wantTotal, tt.filter.Currency) {
+				t.Errorf("total = %v, want %d", got.Total, tt.wantTotal)
+			}
+		})
+	}
+}
@@ -5,25 +5,66 @@ import (
 	"strings"
 
 	"github.com/garnizeH/dimdim/pkg/argon2id"
+	"github.com/garnizeH/dimdim/pkg/logger"
 	"github.com/garnizeH/dimdim/pkg/mailer"
+	"github.com/garnizeH/dimdim/service/accesstoken"
+	"github.com/garnizeH/dimdim/service/backup"
+	"github.com/garnizeH/dimdim/service/bill"
+	"github.com/garnizeH/dimdim/service/invite"
+	"github.com/garnizeH/dimdim/service/notification"
+	"github.com/garnizeH/dimdim/service/outbox"
+	"github.com/garnizeH/dimdim/service/payee"
+	"github.com/garnizeH/dimdim/service/report"
 	"github.com/garnizeH/dimdim/service/user"
 	"github.com/garnizeH/dimdim/storage"
 	"github.com/garnizeH/dimdim/storage/datastore"
 )
 
+type Config struct {
+	User user.Config
+}
+
 type Service struct {
-	user *user.Service
+	user   *user.Service
+	invite *invite.Service
+	outbox *outbox.Service
+
+	bill         *bill.Service
+	notification *notification.Service
+	payee        *payee.Service
+	report       *report.Service
+	backup       *backup.Service
+	accessToken  *accesstoken.Service
 }
 
 func New(
+	log *logger.Logger,
+	cfg Config,
 	argon *argon2id.Argon2idHash,
 	mailer *mailer.Mailer,
 	db *storage.DB[datastore.Queries],
 ) *Service {
-	user := user.New(argon, mailer, db)
+	user := user.New(log, cfg.User, argon, mailer, db)
+	invite := invite.New(db)
+	outbox := outbox.New(mailer, db)
+	bill := bill.New(log, mailer, db)
+	notification := notification.New(db)
+	payee := payee.New(db)
+	report := report.New(db)
+	backup := backup.New(db)
+	accessToken := accesstoken.New(db)
 
 	return &Service{
-		user: user,
+		user:   user,
+		invite: invite,
+		outbox: outbox,
+
+		bill:         bill,
+		notification: notification,
+		payee:        payee,
+		report:       report,
+		backup:       backup,
+		accessToken:  accessToken,
 	}
 }
 
@@ -31,6 +72,38 @@ func (s *Service) User() *user.Service {
 	return s.user
 }
 
+func (s *Service) Invite() *invite.Service {
+	return s.invite
+}
+
+func (s *Service) Outbox() *outbox.Service {
+	return s.outbox
+}
+
+func (s *Service) Bill() *bill.Service {
+	return s.bill
+}
+
+func (s *Service) Notification() *notification.Service {
+	return s.notification
+}
+
+func (s *Service) Payee() *payee.Service {
+	return s.payee
+}
+
+func (s *Service) Report() *report.Service {
+	return s.report
+}
+
+func (s *Service) Backup() *backup.Service {
+	return s.backup
+}
+
+func (s *Service) AccessToken() *accesstoken.Service {
+	return s.accessToken
+}
+
 var (
 	ErrInvalidParam = errors.New("invalid param")
 	ErrUniqueParam  = errors.New("param violated unique constraint")
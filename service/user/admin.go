@@ -0,0 +1,246 @@
+package user
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/garnizeH/dimdim/pkg/mailer"
+	"github.com/garnizeH/dimdim/service/outbox"
+	"github.com/garnizeH/dimdim/storage"
+	"github.com/garnizeH/dimdim/storage/datastore"
+	"github.com/google/uuid"
+)
+
+// Account is the view of a user shown to the administrators.
+type Account struct {
+	Email        string
+	Name         string
+	Admin        bool
+	CreatedAt    time.Time
+	VerifiedAt   time.Time
+	DisabledAt   time.Time
+	ActiveTokens int64
+}
+
+func (a Account) Verified() bool {
+	return !a.VerifiedAt.IsZero()
+}
+
+func (a Account) Disabled() bool {
+	return !a.DisabledAt.IsZero()
+}
+
+func newAccount(u datastore.User) Account {
+	return Account{
+		Email:      u.Email,
+		Name:       u.Name,
+		Admin:      u.IsAdmin,
+		CreatedAt:  timeFromMilli(u.CreatedAt),
+		VerifiedAt: timeFromMilli(u.VerifiedAt),
+		DisabledAt: timeFromMilli(u.DisabledAt),
+	}
+}
+
+// ListAccounts returns the accounts whose email or name contains the search term.
+func (s *Service) ListAccounts(ctx context.Context, search string) ([]Account, error) {
+	var users []datastore.User
+	if err := s.db.Read(ctx, func(queries *datastore.Queries) error {
+		var err error
+		users, err = queries.GetAllUsers(ctx, likePattern(search))
+		return err
+	}); err != nil {
+		return nil, err
+	}
+
+	accounts := make([]Account, len(users))
+	for i, u := range users {
+		accounts[i] = newAccount(u)
+	}
+
+	return accounts, nil
+}
+
+// GetAccount returns the account with the number of tokens not yet expired.
+func (s *Service) GetAccount(ctx context.Context, email string) (Account, error) {
+	var account Account
+	if err := s.db.Read(ctx, func(queries *datastore.Queries) error {
+		user, err := queries.GetUser(ctx, email)
+		if err != nil {
+			if storage.NoRows(err) {
+				return ErrEmailNotFound
+			}
+
+			return err
+		}
+		account = newAccount(user)
+
+		account.ActiveTokens, err = queries.CountActiveTokensByEmail(ctx, datastore.CountActiveTokensByEmailParams{
+			Email:     email,
+			ExpiresAt: time.Now().UTC().UnixMilli(),
+		})
+		return err
+	}); err != nil {
+		return Account{}, err
+	}
+
+	return account, nil
+}
+
+// IsAdmin returns whether the account is an enabled administrator. It reads the
+// database and not the user cache, so a role revoked by the admin command is
+// enforced at once.
+func (s *Service) IsAdmin(ctx context.Context, email string) (bool, error) {
+	var user datastore.User
+	if err := s.db.Read(ctx, func(queries *datastore.Queries) error {
+		var err error
+		user, err = queries.GetUser(ctx, email)
+		return err
+	}); err != nil {
+		if storage.NoRows(err) {
+			return false, nil
+		}
+
+		return false, err
+	}
+
+	return user.IsAdmin && user.DisabledAt == 0, nil
+}
+
+// SetAdmin grants or revokes the administrative role of the account.
+func (s *Service) SetAdmin(ctx context.Context, email string, admin bool) error {
+	if err := s.db.Write(ctx, func(queries *datastore.Queries) error {
+		n, err := queries.SetUserIsAdmin(ctx, datastore.SetUserIsAdminParams{
+			IsAdmin: admin,
+			Email:   email,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to update the admin flag in the database: %w", err)
+		}
+		if n == 0 {
+			return ErrEmailNotFound
+		}
+
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	s.userCache.Delete(email)
+
+	return nil
+}
+
+// SetDisabled disables or re-enables the account. Disabled accounts cannot sign in.
+func (s *Service) SetDisabled(ctx context.Context, email string, disabled bool) error {
+	var disabledAt int64
+	if disabled {
+		disabledAt = time.Now().UTC().UnixMilli()
+	}
+
+	if err := s.db.Write(ctx, func(queries *datastore.Queries) error {
+		n, err := queries.SetUserDisabledAt(ctx, datastore.SetUserDisabledAtParams{
+			DisabledAt: disabledAt,
+			Email:      email,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to update the disabled flag in the database: %w", err)
+		}
+		if n == 0 {
+			return ErrEmailNotFound
+		}
+
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	s.userCache.Delete(email)
+
+	return nil
+}
+
+// ForcePasswordReset replaces the password of the account by a random one and
+// sends the reset password email, so the user must choose a new password.
+func (s *Service) ForcePasswordReset(
+	ctx context.Context,
+	baseURL string,
+	email string,
+) error {
+	if err := s.db.Write(ctx, func(queries *datastore.Queries) error {
+		user, err := queries.GetUser(ctx, email)
+		if err != nil {
+			if storage.NoRows(err) {
+				return ErrEmailNotFound
+			}
+
+			return fmt.Errorf("failed to check for the email existence in the database: %w", err)
+		}
+		if user.VerifiedAt == 0 {
+			return ErrUserNotVerified
+		}
+
+		hashSalt, err := s.argon.GenerateHash([]byte(rand.Text()), nil)
+		if err != nil {
+			return fmt.Errorf("failed to hash the password: %w", err)
+		}
+
+		if _, err := queries.UpdateUserPassword(ctx, datastore.UpdateUserPasswordParams{
+			Email:    email,
+			Password: hashSalt.Hash,
+			Salt:     hashSalt.Salt,
+		}); err != nil {
+			return fmt.Errorf("failed to update the user password in the database: %w", err)
+		}
+
+		prefs, err := GetPreferences(ctx, queries, email)
+		if err != nil {
+			return fmt.Errorf("failed to get the user preferences from the database: %w", err)
+		}
+
+		token := uuid.New().String()
+		mail := mailer.NewMailPassword(prefs.Locale, baseURL, email, user.Name, token)
+
+		if err := queries.DeletePasswordTokensByEmail(ctx, email); err != nil {
+			return fmt.Errorf("failed to delete existing reset password tokens for the email %q in the database: %w", email, err)
+		}
+
+		expiresAt := time.Now().Add(tokenDurationPassword).UTC().UnixMilli()
+		if err := queries.CreateToken(ctx, datastore.CreateTokenParams{
+			Token:     token,
+			Type:      tokenPassword,
+			Email:     email,
+			ExpiresAt: expiresAt,
+		}); err != nil {
+			return fmt.Errorf("failed to create the reset password token in the database: %w", err)
+		}
+
+		if err := outbox.Queue(ctx, queries, mail); err != nil {
+			return err
+		}
+
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	s.userCache.Delete(email)
+	s.mailer.Notify()
+
+	return nil
+}
+
+// likePattern matches the search term anywhere, ignoring the wildcards typed by the user.
+func likePattern(search string) string {
+	return "%" + strings.NewReplacer("%", "", "_", "").Replace(search) + "%"
+}
+
+func timeFromMilli(ms int64) time.Time {
+	if ms == 0 {
+		return time.Time{}
+	}
+
+	return time.UnixMilli(ms).UTC()
+}
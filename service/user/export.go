@@ -0,0 +1,555 @@
+package user
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/garnizeH/dimdim/pkg/mailer"
+	"github.com/garnizeH/dimdim/pkg/money"
+	"github.com/garnizeH/dimdim/service/outbox"
+	"github.com/garnizeH/dimdim/storage"
+	"github.com/garnizeH/dimdim/storage/datastore"
+	"github.com/google/uuid"
+)
+
+const (
+	tokenExport = "EXPORT"
+
+	// maxQueuedExports is the number of exports waiting to be generated, the
+	// requests beyond it are rejected until the queue drains.
+	maxQueuedExports = 16
+)
+
+var (
+	ErrExportNotFound = errors.New("export not found")
+	ErrExportPending  = errors.New("an export is already being generated, check your mailbox")
+	ErrExportsBusy    = errors.New("too many exports being generated, try again later")
+)
+
+// ExportSession is the session information kept by the web layer that is included in the export.
+type ExportSession struct {
+	UserAgent string
+	IP        string
+	CreatedAt time.Time
+	LastSeen  time.Time
+}
+
+type exportAccount struct {
+	Email      string `json:"email"`
+	Name       string `json:"name"`
+	CreatedAt  string `json:"created_at"`
+	UpdatedAt  string `json:"updated_at"`
+	VerifiedAt string `json:"verified_at,omitempty"`
+	Admin      bool   `json:"admin"`
+	DisabledAt string `json:"disabled_at,omitempty"`
+	DeletedAt  string `json:"deleted_at,omitempty"`
+}
+
+type exportPreferences struct {
+	Locale   string `json:"locale"`
+	Timezone string `json:"timezone"`
+	Currency string `json:"currency"`
+	// FirstDayOfWeek is 0 for Sunday to 6 for Saturday, as in the backups.
+	FirstDayOfWeek int    `json:"first_day_of_week"`
+	DateFormat     string `json:"date_format"`
+}
+
+// RequestExport checks the account and queues the personal data export, generated in the
+// background by RunExports. The user receives an email with the download link once the export
+// is ready.
+func (s *Service) RequestExport(
+	ctx context.Context,
+	baseURL string,
+	email string,
+	sessions []ExportSession,
+) error {
+	var user datastore.User
+	if err := s.db.Read(ctx, func(queries *datastore.Queries) error {
+		var err error
+		user, err = queries.GetUser(ctx, email)
+		if err != nil {
+			if storage.NoRows(err) {
+				return ErrEmailNotFound
+			}
+
+			return err
+		}
+
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	// Only one export per user is queued or being generated at a time.
+	if _, pending := s.exporting.LoadOrStore(user.Email, struct{}{}); pending {
+		return ErrExportPending
+	}
+
+	select {
+	case s.exports <- exportJob{baseURL: baseURL, user: user, sessions: sessions}:
+		return nil
+	default:
+		s.exporting.Delete(user.Email)
+		return ErrExportsBusy
+	}
+}
+
+type exportJob struct {
+	baseURL  string
+	user     datastore.User
+	sessions []ExportSession
+}
+
+// RunExports generates the requested exports one at a time until the context
+// is canceled. The exports still queued are dropped and must be requested again.
+func (s *Service) RunExports(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case job := <-s.exports:
+			if err := s.export(ctx, job.baseURL, job.user, job.sessions); err != nil {
+				s.log.Error(ctx, "export", "status", "failed to export the user data", "email", job.user.Email, "error", err)
+			}
+			s.exporting.Delete(job.user.Email)
+		}
+	}
+}
+
+func (s *Service) export(
+	ctx context.Context,
+	baseURL string,
+	user datastore.User,
+	sessions []ExportSession,
+) error {
+	var (
+		prefs         Preferences
+		tokens        []datastore.Token
+		bills         []datastore.Bill
+		payees        []datastore.Payee
+		aliases       []datastore.PayeeAlias
+		searches      []datastore.SavedSearch
+		access        []datastore.AccessToken
+		notifications []datastore.Notification
+		emails        []datastore.Outbox
+	)
+	if err := s.db.Read(ctx, func(queries *datastore.Queries) error {
+		// The account may have been deleted while the export was queued.
+		if _, err := queries.GetUser(ctx, user.Email); err != nil {
+			return err
+		}
+
+		var err error
+		prefs, err = GetPreferences(ctx, queries, user.Email)
+		if err != nil {
+			return err
+		}
+
+		tokens, err = queries.ListTokensByEmail(ctx, user.Email)
+		if err != nil {
+			return err
+		}
+
+		bills, err = queries.ListBillsByEmail(ctx, user.Email)
+		if err != nil {
+			return err
+		}
+
+		payees, err = queries.ListPayeesByEmail(ctx, user.Email)
+		if err != nil {
+			return err
+		}
+
+		aliases, err = queries.ListPayeeAliasesByEmail(ctx, user.Email)
+		if err != nil {
+			return err
+		}
+
+		searches, err = queries.ListSavedSearchesByEmail(ctx, user.Email)
+		if err != nil {
+			return err
+		}
+
+		access, err = queries.ListAccessTokensByEmail(ctx, user.Email)
+		if err != nil {
+			return err
+		}
+
+		notifications, err = queries.ListNotificationsByEmail(ctx, user.Email)
+		if err != nil {
+			return err
+		}
+
+		emails, err = queries.ListOutboxMessagesByRecipient(ctx, user.Email)
+		return err
+	}); err != nil {
+		return fmt.Errorf("failed to read the user data: %w", err)
+	}
+
+	if err := os.MkdirAll(s.cfg.ExportsDir, 0o700); err != nil {
+		return fmt.Errorf("failed to create the exports directory: %w", err)
+	}
+
+	token := uuid.New().String()
+	filename := s.exportFilename(token)
+	data := exportData{
+		user:          user,
+		prefs:         prefs,
+		tokens:        tokens,
+		access:        access,
+		bills:         bills,
+		payees:        payees,
+		aliases:       aliases,
+		searches:      searches,
+		notifications: notifications,
+		emails:        emails,
+		sessions:      sessions,
+	}
+	if err := writeExport(filename, data); err != nil {
+		return err
+	}
+
+	if err := s.db.Write(ctx, func(queries *datastore.Queries) error {
+		// The account may have been deleted while the export was written.
+		if _, err := queries.GetUser(ctx, user.Email); err != nil {
+			return err
+		}
+
+		expiresAt := time.Now().Add(s.cfg.ExportLifetime).UTC().UnixMilli()
+		if err := queries.CreateToken(ctx, datastore.CreateTokenParams{
+			Token:     token,
+			Type:      tokenExport,
+			Email:     user.Email,
+			ExpiresAt: expiresAt,
+		}); err != nil {
+			return fmt.Errorf("failed to create the export token in the database: %w", err)
+		}
+
+		mail := mailer.NewMailExport(prefs.Locale, baseURL, user.Email, user.Name, token)
+		if err := outbox.Queue(ctx, queries, mail); err != nil {
+			return err
+		}
+
+		return nil
+	}); err != nil {
+		os.Remove(filename)
+		return err
+	}
+
+	s.mailer.Notify()
+
+	return nil
+}
+
+// GetExport returns the path of the export file identified by the token if it belongs to the email.
+func (s *Service) GetExport(
+	ctx context.Context,
+	email string,
+	token string,
+) (string, error) {
+	if err := s.db.Read(ctx, func(queries *datastore.Queries) error {
+		now := time.Now().UTC().UnixMilli()
+		if _, err := queries.GetExportTokenNotExpired(ctx, datastore.GetExportTokenNotExpiredParams{
+			Token:     token,
+			Email:     email,
+			ExpiresAt: now,
+		}); err != nil {
+			if storage.NoRows(err) {
+				return ErrExportNotFound
+			}
+
+			return err
+		}
+
+		return nil
+	}); err != nil {
+		return "", err
+	}
+
+	filename := s.exportFilename(token)
+	if _, err := os.Stat(filename); err != nil {
+		return "", errors.Join(err, ErrExportNotFound)
+	}
+
+	return filename, nil
+}
+
+// PurgeExpiredExports removes the export files older than the export lifetime.
+func (s *Service) PurgeExpiredExports(ctx context.Context) error {
+	entries, err := os.ReadDir(s.cfg.ExportsDir)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+
+		return err
+	}
+
+	deadline := time.Now().Add(-s.cfg.ExportLifetime)
+	for _, entry := range entries {
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+
+		if entry.IsDir() || info.ModTime().After(deadline) {
+			continue
+		}
+
+		if err := os.Remove(filepath.Join(s.cfg.ExportsDir, entry.Name())); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// removeExports removes the export files of the tokens.
+func (s *Service) removeExports(ctx context.Context, tokens []datastore.Token) {
+	for _, token := range tokens {
+		if token.Type != tokenExport {
+			continue
+		}
+
+		if err := os.Remove(s.exportFilename(token.Token)); err != nil && !errors.Is(err, os.ErrNotExist) {
+			s.log.Error(ctx, "export", "status", "failed to remove the export", "email", token.Email, "error", err)
+		}
+	}
+}
+
+func (s *Service) exportFilename(token string) string {
+	return filepath.Join(s.cfg.ExportsDir, token+".zip")
+}
+
+// exportData is the personal data of the user written to the export.
+type exportData struct {
+	user          datastore.User
+	prefs         Preferences
+	tokens        []datastore.Token
+	access        []datastore.AccessToken
+	bills         []datastore.Bill
+	payees        []datastore.Payee
+	aliases       []datastore.PayeeAlias
+	searches      []datastore.SavedSearch
+	notifications []datastore.Notification
+	emails        []datastore.Outbox
+	sessions      []ExportSession
+}
+
+func writeExport(filename string, data exportData) error {
+	tmp := filename + ".tmp"
+	f, err := os.OpenFile(tmp, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o600)
+	if err != nil {
+		return fmt.Errorf("failed to create the export file: %w", err)
+	}
+
+	zw := zip.NewWriter(f)
+	err = errors.Join(
+		writeExportJSON(zw, "account.json", exportAccount{
+			Email:      data.user.Email,
+			Name:       data.user.Name,
+			CreatedAt:  exportTime(data.user.CreatedAt),
+			UpdatedAt:  exportTime(data.user.UpdatedAt),
+			VerifiedAt: exportTime(data.user.VerifiedAt),
+			Admin:      data.user.IsAdmin,
+			DisabledAt: exportTime(data.user.DisabledAt),
+			DeletedAt:  exportTime(data.user.DeletedAt),
+		}),
+		writeExportJSON(zw, "preferences.json", exportPreferences{
+			Locale:         data.prefs.Locale,
+			Timezone:       data.prefs.Timezone,
+			Currency:       data.prefs.Currency,
+			FirstDayOfWeek: int(data.prefs.FirstDayOfWeek),
+			DateFormat:     data.prefs.DateFormat,
+		}),
+		writeExportCSV(zw, "tokens.csv", exportTokens(data.tokens)),
+		writeExportCSV(zw, "access_tokens.csv", exportAccessTokens(data.access)),
+		writeExportCSV(zw, "bills.csv", exportBills(data.bills)),
+		writeExportCSV(zw, "payees.csv", exportPayees(data.payees)),
+		writeExportCSV(zw, "payee_aliases.csv", exportPayeeAliases(data.payees, data.aliases)),
+		writeExportCSV(zw, "saved_searches.csv", exportSavedSearches(data.searches)),
+		writeExportCSV(zw, "notifications.csv", exportNotifications(data.notifications)),
+		writeExportCSV(zw, "emails.csv", exportEmails(data.emails)),
+		writeExportCSV(zw, "sessions.csv", exportSessions(data.sessions)),
+	)
+	err = errors.Join(err, zw.Close(), f.Close())
+	if err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("failed to write the export file: %w", err)
+	}
+
+	return os.Rename(tmp, filename)
+}
+
+func writeExportJSON(zw *zip.Writer, name string, v any) error {
+	w, err := zw.Create(name)
+	if err != nil {
+		return err
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+func writeExportCSV(zw *zip.Writer, name string, records [][]string) error {
+	w, err := zw.Create(name)
+	if err != nil {
+		return err
+	}
+
+	cw := csv.NewWriter(w)
+	if err := cw.WriteAll(records); err != nil {
+		return err
+	}
+
+	return cw.Error()
+}
+
+func exportTokens(tokens []datastore.Token) [][]string {
+	records := [][]string{{"type", "new_email", "expires_at", "deleted_at"}}
+	for _, t := range tokens {
+		// The token values are secrets so only their metadata is exported.
+		records = append(records, []string{t.Type, t.NewEmail, exportTime(t.ExpiresAt), exportTime(t.DeletedAt)})
+	}
+
+	return records
+}
+
+func exportAccessTokens(tokens []datastore.AccessToken) [][]string {
+	records := [][]string{{"name", "scope", "expires_at", "last_used_at", "created_at"}}
+	for _, t := range tokens {
+		// Only the hashes of the secrets are stored, and they are not exported.
+		records = append(records, []string{t.Name, t.Scope, exportTime(t.ExpiresAt), exportTime(t.LastUsedAt), exportTime(t.CreatedAt)})
+	}
+
+	return records
+}
+
+func exportBills(bills []datastore.Bill) [][]string {
+	records := [][]string{{"name", "amount", "currency", "due_on", "remind_days", "reminded_at", "paid_at", "created_at", "barcode", "pix"}}
+	for _, b := range bills {
+		records = append(records, []string{
+			b.Name,
+			money.FormatDecimal("", b.Amount, money.Exponent(b.Currency)),
+			b.Currency,
+			b.DueOn,
+			strconv.FormatInt(b.RemindDays, 10),
+			exportTime(b.RemindedAt),
+			exportTime(b.PaidAt),
+			exportTime(b.CreatedAt),
+			b.Barcode,
+			b.Pix,
+		})
+	}
+
+	return records
+}
+
+func exportPayees(payees []datastore.Payee) [][]string {
+	records := [][]string{{"name", "city", "tax_id", "created_at"}}
+	for _, p := range payees {
+		records = append(records, []string{p.Name, p.City, p.TaxID, exportTime(p.CreatedAt)})
+	}
+
+	return records
+}
+
+func exportPayeeAliases(payees []datastore.Payee, aliases []datastore.PayeeAlias) [][]string {
+	names := make(map[int64]string, len(payees))
+	for _, p := range payees {
+		names[p.ID] = p.Name
+	}
+
+	records := [][]string{{"payee", "kind", "value"}}
+	for _, a := range aliases {
+		records = append(records, []string{names[a.PayeeID], a.Kind, a.Value})
+	}
+
+	return records
+}
+
+func exportSavedSearches(searches []datastore.SavedSearch) [][]string {
+	records := [][]string{{"name", "query", "pinned", "created_at"}}
+	for _, s := range searches {
+		records = append(records, []string{s.Name, s.Query, strconv.FormatBool(s.Pinned), exportTime(s.CreatedAt)})
+	}
+
+	return records
+}
+
+func exportNotifications(notifications []datastore.Notification) [][]string {
+	records := [][]string{{"message", "link", "read_at", "created_at"}}
+	for _, n := range notifications {
+		records = append(records, []string{n.Message, n.Link, exportTime(n.ReadAt), exportTime(n.CreatedAt)})
+	}
+
+	return records
+}
+
+func exportEmails(emails []datastore.Outbox) [][]string {
+	records := [][]string{{"template", "subject", "recipient", "data", "attempts", "last_error", "created_at", "sent_at", "failed_at"}}
+	for _, e := range emails {
+		records = append(records, []string{
+			e.Template,
+			e.Subject,
+			e.Recipient,
+			exportEmailData(e.Data),
+			strconv.FormatInt(e.Attempts, 10),
+			e.LastError,
+			exportTime(e.CreatedAt),
+			exportTime(e.SentAt),
+			exportTime(e.FailedAt),
+		})
+	}
+
+	return records
+}
+
+// exportEmailData returns the data of the email without its link, which carries the secret of a
+// token like the ones left out of tokens.csv.
+func exportEmailData(data string) string {
+	var values map[string]string
+	if err := json.Unmarshal([]byte(data), &values); err != nil {
+		return ""
+	}
+	delete(values, "URL")
+
+	b, err := json.Marshal(values)
+	if err != nil {
+		return ""
+	}
+
+	return string(b)
+}
+
+func exportSessions(sessions []ExportSession) [][]string {
+	records := [][]string{{"user_agent", "ip", "created_at", "last_seen"}}
+	for _, s := range sessions {
+		records = append(records, []string{
+			s.UserAgent,
+			s.IP,
+			s.CreatedAt.Format(time.RFC3339),
+			s.LastSeen.Format(time.RFC3339),
+		})
+	}
+
+	return records
+}
+
+// exportTime formats the unix milliseconds timestamps stored in the database.
+func exportTime(ms int64) string {
+	if ms == 0 {
+		return ""
+	}
+
+	return time.UnixMilli(ms).UTC().Format(time.RFC3339)
+}
@@ -0,0 +1,202 @@
+package user
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+	_ "time/tzdata"
+
+	"github.com/garnizeH/dimdim/storage"
+	"github.com/garnizeH/dimdim/storage/datastore"
+)
+
+var (
+	ErrInvalidName        = errors.New("invalid name")
+	ErrInvalidPreferences = errors.New("invalid preferences")
+)
+
+var (
+	Locales     = []string{"pt-BR", "en"}
+	Currencies  = []string{"BRL", "USD", "EUR"}
+	DateFormats = []string{"DD/MM/YYYY", "MM/DD/YYYY", "YYYY-MM-DD"}
+)
+
+var dateLayouts = map[string]string{
+	"DD/MM/YYYY": "02/01/2006",
+	"MM/DD/YYYY": "01/02/2006",
+	"YYYY-MM-DD": "2006-01-02",
+}
+
+type Preferences struct {
+	Locale         string
+	Timezone       string
+	Currency       string
+	FirstDayOfWeek time.Weekday
+	DateFormat     string
+}
+
+func DefaultPreferences() Preferences {
+	return Preferences{
+		Locale:         "pt-BR",
+		Timezone:       "America/Sao_Paulo",
+		Currency:       "BRL",
+		FirstDayOfWeek: time.Sunday,
+		DateFormat:     "DD/MM/YYYY",
+	}
+}
+
+func (p Preferences) validate() error {
+	if !slices.Contains(Locales, p.Locale) ||
+		!slices.Contains(Currencies, p.Currency) ||
+		!slices.Contains(DateFormats, p.DateFormat) {
+		return ErrInvalidPreferences
+	}
+
+	if p.FirstDayOfWeek != time.Sunday && p.FirstDayOfWeek != time.Monday {
+		return ErrInvalidPreferences
+	}
+
+	if _, err := time.LoadLocation(p.Timezone); err != nil {
+		return ErrInvalidPreferences
+	}
+
+	return nil
+}
+
+// Location returns the time zone of the user, falling back to UTC for unknown zones.
+func (p Preferences) Location() *time.Location {
+	loc, err := time.LoadLocation(p.Timezone)
+	if err != nil {
+		return time.UTC
+	}
+
+	return loc
+}
+
+// DateLayout returns the go time layout of the user date format.
+func (p Preferences) DateLayout() string {
+	layout, ok := dateLayouts[p.DateFormat]
+	if !ok {
+		return dateLayouts[DefaultPreferences().DateFormat]
+	}
+
+	return layout
+}
+
+// FormatDate formats the date in the user time zone and date format.
+func (p Preferences) FormatDate(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+
+	return t.In(p.Location()).Format(p.DateLayout())
+}
+
+// FormatDateTime formats the date and time in the user time zone and date format.
+func (p Preferences) FormatDateTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+
+	return t.In(p.Location()).Format(p.DateLayout() + " 15:04")
+}
+
+func newPreferences(p datastore.UserPreference) Preferences {
+	return Preferences{
+		Locale:         p.Locale,
+		Timezone:       p.Timezone,
+		Currency:       p.Currency,
+		FirstDayOfWeek: time.Weekday(p.FirstDayOfWeek),
+		DateFormat:     p.DateFormat,
+	}
+}
+
+// GetPreferences returns the preferences of the user using the queries of the
+// caller transaction, falling back to the defaults when none were saved.
+func GetPreferences(ctx context.Context, queries *datastore.Queries, email string) (Preferences, error) {
+	prefs, err := queries.GetUserPreferences(ctx, email)
+	if err != nil {
+		if storage.NoRows(err) {
+			return DefaultPreferences(), nil
+		}
+
+		return Preferences{}, err
+	}
+
+	return newPreferences(prefs), nil
+}
+
+// SavePreferences validates and saves the preferences of the user using the
+// queries of the caller transaction. The cached user keeps the previous ones
+// until it is forgotten.
+func SavePreferences(ctx context.Context, queries *datastore.Queries, email string, prefs Preferences) error {
+	if err := prefs.validate(); err != nil {
+		return err
+	}
+
+	if _, err := queries.UpsertUserPreferences(ctx, datastore.UpsertUserPreferencesParams{
+		Email:          email,
+		Locale:         prefs.Locale,
+		Timezone:       prefs.Timezone,
+		Currency:       prefs.Currency,
+		FirstDayOfWeek: int64(prefs.FirstDayOfWeek),
+		DateFormat:     prefs.DateFormat,
+	}); err != nil {
+		return fmt.Errorf("failed to update the user preferences in the database: %w", err)
+	}
+
+	return nil
+}
+
+func (s *Service) UpdateProfile(
+	ctx context.Context,
+	email string,
+	name string,
+	prefs Preferences,
+) (User, error) {
+	if name == "" {
+		return User{}, ErrInvalidName
+	}
+	if err := prefs.validate(); err != nil {
+		return User{}, err
+	}
+
+	var (
+		u datastore.User
+		p datastore.UserPreference
+	)
+	if err := s.db.Write(ctx, func(queries *datastore.Queries) error {
+		var err error
+		u, err = queries.UpdateUserName(ctx, datastore.UpdateUserNameParams{
+			Name:  name,
+			Email: email,
+		})
+		if err != nil {
+			if storage.NoRows(err) {
+				return ErrEmailNotFound
+			}
+
+			return fmt.Errorf("failed to update the user name in the database: %w", err)
+		}
+
+		p, err = queries.UpsertUserPreferences(ctx, datastore.UpsertUserPreferencesParams{
+			Email:          email,
+			Locale:         prefs.Locale,
+			Timezone:       prefs.Timezone,
+			Currency:       prefs.Currency,
+			FirstDayOfWeek: int64(prefs.FirstDayOfWeek),
+			DateFormat:     prefs.DateFormat,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to update the user preferences in the database: %w", err)
+		}
+
+		return nil
+	}); err != nil {
+		return User{}, err
+	}
+
+	return s.updateCache(u, newPreferences(p)), nil
+}
@@ -0,0 +1,88 @@
+package user_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/garnizeH/dimdim/service/user"
+)
+
+func TestServiceUpdateProfile(t *testing.T) {
+	svc, db := newTestService(t, user.RegistrationOpen)
+	ctx := context.Background()
+	signup(t, svc, db, email)
+
+	valid := user.Preferences{
+		Locale:         "pt-BR",
+		Timezone:       "Europe/Lisbon",
+		Currency:       "EUR",
+		FirstDayOfWeek: time.Monday,
+		DateFormat:     "YYYY-MM-DD",
+	}
+	with := func(f func(p *user.Preferences)) user.Preferences {
+		p := valid
+		f(&p)
+		return p
+	}
+
+	tests := []struct {
+		name    string
+		newName string
+		prefs   user.Preferences
+		wantErr error
+	}{
+		{name: "empty name", newName: "", prefs: valid, wantErr: user.ErrInvalidName},
+		{name: "unknown locale", newName: "Someone", prefs: with(func(p *user.Preferences) { p.Locale = "fr" }), wantErr: user.ErrInvalidPreferences},
+		{name: "unknown time zone", newName: "Someone", prefs: with(func(p *user.Preferences) { p.Timezone = "Mars/Olympus" }), wantErr: user.ErrInvalidPreferences},
+		{name: "unknown currency", newName: "Someone", prefs: with(func(p *user.Preferences) { p.Currency = "JPY" }), wantErr: user.ErrInvalidPreferences},
+		{name: "week starting on friday", newName: "Someone", prefs: with(func(p *user.Preferences) { p.FirstDayOfWeek = time.Friday }), wantErr: user.ErrInvalidPreferences},
+		{name: "unknown date format", newName: "Someone", prefs: with(func(p *user.Preferences) { p.DateFormat = "YY/MM/DD" }), wantErr: user.ErrInvalidPreferences},
+		{name: "valid", newName: "Someone Else", prefs: valid},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			u, err := svc.UpdateProfile(ctx, email, tt.newName, tt.prefs)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Service.UpdateProfile() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+
+			if u.Name != tt.newName || u.Preferences != tt.prefs {
+				t.Errorf("Service.UpdateProfile() = %+v", u)
+			}
+			// The cache is updated with the saved profile.
+			if got, err := svc.GetUser(ctx, email); err != nil || got != u {
+				t.Errorf("Service.GetUser() = %+v, %v, want %+v", got, err, u)
+			}
+		})
+	}
+}
+
+func TestPreferencesFormatDate(t *testing.T) {
+	tm := time.Date(2026, time.March, 1, 2, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		name  string
+		prefs user.Preferences
+		want  string
+	}{
+		{name: "previous day in Sao Paulo", prefs: user.Preferences{Timezone: "America/Sao_Paulo", DateFormat: "DD/MM/YYYY"}, want: "28/02/2026 23:30"},
+		{name: "us format", prefs: user.Preferences{Timezone: "UTC", DateFormat: "MM/DD/YYYY"}, want: "03/01/2026 02:30"},
+		{name: "unknown zone and format", prefs: user.Preferences{Timezone: "Mars/Olympus", DateFormat: "?"}, want: "01/03/2026 02:30"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.prefs.FormatDateTime(tm); got != tt.want {
+				t.Errorf("Preferences.FormatDateTime() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	if got := user.DefaultPreferences().FormatDate(time.Time{}); got != "" {
+		t.Errorf("Preferences.FormatDate() of the zero time = %q, want empty", got)
+	}
+}
@@ -4,73 +4,139 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"slices"
 	"sync"
 	"time"
 
 	"github.com/garnizeH/dimdim/pkg/argon2id"
+	"github.com/garnizeH/dimdim/pkg/logger"
 	"github.com/garnizeH/dimdim/pkg/mailer"
+	"github.com/garnizeH/dimdim/service/outbox"
 	"github.com/garnizeH/dimdim/storage"
 	"github.com/garnizeH/dimdim/storage/datastore"
 	"github.com/google/uuid"
 )
 
 const (
-	tokenSignup   = "SIGNUP"
-	tokenPassword = "PASSWORD"
-
-	tokenDurationSignup   = time.Hour * 12
-	tokenDurationPassword = time.Hour * 1
+	tokenSignup      = "SIGNUP"
+	tokenPassword    = "PASSWORD"
+	tokenEmailChange = "EMAIL_CHANGE"
+	tokenEmailCancel = "EMAIL_CANCEL"
+
+	tokenDurationSignup      = time.Hour * 12
+	tokenDurationPassword    = time.Hour * 1
+	tokenDurationEmailChange = time.Hour * 12
+
+	// userCacheTTL bounds the time the changes made outside of the application,
+	// like the ones of the admin command, take to be seen.
+	userCacheTTL = time.Minute
 )
 
 var (
 	ErrInvalidCredentials  = errors.New("invalid credentials")
 	ErrEmailInUse          = errors.New("email already in use")
+	ErrEmailDeleted        = errors.New("email of a deleted account, try again later")
 	ErrUserNotVerified     = errors.New("email not verified")
 	ErrEmailNotFound       = errors.New("email not found")
 	ErrUserAlreadyVerified = errors.New("user already verified")
 	ErrInvalidToken        = errors.New("invalid token")
+	ErrRegistrationClosed  = errors.New("registration is closed")
+	ErrInvalidInvite       = errors.New("invalid invite code")
+	ErrUserDisabled        = errors.New("account disabled")
+)
+
+// Registration is the sign up policy of the instance.
+type Registration string
+
+const (
+	RegistrationOpen   Registration = "open"
+	RegistrationInvite Registration = "invite"
+	RegistrationClosed Registration = "closed"
 )
 
+func ParseRegistration(s string) (Registration, error) {
+	switch r := Registration(s); r {
+	case RegistrationOpen, RegistrationInvite, RegistrationClosed:
+		return r, nil
+	default:
+		return "", fmt.Errorf("invalid registration mode %q", s)
+	}
+}
+
+type Config struct {
+	Registration   Registration
+	ExportsDir     string
+	ExportLifetime time.Duration
+}
+
 type Service struct {
+	log       *logger.Logger
+	cfg       Config
 	argon     *argon2id.Argon2idHash
 	mailer    *mailer.Mailer
 	db        *storage.DB[datastore.Queries]
 	userCache *sync.Map
+
+	exports   chan exportJob
+	exporting *sync.Map
 }
 
 func New(
+	log *logger.Logger,
+	cfg Config,
 	argon *argon2id.Argon2idHash,
 	mailer *mailer.Mailer,
 	db *storage.DB[datastore.Queries],
 ) *Service {
 	return &Service{
+		log:       log,
+		cfg:       cfg,
 		argon:     argon,
 		mailer:    mailer,
 		db:        db,
 		userCache: &sync.Map{},
+		exports:   make(chan exportJob, maxQueuedExports),
+		exporting: &sync.Map{},
 	}
 }
 
 type User struct {
-	Name  string
-	Email string
+	Name        string
+	Email       string
+	Admin       bool
+	Disabled    bool
+	Preferences Preferences
+}
+
+func (s *Service) Registration() Registration {
+	return s.cfg.Registration
 }
 
 func (s *Service) GetUser(ctx context.Context, email string) (User, error) {
 	if v, ok := s.userCache.Load(email); ok {
-		return v.(User), nil
+		if cached := v.(cachedUser); time.Now().Before(cached.expiresAt) {
+			return cached.user, nil
+		}
 	}
 
-	var user datastore.User
+	var (
+		user  datastore.User
+		prefs Preferences
+	)
 	if err := s.db.Read(ctx, func(queries *datastore.Queries) error {
 		var err error
 		user, err = queries.GetUser(ctx, email)
+		if err != nil {
+			return err
+		}
+
+		prefs, err = GetPreferences(ctx, queries, email)
 		return err
 	}); err != nil {
 		return User{}, err
 	}
 
-	return s.updateCache(user), nil
+	return s.updateCache(user, prefs), nil
 }
 
 func (s *Service) Signin(
@@ -78,7 +144,10 @@ func (s *Service) Signin(
 	email string,
 	password string,
 ) (User, error) {
-	var user datastore.User
+	var (
+		user  datastore.User
+		prefs Preferences
+	)
 	if err := s.db.Read(ctx, func(queries *datastore.Queries) error {
 		var err error
 		user, err = queries.GetUser(ctx, email)
@@ -94,7 +163,8 @@ func (s *Service) Signin(
 			return ErrUserNotVerified
 		}
 
-		return nil
+		prefs, err = GetPreferences(ctx, queries, email)
+		return err
 	}); err != nil {
 		return User{}, err
 	}
@@ -103,7 +173,11 @@ func (s *Service) Signin(
 		return User{}, ErrInvalidCredentials
 	}
 
-	return s.updateCache(user), nil
+	if user.DisabledAt > 0 {
+		return User{}, ErrUserDisabled
+	}
+
+	return s.updateCache(user, prefs), nil
 }
 
 func (s *Service) Signup(
@@ -112,14 +186,36 @@ func (s *Service) Signup(
 	email string,
 	name string,
 	password string,
+	invite string,
+	locale string,
 ) error {
+	switch s.cfg.Registration {
+	case RegistrationOpen:
+	case RegistrationInvite:
+		if invite == "" {
+			return ErrInvalidInvite
+		}
+	default:
+		return ErrRegistrationClosed
+	}
+
 	if err := s.db.Write(ctx, func(queries *datastore.Queries) error {
-		_, err := queries.GetUser(ctx, email)
-		if err == nil {
-			return ErrEmailInUse
+		if err := checkEmailAvailable(ctx, queries, email); err != nil {
+			return err
 		}
-		if !storage.NoRows(err) {
-			return fmt.Errorf("failed to check for the email existence in the database: %w", err)
+
+		if s.cfg.Registration == RegistrationInvite {
+			used, err := queries.UseInviteToken(ctx, datastore.UseInviteTokenParams{
+				Token:     invite,
+				Email:     email,
+				ExpiresAt: time.Now().UTC().UnixMilli(),
+			})
+			if err != nil {
+				return fmt.Errorf("failed to use the invite token in the database: %w", err)
+			}
+			if used == 0 {
+				return ErrInvalidInvite
+			}
 		}
 
 		hashSalt, err := s.argon.GenerateHash([]byte(password), nil)
@@ -136,8 +232,23 @@ func (s *Service) Signup(
 			return fmt.Errorf("failed to create the user in the database: %w", err)
 		}
 
+		prefs := DefaultPreferences()
+		if slices.Contains(Locales, locale) {
+			prefs.Locale = locale
+		}
+		if _, err := queries.UpsertUserPreferences(ctx, datastore.UpsertUserPreferencesParams{
+			Email:          email,
+			Locale:         prefs.Locale,
+			Timezone:       prefs.Timezone,
+			Currency:       prefs.Currency,
+			FirstDayOfWeek: int64(prefs.FirstDayOfWeek),
+			DateFormat:     prefs.DateFormat,
+		}); err != nil {
+			return fmt.Errorf("failed to create the user preferences in the database: %w", err)
+		}
+
 		token := uuid.New().String()
-		mail := mailer.NewMailSignup(baseURL, email, name, token)
+		mail := mailer.NewMailSignup(prefs.Locale, baseURL, email, name, token)
 
 		if err := queries.DeleteSignupTokensByEmail(ctx, email); err != nil {
 			return fmt.Errorf("failed to delete existing signup tokens for the email %q in the database: %w", email, err)
@@ -153,8 +264,8 @@ func (s *Service) Signup(
 			return fmt.Errorf("failed to create the signup token in the database: %w", err)
 		}
 
-		if err := s.mailer.SendMailSignup(mail); err != nil {
-			return fmt.Errorf("failed to send the signup confirmation email: %w", err)
+		if err := outbox.Queue(ctx, queries, mail); err != nil {
+			return err
 		}
 
 		return nil
@@ -162,6 +273,8 @@ func (s *Service) Signup(
 		return err
 	}
 
+	s.mailer.Notify()
+
 	return nil
 }
 
@@ -183,8 +296,13 @@ func (s *Service) ResendSignupToken(
 			return ErrUserAlreadyVerified
 		}
 
+		prefs, err := GetPreferences(ctx, queries, email)
+		if err != nil {
+			return fmt.Errorf("failed to get the user preferences from the database: %w", err)
+		}
+
 		token := uuid.New().String()
-		mail := mailer.NewMailSignup(baseURL, email, user.Name, token)
+		mail := mailer.NewMailSignup(prefs.Locale, baseURL, email, user.Name, token)
 
 		if err := queries.DeleteSignupTokensByEmail(ctx, email); err != nil {
 			return fmt.Errorf("failed to delete existing signup tokens for the email %q in the database: %w", email, err)
@@ -200,8 +318,8 @@ func (s *Service) ResendSignupToken(
 			return fmt.Errorf("failed to create the signup token in the database: %w", err)
 		}
 
-		if err := s.mailer.SendMailSignup(mail); err != nil {
-			return fmt.Errorf("failed to send the signup confirmation email: %w", err)
+		if err := outbox.Queue(ctx, queries, mail); err != nil {
+			return err
 		}
 
 		return nil
@@ -209,6 +327,8 @@ func (s *Service) ResendSignupToken(
 		return err
 	}
 
+	s.mailer.Notify()
+
 	return nil
 }
 
@@ -240,7 +360,7 @@ func (s *Service) ValidateSignupToken(
 			return err
 		}
 
-		_ = s.updateCache(user)
+		s.userCache.Delete(user.Email)
 
 		return nil
 	}); err != nil {
@@ -268,8 +388,13 @@ func (s *Service) ResetPassword(
 			return ErrUserNotVerified
 		}
 
+		prefs, err := GetPreferences(ctx, queries, email)
+		if err != nil {
+			return fmt.Errorf("failed to get the user preferences from the database: %w", err)
+		}
+
 		token := uuid.New().String()
-		mail := mailer.NewMailPassword(baseURL, email, user.Name, token)
+		mail := mailer.NewMailPassword(prefs.Locale, baseURL, email, user.Name, token)
 
 		if err := queries.DeletePasswordTokensByEmail(ctx, email); err != nil {
 			return fmt.Errorf("failed to delete existing reset password tokens for the email %q in the database: %w", email, err)
@@ -285,8 +410,8 @@ func (s *Service) ResetPassword(
 			return fmt.Errorf("failed to create the reset password token in the database: %w", err)
 		}
 
-		if err := s.mailer.SendMailSignup(mail); err != nil {
-			return fmt.Errorf("failed to send the reset password email: %w", err)
+		if err := outbox.Queue(ctx, queries, mail); err != nil {
+			return err
 		}
 
 		return nil
@@ -294,6 +419,8 @@ func (s *Service) ResetPassword(
 		return err
 	}
 
+	s.mailer.Notify()
+
 	return nil
 }
 
@@ -356,7 +483,7 @@ func (s *Service) ChangePassword(
 			return err
 		}
 
-		_ = s.updateCache(user)
+		s.userCache.Delete(user.Email)
 
 		return nil
 	}); err != nil {
@@ -408,7 +535,7 @@ func (s *Service) ChangePasswordWithToken(
 			return err
 		}
 
-		_ = s.updateCache(user)
+		s.userCache.Delete(user.Email)
 
 		return nil
 	}); err != nil {
@@ -418,12 +545,366 @@ func (s *Service) ChangePasswordWithToken(
 	return user, nil
 }
 
-func (s *Service) updateCache(u datastore.User) User {
+type EmailChange struct {
+	OldEmail string
+	NewEmail string
+}
+
+func (s *Service) RequestEmailChange(
+	ctx context.Context,
+	baseURL string,
+	email string,
+	newEmail string,
+	password string,
+) error {
+	if err := s.db.Write(ctx, func(queries *datastore.Queries) error {
+		user, err := queries.GetUser(ctx, email)
+		if err != nil {
+			if storage.NoRows(err) {
+				return ErrEmailNotFound
+			}
+
+			return fmt.Errorf("failed to check for the email existence in the database: %w", err)
+		}
+
+		if err := s.argon.Compare(user.Password, user.Salt, []byte(password)); err != nil {
+			return ErrInvalidCredentials
+		}
+
+		if err := checkEmailAvailable(ctx, queries, newEmail); err != nil {
+			return err
+		}
+
+		if err := queries.DeleteEmailChangeTokensByEmail(ctx, email); err != nil {
+			return fmt.Errorf("failed to delete existing email change tokens for the email %q in the database: %w", email, err)
+		}
+
+		prefs, err := GetPreferences(ctx, queries, email)
+		if err != nil {
+			return fmt.Errorf("failed to get the user preferences from the database: %w", err)
+		}
+
+		token := uuid.New().String()
+		cancelToken := uuid.New().String()
+		expiresAt := time.Now().Add(tokenDurationEmailChange).UTC().UnixMilli()
+		if err := queries.CreateToken(ctx, datastore.CreateTokenParams{
+			Token:     token,
+			Type:      tokenEmailChange,
+			Email:     email,
+			NewEmail:  newEmail,
+			ExpiresAt: expiresAt,
+		}); err != nil {
+			return fmt.Errorf("failed to create the email change token in the database: %w", err)
+		}
+		if err := queries.CreateToken(ctx, datastore.CreateTokenParams{
+			Token:     cancelToken,
+			Type:      tokenEmailCancel,
+			Email:     email,
+			NewEmail:  newEmail,
+			ExpiresAt: expiresAt,
+		}); err != nil {
+			return fmt.Errorf("failed to create the email change cancel token in the database: %w", err)
+		}
+
+		mail := mailer.NewMailEmailChange(prefs.Locale, baseURL, newEmail, user.Name, token)
+		if err := outbox.Queue(ctx, queries, mail); err != nil {
+			return err
+		}
+
+		notice := mailer.NewMailEmailChangeNotice(prefs.Locale, baseURL, email, newEmail, user.Name, cancelToken)
+		if err := outbox.Queue(ctx, queries, notice); err != nil {
+			return err
+		}
+
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	s.mailer.Notify()
+
+	return nil
+}
+
+func (s *Service) ConfirmEmailChange(
+	ctx context.Context,
+	token string,
+) (EmailChange, error) {
+	var change EmailChange
+	if err := s.db.Write(ctx, func(queries *datastore.Queries) error {
+		now := time.Now().UTC().UnixMilli()
+		registeredToken, err := queries.GetEmailChangeTokenNotExpired(ctx, datastore.GetEmailChangeTokenNotExpiredParams{
+			Token:     token,
+			ExpiresAt: now,
+		})
+		if err != nil {
+			if storage.NoRows(err) {
+				return ErrInvalidToken
+			}
+
+			return err
+		}
+
+		user, err := queries.GetUser(ctx, registeredToken.Email)
+		if err != nil {
+			if storage.NoRows(err) {
+				return ErrEmailNotFound
+			}
+
+			return err
+		}
+
+		if err := checkEmailAvailable(ctx, queries, registeredToken.NewEmail); err != nil {
+			return err
+		}
+
+		if err := queries.DeleteEmailChangeTokensByEmail(ctx, registeredToken.Email); err != nil {
+			return err
+		}
+
+		if err := queries.UpdateUser(ctx, datastore.UpdateUserParams{
+			Email:   registeredToken.NewEmail,
+			Name:    user.Name,
+			Email_2: registeredToken.Email,
+		}); err != nil {
+			return fmt.Errorf("failed to update the user email in the database: %w", err)
+		}
+
+		if err := queries.UpdateTokensEmail(ctx, datastore.UpdateTokensEmailParams{
+			NewEmail: registeredToken.NewEmail,
+			OldEmail: registeredToken.Email,
+		}); err != nil {
+			return fmt.Errorf("failed to update the tokens email in the database: %w", err)
+		}
+
+		if err := queries.UpdateUserPreferencesEmail(ctx, datastore.UpdateUserPreferencesEmailParams{
+			NewEmail: registeredToken.NewEmail,
+			OldEmail: registeredToken.Email,
+		}); err != nil {
+			return fmt.Errorf("failed to update the user preferences email in the database: %w", err)
+		}
+
+		if err := queries.UpdateBillsEmail(ctx, datastore.UpdateBillsEmailParams{
+			NewEmail: registeredToken.NewEmail,
+			OldEmail: registeredToken.Email,
+		}); err != nil {
+			return fmt.Errorf("failed to update the bills email in the database: %w", err)
+		}
+
+		if err := queries.UpdateNotificationsEmail(ctx, datastore.UpdateNotificationsEmailParams{
+			NewEmail: registeredToken.NewEmail,
+			OldEmail: registeredToken.Email,
+		}); err != nil {
+			return fmt.Errorf("failed to update the notifications email in the database: %w", err)
+		}
+
+		if err := queries.UpdatePayeesEmail(ctx, datastore.UpdatePayeesEmailParams{
+			NewEmail: registeredToken.NewEmail,
+			OldEmail: registeredToken.Email,
+		}); err != nil {
+			return fmt.Errorf("failed to update the payees email in the database: %w", err)
+		}
+
+		if err := queries.UpdatePayeeAliasesEmail(ctx, datastore.UpdatePayeeAliasesEmailParams{
+			NewEmail: registeredToken.NewEmail,
+			OldEmail: registeredToken.Email,
+		}); err != nil {
+			return fmt.Errorf("failed to update the payee aliases email in the database: %w", err)
+		}
+
+		if err := queries.UpdateSavedSearchesEmail(ctx, datastore.UpdateSavedSearchesEmailParams{
+			NewEmail: registeredToken.NewEmail,
+			OldEmail: registeredToken.Email,
+		}); err != nil {
+			return fmt.Errorf("failed to update the saved searches email in the database: %w", err)
+		}
+
+		if err := queries.UpdateAccessTokensEmail(ctx, datastore.UpdateAccessTokensEmailParams{
+			NewEmail: registeredToken.NewEmail,
+			OldEmail: registeredToken.Email,
+		}); err != nil {
+			return fmt.Errorf("failed to update the access tokens email in the database: %w", err)
+		}
+
+		change = EmailChange{
+			OldEmail: registeredToken.Email,
+			NewEmail: registeredToken.NewEmail,
+		}
+
+		return nil
+	}); err != nil {
+		return EmailChange{}, err
+	}
+
+	s.userCache.Delete(change.OldEmail)
+
+	return change, nil
+}
+
+func (s *Service) CancelEmailChange(
+	ctx context.Context,
+	token string,
+) error {
+	if err := s.db.Write(ctx, func(queries *datastore.Queries) error {
+		now := time.Now().UTC().UnixMilli()
+		registeredToken, err := queries.GetEmailCancelTokenNotExpired(ctx, datastore.GetEmailCancelTokenNotExpiredParams{
+			Token:     token,
+			ExpiresAt: now,
+		})
+		if err != nil {
+			if storage.NoRows(err) {
+				return ErrInvalidToken
+			}
+
+			return err
+		}
+
+		return queries.DeleteEmailChangeTokensByEmail(ctx, registeredToken.Email)
+	}); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (s *Service) DeleteAccount(
+	ctx context.Context,
+	email string,
+	password string,
+) error {
+	var tokens []datastore.Token
+	if err := s.db.Write(ctx, func(queries *datastore.Queries) error {
+		user, err := queries.GetUser(ctx, email)
+		if err != nil {
+			if storage.NoRows(err) {
+				return ErrEmailNotFound
+			}
+
+			return err
+		}
+
+		if err := s.argon.Compare(user.Password, user.Salt, []byte(password)); err != nil {
+			return ErrInvalidCredentials
+		}
+
+		// The exports are removed with the account, the queued ones are
+		// dropped by RunExports once it finds the account deleted.
+		tokens, err = queries.ListTokensByEmail(ctx, email)
+		if err != nil {
+			return fmt.Errorf("failed to list the tokens of the email %q in the database: %w", email, err)
+		}
+
+		if err := queries.DeleteTokensByEmail(ctx, email); err != nil {
+			return fmt.Errorf("failed to delete the tokens of the email %q in the database: %w", email, err)
+		}
+
+		if err := queries.DeleteAccessTokensByEmail(ctx, email); err != nil {
+			return fmt.Errorf("failed to delete the access tokens of the email %q in the database: %w", email, err)
+		}
+
+		if err := queries.DeleteUser(ctx, email); err != nil {
+			return fmt.Errorf("failed to delete the user in the database: %w", err)
+		}
+
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	s.userCache.Delete(email)
+	s.removeExports(ctx, tokens)
+
+	return nil
+}
+
+// PurgeDeletedUsers permanently removes the users, and the data they own, deleted before the given time.
+func (s *Service) PurgeDeletedUsers(
+	ctx context.Context,
+	deletedBefore time.Time,
+) error {
+	return s.db.Write(ctx, func(queries *datastore.Queries) error {
+		deletedAt := deletedBefore.UTC().UnixMilli()
+		if err := queries.PurgeTokensOfDeletedUsers(ctx, deletedAt); err != nil {
+			return fmt.Errorf("failed to purge the tokens of deleted users in the database: %w", err)
+		}
+
+		if err := queries.PurgeUserPreferencesOfDeletedUsers(ctx, deletedAt); err != nil {
+			return fmt.Errorf("failed to purge the preferences of deleted users in the database: %w", err)
+		}
+
+		if err := queries.PurgeBillsOfDeletedUsers(ctx, deletedAt); err != nil {
+			return fmt.Errorf("failed to purge the bills of deleted users in the database: %w", err)
+		}
+
+		if err := queries.PurgeNotificationsOfDeletedUsers(ctx, deletedAt); err != nil {
+			return fmt.Errorf("failed to purge the notifications of deleted users in the database: %w", err)
+		}
+
+		if err := queries.PurgePayeesOfDeletedUsers(ctx, deletedAt); err != nil {
+			return fmt.Errorf("failed to purge the payees of deleted users in the database: %w", err)
+		}
+
+		if err := queries.PurgePayeeAliasesOfDeletedUsers(ctx, deletedAt); err != nil {
+			return fmt.Errorf("failed to purge the payee aliases of deleted users in the database: %w", err)
+		}
+
+		if err := queries.PurgeSavedSearchesOfDeletedUsers(ctx, deletedAt); err != nil {
+			return fmt.Errorf("failed to purge the saved searches of deleted users in the database: %w", err)
+		}
+
+		if err := queries.PurgeAccessTokensOfDeletedUsers(ctx, deletedAt); err != nil {
+			return fmt.Errorf("failed to purge the access tokens of deleted users in the database: %w", err)
+		}
+
+		if err := queries.PurgeDeletedUsers(ctx, deletedAt); err != nil {
+			return fmt.Errorf("failed to purge the deleted users in the database: %w", err)
+		}
+
+		return nil
+	})
+}
+
+// checkEmailAvailable returns an error if the email belongs to an account. The deleted accounts
+// keep their email until they are purged, after the grace period.
+func checkEmailAvailable(ctx context.Context, queries *datastore.Queries, email string) error {
+	if _, err := queries.GetUser(ctx, email); err == nil {
+		return ErrEmailInUse
+	} else if !storage.NoRows(err) {
+		return fmt.Errorf("failed to check for the email existence in the database: %w", err)
+	}
+
+	if _, err := queries.GetUserIsDeleted(ctx, email); err == nil {
+		return ErrEmailDeleted
+	} else if !storage.NoRows(err) {
+		return fmt.Errorf("failed to check for the deleted email existence in the database: %w", err)
+	}
+
+	return nil
+}
+
+// ForgetUser drops the user from the cache, so the next request reads it
+// again from the database.
+func (s *Service) ForgetUser(email string) {
+	s.userCache.Delete(email)
+}
+
+func (s *Service) updateCache(u datastore.User, prefs Preferences) User {
 	user := User{
-		Name:  u.Name,
-		Email: u.Email,
+		Name:        u.Name,
+		Email:       u.Email,
+		Admin:       u.IsAdmin,
+		Disabled:    u.DisabledAt > 0,
+		Preferences: prefs,
 	}
-	s.userCache.LoadOrStore(u.Email, user)
+	s.userCache.Store(u.Email, cachedUser{
+		user:      user,
+		expiresAt: time.Now().Add(userCacheTTL),
+	})
 
 	return user
 }
+
+type cachedUser struct {
+	user      User
+	expiresAt time.Time
+}
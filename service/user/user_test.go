@@ -0,0 +1,483 @@
+package user_test
+
+import (
+	"archive/zip"
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/garnizeH/dimdim/pkg/argon2id"
+	"github.com/garnizeH/dimdim/pkg/logger"
+	"github.com/garnizeH/dimdim/pkg/mailer"
+	"github.com/garnizeH/dimdim/service/invite"
+	"github.com/garnizeH/dimdim/service/user"
+	"github.com/garnizeH/dimdim/storage"
+	"github.com/garnizeH/dimdim/storage/datastore"
+)
+
+const (
+	baseURL  = "http://localhost:3000"
+	email    = "someone@example.com"
+	password = "password"
+)
+
+func newTestService(t *testing.T, registration user.Registration) (*user.Service, *storage.DB[datastore.Queries]) {
+	t.Helper()
+
+	db := storage.NewDBForTest(t, datastore.Migrations, datastore.Factory)
+	log := logger.New(io.Discard, logger.LevelError, "test", func(context.Context) string { return "" })
+	cfg := user.Config{
+		Registration:   registration,
+		ExportsDir:     t.TempDir(),
+		ExportLifetime: time.Hour,
+	}
+	// The cheapest hashing parameters, the tests do not need a strong hash.
+	argon := argon2id.New(1, 16, 64, 1, 32)
+
+	return user.New(log, cfg, argon, mailer.New("dimdim@example.com", mailer.NewMemoryTransport()), db), db
+}
+
+// lastToken returns the most recent token of the type sent to the email.
+func lastToken(t *testing.T, db *storage.DB[datastore.Queries], email, tokenType string) datastore.Token {
+	t.Helper()
+
+	var token datastore.Token
+	if err := db.Read(context.Background(), func(queries *datastore.Queries) error {
+		tokens, err := queries.ListTokensByEmail(context.Background(), email)
+		if err != nil {
+			return err
+		}
+
+		for _, tk := range tokens {
+			if tk.Type == tokenType {
+				token = tk
+			}
+		}
+
+		return nil
+	}); err != nil {
+		t.Fatalf("failed to list the tokens: %v", err)
+	}
+	if token.Token == "" {
+		t.Fatalf("no %s token for %q", tokenType, email)
+	}
+
+	return token
+}
+
+// signup creates the verified account of the email.
+func signup(t *testing.T, svc *user.Service, db *storage.DB[datastore.Queries], email string) {
+	t.Helper()
+
+	ctx := context.Background()
+	if err := svc.Signup(ctx, baseURL, email, "Someone", password, "", "en"); err != nil {
+		t.Fatalf("Service.Signup() error = %v", err)
+	}
+	if _, err := svc.ValidateSignupToken(ctx, lastToken(t, db, email, "SIGNUP").Token); err != nil {
+		t.Fatalf("Service.ValidateSignupToken() error = %v", err)
+	}
+}
+
+func TestServiceDeleteAccount(t *testing.T) {
+	svc, db := newTestService(t, user.RegistrationOpen)
+	ctx := context.Background()
+	signup(t, svc, db, email)
+
+	if err := svc.DeleteAccount(ctx, email, "wrong"); !errors.Is(err, user.ErrInvalidCredentials) {
+		t.Fatalf("Service.DeleteAccount() with a wrong password error = %v, want %v", err, user.ErrInvalidCredentials)
+	}
+	if err := svc.DeleteAccount(ctx, email, password); err != nil {
+		t.Fatalf("Service.DeleteAccount() error = %v", err)
+	}
+
+	if _, err := svc.GetUser(ctx, email); !storage.NoRows(err) {
+		t.Errorf("Service.GetUser() of a deleted account error = %v, want no rows", err)
+	}
+	if _, err := svc.Signin(ctx, email, password); !errors.Is(err, user.ErrInvalidCredentials) {
+		t.Errorf("Service.Signin() of a deleted account error = %v, want %v", err, user.ErrInvalidCredentials)
+	}
+
+	// The deleted account keeps its email until it is purged.
+	if err := svc.Signup(ctx, baseURL, email, "Someone", password, "", "en"); !errors.Is(err, user.ErrEmailDeleted) {
+		t.Errorf("Service.Signup() with the email of a deleted account error = %v, want %v", err, user.ErrEmailDeleted)
+	}
+
+	if err := svc.PurgeDeletedUsers(ctx, time.Now().Add(-time.Hour)); err != nil {
+		t.Fatalf("Service.PurgeDeletedUsers() error = %v", err)
+	}
+	if err := svc.Signup(ctx, baseURL, email, "Someone", password, "", "en"); !errors.Is(err, user.ErrEmailDeleted) {
+		t.Errorf("Service.Signup() within the grace period error = %v, want %v", err, user.ErrEmailDeleted)
+	}
+
+	if err := svc.PurgeDeletedUsers(ctx, time.Now().Add(time.Second)); err != nil {
+		t.Fatalf("Service.PurgeDeletedUsers() error = %v", err)
+	}
+	if err := svc.Signup(ctx, baseURL, email, "Someone", password, "", "en"); err != nil {
+		t.Errorf("Service.Signup() after the purge error = %v", err)
+	}
+}
+
+func TestServiceEmailChangeToDeletedAccount(t *testing.T) {
+	svc, db := newTestService(t, user.RegistrationOpen)
+	ctx := context.Background()
+	const (
+		deleted = "deleted@example.com"
+		later   = "later@example.com"
+	)
+	signup(t, svc, db, email)
+	signup(t, svc, db, deleted)
+
+	if err := svc.DeleteAccount(ctx, deleted, password); err != nil {
+		t.Fatalf("Service.DeleteAccount() error = %v", err)
+	}
+	if err := svc.RequestEmailChange(ctx, baseURL, email, deleted, password); !errors.Is(err, user.ErrEmailDeleted) {
+		t.Errorf("Service.RequestEmailChange() to a deleted account error = %v, want %v", err, user.ErrEmailDeleted)
+	}
+
+	// The address was taken and deleted after the change was requested.
+	if err := svc.RequestEmailChange(ctx, baseURL, email, later, password); err != nil {
+		t.Fatalf("Service.RequestEmailChange() error = %v", err)
+	}
+	signup(t, svc, db, later)
+	if err := svc.DeleteAccount(ctx, later, password); err != nil {
+		t.Fatalf("Service.DeleteAccount() error = %v", err)
+	}
+	if _, err := svc.ConfirmEmailChange(ctx, lastToken(t, db, email, "EMAIL_CHANGE").Token); !errors.Is(err, user.ErrEmailDeleted) {
+		t.Errorf("Service.ConfirmEmailChange() to a deleted account error = %v, want %v", err, user.ErrEmailDeleted)
+	}
+}
+
+func TestServiceRequestExport(t *testing.T) {
+	svc, db := newTestService(t, user.RegistrationOpen)
+	ctx := context.Background()
+	signup(t, svc, db, email)
+
+	// The export of an account deleted while it is queued is dropped.
+	const other = "other@example.com"
+	signup(t, svc, db, other)
+	if err := svc.RequestExport(ctx, baseURL, other, nil); err != nil {
+		t.Fatalf("Service.RequestExport() error = %v", err)
+	}
+	if err := svc.DeleteAccount(ctx, other, password); err != nil {
+		t.Fatalf("Service.DeleteAccount() error = %v", err)
+	}
+
+	if err := svc.RequestExport(ctx, baseURL, email, nil); err != nil {
+		t.Fatalf("Service.RequestExport() error = %v", err)
+	}
+	if err := svc.RequestExport(ctx, baseURL, email, nil); !errors.Is(err, user.ErrExportPending) {
+		t.Fatalf("Service.RequestExport() while pending error = %v, want %v", err, user.ErrExportPending)
+	}
+
+	jobsCtx, cancel := context.WithCancel(ctx)
+	done := make(chan struct{})
+	go func() {
+		svc.RunExports(jobsCtx)
+		close(done)
+	}()
+	defer func() {
+		cancel()
+		<-done
+	}()
+
+	// The export is ready once its token is created.
+	var token datastore.Token
+	for range 100 {
+		if err := db.Read(ctx, func(queries *datastore.Queries) error {
+			tokens, err := queries.ListTokensByEmail(ctx, email)
+			for _, tk := range tokens {
+				if tk.Type == "EXPORT" {
+					token = tk
+				}
+			}
+			return err
+		}); err != nil {
+			t.Fatalf("failed to list the tokens: %v", err)
+		}
+		if token.Token != "" {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if token.Token == "" {
+		t.Fatal("the export was not generated")
+	}
+
+	filename, err := svc.GetExport(ctx, email, token.Token)
+	if err != nil {
+		t.Fatalf("Service.GetExport() error = %v", err)
+	}
+
+	zr, err := zip.OpenReader(filename)
+	if err != nil {
+		t.Fatalf("failed to open the export: %v", err)
+	}
+	defer zr.Close()
+
+	files := map[string]string{}
+	for _, f := range zr.File {
+		rc, err := f.Open()
+		if err != nil {
+			t.Fatalf("failed to open %s: %v", f.Name, err)
+		}
+		b, err := io.ReadAll(rc)
+		rc.Close()
+		if err != nil {
+			t.Fatalf("failed to read %s: %v", f.Name, err)
+		}
+		files[f.Name] = string(b)
+	}
+
+	for _, name := range []string{"account.json", "preferences.json", "tokens.csv", "access_tokens.csv", "bills.csv", "payees.csv", "payee_aliases.csv", "saved_searches.csv", "notifications.csv", "emails.csv", "sessions.csv"} {
+		if _, ok := files[name]; !ok {
+			t.Errorf("the export has no %s", name)
+		}
+	}
+	if !strings.Contains(files["account.json"], `"admin": false`) {
+		t.Errorf("account.json = %s, want the admin flag", files["account.json"])
+	}
+	// The week starts on Sunday, numbered as in the backups.
+	if !strings.Contains(files["preferences.json"], `"first_day_of_week": 0`) {
+		t.Errorf("preferences.json = %s, want the first day of week as a number", files["preferences.json"])
+	}
+	// The signup email is exported without the link to its token.
+	if emails := files["emails.csv"]; !strings.Contains(emails, "signup") || strings.Contains(emails, "/auth/signup/") {
+		t.Errorf("emails.csv = %s, want the signup email without its link", emails)
+	}
+	if _, err := svc.GetExport(ctx, other, token.Token); !errors.Is(err, user.ErrExportNotFound) {
+		t.Errorf("Service.GetExport() of another user error = %v, want %v", err, user.ErrExportNotFound)
+	}
+
+	// The jobs run in order, the one of the deleted account before this one.
+	if exports, err := os.ReadDir(filepath.Dir(filename)); err != nil || len(exports) != 1 {
+		t.Errorf("the exports directory has %d files, %v, want only the export of %s", len(exports), err, email)
+	}
+
+	// The export is removed with the account.
+	if err := svc.DeleteAccount(ctx, email, password); err != nil {
+		t.Fatalf("Service.DeleteAccount() error = %v", err)
+	}
+	if _, err := os.Stat(filename); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("the export of a deleted account error = %v, want %v", err, os.ErrNotExist)
+	}
+}
+
+func TestServiceEmailChange(t *testing.T) {
+	svc, db := newTestService(t, user.RegistrationOpen)
+	ctx := context.Background()
+	const (
+		other    = "other@example.com"
+		newEmail = "new@example.com"
+	)
+	signup(t, svc, db, email)
+	signup(t, svc, db, other)
+
+	// The user is cached with the old email.
+	u, err := svc.GetUser(ctx, email)
+	if err != nil {
+		t.Fatalf("Service.GetUser() error = %v", err)
+	}
+	prefs := u.Preferences
+	prefs.Currency = "EUR"
+	if _, err := svc.UpdateProfile(ctx, email, u.Name, prefs); err != nil {
+		t.Fatalf("Service.UpdateProfile() error = %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		newEmail string
+		password string
+		wantErr  error
+	}{
+		{name: "wrong password", newEmail: newEmail, password: "wrong", wantErr: user.ErrInvalidCredentials},
+		{name: "email in use", newEmail: other, password: password, wantErr: user.ErrEmailInUse},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := svc.RequestEmailChange(ctx, baseURL, email, tt.newEmail, tt.password); !errors.Is(err, tt.wantErr) {
+				t.Errorf("Service.RequestEmailChange() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	// A cancelled change cannot be confirmed.
+	if err := svc.RequestEmailChange(ctx, baseURL, email, newEmail, password); err != nil {
+		t.Fatalf("Service.RequestEmailChange() error = %v", err)
+	}
+	confirm := lastToken(t, db, email, "EMAIL_CHANGE").Token
+	if err := svc.CancelEmailChange(ctx, lastToken(t, db, email, "EMAIL_CANCEL").Token); err != nil {
+		t.Fatalf("Service.CancelEmailChange() error = %v", err)
+	}
+	if _, err := svc.ConfirmEmailChange(ctx, confirm); !errors.Is(err, user.ErrInvalidToken) {
+		t.Errorf("Service.ConfirmEmailChange() of a cancelled change error = %v, want %v", err, user.ErrInvalidToken)
+	}
+
+	if err := svc.RequestEmailChange(ctx, baseURL, email, newEmail, password); err != nil {
+		t.Fatalf("Service.RequestEmailChange() error = %v", err)
+	}
+	change, err := svc.ConfirmEmailChange(ctx, lastToken(t, db, email, "EMAIL_CHANGE").Token)
+	if err != nil {
+		t.Fatalf("Service.ConfirmEmailChange() error = %v", err)
+	}
+	if change.OldEmail != email || change.NewEmail != newEmail {
+		t.Errorf("Service.ConfirmEmailChange() = %+v", change)
+	}
+
+	if _, err := svc.GetUser(ctx, email); !storage.NoRows(err) {
+		t.Errorf("Service.GetUser() of the old email error = %v, want no rows", err)
+	}
+	u, err = svc.GetUser(ctx, newEmail)
+	if err != nil {
+		t.Fatalf("Service.GetUser() of the new email error = %v", err)
+	}
+	if u.Preferences != prefs {
+		t.Errorf("the preferences were not moved to the new email: %+v", u.Preferences)
+	}
+	if _, err := svc.Signin(ctx, newEmail, password); err != nil {
+		t.Errorf("Service.Signin() with the new email error = %v", err)
+	}
+}
+
+func TestServiceSignupRegistration(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("closed", func(t *testing.T) {
+		svc, _ := newTestService(t, user.RegistrationClosed)
+		if err := svc.Signup(ctx, baseURL, email, "Someone", password, "", "en"); !errors.Is(err, user.ErrRegistrationClosed) {
+			t.Errorf("Service.Signup() error = %v, want %v", err, user.ErrRegistrationClosed)
+		}
+	})
+
+	t.Run("invite", func(t *testing.T) {
+		svc, db := newTestService(t, user.RegistrationInvite)
+		invites := invite.New(db)
+
+		open, err := invites.CreateInvite(ctx, "", time.Hour)
+		if err != nil {
+			t.Fatalf("Service.CreateInvite() error = %v", err)
+		}
+		bound, err := invites.CreateInvite(ctx, "bound@example.com", time.Hour)
+		if err != nil {
+			t.Fatalf("Service.CreateInvite() error = %v", err)
+		}
+
+		tests := []struct {
+			name    string
+			email   string
+			invite  string
+			wantErr error
+		}{
+			{name: "no invite", email: email, wantErr: user.ErrInvalidInvite},
+			{name: "unknown invite", email: email, invite: "unknown", wantErr: user.ErrInvalidInvite},
+			{name: "invite of another email", email: email, invite: bound.Code, wantErr: user.ErrInvalidInvite},
+			{name: "open invite", email: email, invite: open.Code},
+			{name: "used invite", email: "other@example.com", invite: open.Code, wantErr: user.ErrInvalidInvite},
+			{name: "bound invite", email: "bound@example.com", invite: bound.Code},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				if err := svc.Signup(ctx, baseURL, tt.email, "Someone", password, tt.invite, "en"); !errors.Is(err, tt.wantErr) {
+					t.Errorf("Service.Signup() error = %v, want %v", err, tt.wantErr)
+				}
+			})
+		}
+	})
+
+	t.Run("open", func(t *testing.T) {
+		svc, db := newTestService(t, user.RegistrationOpen)
+		if err := svc.Signup(ctx, baseURL, email, "Someone", password, "", "pt-BR"); err != nil {
+			t.Fatalf("Service.Signup() error = %v", err)
+		}
+		if _, err := svc.Signin(ctx, email, password); !errors.Is(err, user.ErrUserNotVerified) {
+			t.Errorf("Service.Signin() before the verification error = %v, want %v", err, user.ErrUserNotVerified)
+		}
+		if err := svc.Signup(ctx, baseURL, email, "Someone", password, "", "en"); !errors.Is(err, user.ErrEmailInUse) {
+			t.Errorf("Service.Signup() with the same email error = %v, want %v", err, user.ErrEmailInUse)
+		}
+
+		if _, err := svc.ValidateSignupToken(ctx, lastToken(t, db, email, "SIGNUP").Token); err != nil {
+			t.Fatalf("Service.ValidateSignupToken() error = %v", err)
+		}
+		u, err := svc.Signin(ctx, email, password)
+		if err != nil {
+			t.Fatalf("Service.Signin() error = %v", err)
+		}
+		if u.Preferences.Locale != "pt-BR" {
+			t.Errorf("Service.Signin() locale = %q, want the one of the signup", u.Preferences.Locale)
+		}
+	})
+}
+
+func TestParseRegistration(t *testing.T) {
+	for _, s := range []string{"open", "invite", "closed"} {
+		if r, err := user.ParseRegistration(s); err != nil || string(r) != s {
+			t.Errorf("ParseRegistration(%q) = %q, %v", s, r, err)
+		}
+	}
+	if _, err := user.ParseRegistration("public"); err == nil {
+		t.Error("ParseRegistration(\"public\") did not fail")
+	}
+}
+
+func TestServiceAdmin(t *testing.T) {
+	svc, db := newTestService(t, user.RegistrationOpen)
+	ctx := context.Background()
+	signup(t, svc, db, email)
+
+	if err := svc.SetAdmin(ctx, email, true); err != nil {
+		t.Fatalf("Service.SetAdmin() error = %v", err)
+	}
+	if err := svc.SetAdmin(ctx, "other@example.com", true); !errors.Is(err, user.ErrEmailNotFound) {
+		t.Errorf("Service.SetAdmin() of an unknown user error = %v, want %v", err, user.ErrEmailNotFound)
+	}
+	if u, err := svc.GetUser(ctx, email); err != nil || !u.Admin {
+		t.Fatalf("Service.GetUser() = %+v, %v, want an admin", u, err)
+	}
+
+	// The admin command demotes the user in the database, behind the cache.
+	if err := db.Write(ctx, func(queries *datastore.Queries) error {
+		_, err := queries.SetUserIsAdmin(ctx, datastore.SetUserIsAdminParams{IsAdmin: false, Email: email})
+		return err
+	}); err != nil {
+		t.Fatalf("failed to demote the user: %v", err)
+	}
+	if admin, err := svc.IsAdmin(ctx, email); err != nil || admin {
+		t.Errorf("Service.IsAdmin() of a demoted user = %v, %v, want false", admin, err)
+	}
+
+	if err := svc.SetAdmin(ctx, email, true); err != nil {
+		t.Fatalf("Service.SetAdmin() error = %v", err)
+	}
+	if err := svc.SetDisabled(ctx, email, true); err != nil {
+		t.Fatalf("Service.SetDisabled() error = %v", err)
+	}
+	if admin, err := svc.IsAdmin(ctx, email); err != nil || admin {
+		t.Errorf("Service.IsAdmin() of a disabled admin = %v, %v, want false", admin, err)
+	}
+	if u, err := svc.GetUser(ctx, email); err != nil || !u.Disabled {
+		t.Errorf("Service.GetUser() = %+v, %v, want a disabled user", u, err)
+	}
+	if _, err := svc.Signin(ctx, email, password); !errors.Is(err, user.ErrUserDisabled) {
+		t.Errorf("Service.Signin() of a disabled user error = %v, want %v", err, user.ErrUserDisabled)
+	}
+
+	if err := svc.SetDisabled(ctx, email, false); err != nil {
+		t.Fatalf("Service.SetDisabled() error = %v", err)
+	}
+	if admin, err := svc.IsAdmin(ctx, email); err != nil || !admin {
+		t.Errorf("Service.IsAdmin() of an enabled admin = %v, %v, want true", admin, err)
+	}
+	if _, err := svc.Signin(ctx, email, password); err != nil {
+		t.Errorf("Service.Signin() of an enabled user error = %v", err)
+	}
+
+	accounts, err := svc.ListAccounts(ctx, "someone")
+	if err != nil || len(accounts) != 1 || !accounts[0].Admin {
+		t.Errorf("Service.ListAccounts() = %+v, %v", accounts, err)
+	}
+}
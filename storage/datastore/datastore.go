@@ -1,7 +1,10 @@
 package datastore
 
 import (
+	"context"
 	"embed"
+	"testing"
+	"time"
 
 	"github.com/garnizeH/dimdim/storage"
 )
@@ -12,3 +15,41 @@ var Migrations embed.FS
 func Factory(tx storage.DBTX) *Queries {
 	return New(tx)
 }
+
+// NewDBForTest returns a migrated test database with the users of the emails,
+// in English, reais and the time zone of São Paulo.
+func NewDBForTest(t *testing.T, emails ...string) *storage.DB[Queries] {
+	t.Helper()
+
+	db := storage.NewDBForTest(t, Migrations, Factory)
+	if err := db.Write(context.Background(), func(queries *Queries) error {
+		ctx := context.Background()
+		for _, email := range emails {
+			if err := queries.CreateUser(ctx, CreateUserParams{
+				Email:    email,
+				Name:     "Someone",
+				Password: []byte("password"),
+				Salt:     []byte("salt"),
+			}); err != nil {
+				return err
+			}
+
+			if _, err := queries.UpsertUserPreferences(ctx, UpsertUserPreferencesParams{
+				Email:          email,
+				Locale:         "en",
+				Timezone:       "America/Sao_Paulo",
+				Currency:       "BRL",
+				FirstDayOfWeek: int64(time.Sunday),
+				DateFormat:     "YYYY-MM-DD",
+			}); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}); err != nil {
+		t.Fatalf("failed to create the users: %v", err)
+	}
+
+	return db
+}
@@ -0,0 +1,8 @@
+//go:build !sqlite_fts5
+
+package datastore
+
+// The search of the bills is an FTS5 table, which mattn/go-sqlite3 only builds
+// with the sqlite_fts5 tag. Without the tag the migrations would fail at
+// startup, so the build fails here instead: build with -tags sqlite_fts5.
+var _ = requiresBuildTagSqliteFTS5
@@ -4,6 +4,88 @@
 
 package datastore
 
+type AccessToken struct {
+	ID         int64
+	Email      string
+	Name       string
+	Hash       string
+	Scope      string
+	ExpiresAt  int64
+	LastUsedAt int64
+	CreatedAt  int64
+}
+
+type Bill struct {
+	ID         int64
+	Email      string
+	Name       string
+	Amount     int64
+	Currency   string
+	DueOn      string
+	RemindDays int64
+	RemindedAt int64
+	PaidAt     int64
+	CreatedAt  int64
+	UpdatedAt  int64
+	DeletedAt  int64
+	Barcode    string
+	Pix        string
+	PayeeID    int64
+}
+
+type Notification struct {
+	ID        int64
+	Email     string
+	Message   string
+	Link      string
+	ReadAt    int64
+	CreatedAt int64
+}
+
+type Outbox struct {
+	ID            int64
+	Template      string
+	Subject       string
+	Recipient     string
+	Data          string
+	Attempts      int64
+	LastError     string
+	NextAttemptAt int64
+	SentAt        int64
+	FailedAt      int64
+	CreatedAt     int64
+	UpdatedAt     int64
+}
+
+type Payee struct {
+	ID        int64
+	Email     string
+	Name      string
+	City      string
+	CreatedAt int64
+	UpdatedAt int64
+	DeletedAt int64
+	TaxID     string
+}
+
+type PayeeAlias struct {
+	ID        int64
+	Email     string
+	PayeeID   int64
+	Kind      string
+	Value     string
+	CreatedAt int64
+}
+
+type SavedSearch struct {
+	ID        int64
+	Email     string
+	Name      string
+	Query     string
+	Pinned    bool
+	CreatedAt int64
+}
+
 type Tag struct {
 	ID        int64
 	Name      string
@@ -18,6 +100,7 @@ type Token struct {
 	Email     string
 	ExpiresAt int64
 	DeletedAt int64
+	NewEmail  string
 }
 
 type User struct {
@@ -29,4 +112,17 @@ type User struct {
 	UpdatedAt  int64
 	VerifiedAt int64
 	DeletedAt  int64
+	IsAdmin    bool
+	DisabledAt int64
+}
+
+type UserPreference struct {
+	Email          string
+	Locale         string
+	Timezone       string
+	Currency       string
+	FirstDayOfWeek int64
+	DateFormat     string
+	CreatedAt      int64
+	UpdatedAt      int64
 }
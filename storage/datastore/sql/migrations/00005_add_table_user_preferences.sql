@@ -0,0 +1,18 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS user_preferences (
+  email              TEXT    NOT NULL PRIMARY KEY,
+  locale             TEXT    NOT NULL,
+  timezone           TEXT    NOT NULL,
+  currency           TEXT    NOT NULL,
+  first_day_of_week  INTEGER NOT NULL,
+  date_format        TEXT    NOT NULL,
+  created_at         INTEGER NOT NULL DEFAULT (unixepoch('subsecond') * 1000),
+  updated_at         INTEGER NOT NULL DEFAULT (unixepoch('subsecond') * 1000)
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS user_preferences;
+-- +goose StatementEnd
@@ -0,0 +1,25 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS outbox (
+  id               INTEGER NOT NULL PRIMARY KEY AUTOINCREMENT,
+  template         TEXT    NOT NULL,
+  subject          TEXT    NOT NULL,
+  recipient        TEXT    NOT NULL,
+  data             TEXT    NOT NULL,
+  attempts         INTEGER NOT NULL DEFAULT 0,
+  last_error       TEXT    NOT NULL DEFAULT '',
+  next_attempt_at  INTEGER NOT NULL,
+  sent_at          INTEGER NOT NULL DEFAULT 0,
+  failed_at        INTEGER NOT NULL DEFAULT 0,
+  created_at       INTEGER NOT NULL DEFAULT (unixepoch('subsecond') * 1000),
+  updated_at       INTEGER NOT NULL DEFAULT (unixepoch('subsecond') * 1000)
+);
+
+CREATE INDEX IF NOT EXISTS idx_outbox_pending ON outbox (sent_at, failed_at, next_attempt_at);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_outbox_pending;
+DROP TABLE IF EXISTS outbox;
+-- +goose StatementEnd
@@ -0,0 +1,40 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS bills (
+  id           INTEGER NOT NULL PRIMARY KEY AUTOINCREMENT,
+  email        TEXT    NOT NULL,
+  name         TEXT    NOT NULL,
+  amount       INTEGER NOT NULL,
+  currency     TEXT    NOT NULL,
+  due_on       TEXT    NOT NULL,
+  remind_days  INTEGER NOT NULL DEFAULT 3,
+  reminded_at  INTEGER NOT NULL DEFAULT 0,
+  paid_at      INTEGER NOT NULL DEFAULT 0,
+  created_at   INTEGER NOT NULL DEFAULT (unixepoch('subsecond') * 1000),
+  updated_at   INTEGER NOT NULL DEFAULT (unixepoch('subsecond') * 1000),
+  deleted_at   INTEGER NOT NULL DEFAULT 0
+);
+
+CREATE INDEX IF NOT EXISTS idx_bills_email ON bills (email, due_on);
+CREATE INDEX IF NOT EXISTS idx_bills_reminders ON bills (paid_at, reminded_at, due_on);
+
+CREATE TABLE IF NOT EXISTS notifications (
+  id          INTEGER NOT NULL PRIMARY KEY AUTOINCREMENT,
+  email       TEXT    NOT NULL,
+  message     TEXT    NOT NULL,
+  link        TEXT    NOT NULL DEFAULT '',
+  read_at     INTEGER NOT NULL DEFAULT 0,
+  created_at  INTEGER NOT NULL DEFAULT (unixepoch('subsecond') * 1000)
+);
+
+CREATE INDEX IF NOT EXISTS idx_notifications_email ON notifications (email, read_at);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_notifications_email;
+DROP TABLE IF EXISTS notifications;
+DROP INDEX IF EXISTS idx_bills_reminders;
+DROP INDEX IF EXISTS idx_bills_email;
+DROP TABLE IF EXISTS bills;
+-- +goose StatementEnd
@@ -0,0 +1,28 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS payees (
+  id          INTEGER NOT NULL PRIMARY KEY AUTOINCREMENT,
+  email       TEXT    NOT NULL,
+  name        TEXT    NOT NULL,
+  city        TEXT    NOT NULL DEFAULT '',
+  pix_key     TEXT    NOT NULL DEFAULT '',
+  created_at  INTEGER NOT NULL DEFAULT (unixepoch('subsecond') * 1000),
+  updated_at  INTEGER NOT NULL DEFAULT (unixepoch('subsecond') * 1000),
+  deleted_at  INTEGER NOT NULL DEFAULT 0
+);
+
+CREATE INDEX IF NOT EXISTS idx_payees_email ON payees (email, name);
+CREATE INDEX IF NOT EXISTS idx_payees_pix_key ON payees (email, pix_key);
+
+ALTER TABLE bills ADD COLUMN pix TEXT NOT NULL DEFAULT '';
+ALTER TABLE bills ADD COLUMN payee_id INTEGER NOT NULL DEFAULT 0;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE bills DROP COLUMN payee_id;
+ALTER TABLE bills DROP COLUMN pix;
+DROP INDEX IF EXISTS idx_payees_pix_key;
+DROP INDEX IF EXISTS idx_payees_email;
+DROP TABLE IF EXISTS payees;
+-- +goose StatementEnd
@@ -0,0 +1,40 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS payee_aliases (
+  id          INTEGER NOT NULL PRIMARY KEY AUTOINCREMENT,
+  email       TEXT    NOT NULL,
+  payee_id    INTEGER NOT NULL,
+  kind        TEXT    NOT NULL,
+  value       TEXT    NOT NULL,
+  created_at  INTEGER NOT NULL DEFAULT (unixepoch('subsecond') * 1000)
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_payee_aliases_value ON payee_aliases (email, kind, value);
+CREATE INDEX IF NOT EXISTS idx_payee_aliases_payee ON payee_aliases (payee_id);
+
+INSERT INTO payee_aliases (email, payee_id, kind, value)
+SELECT email, id, 'pix', pix_key FROM payees
+WHERE pix_key != '' AND deleted_at = 0;
+
+DROP INDEX IF EXISTS idx_payees_pix_key;
+ALTER TABLE payees DROP COLUMN pix_key;
+ALTER TABLE payees ADD COLUMN tax_id TEXT NOT NULL DEFAULT '';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE payees DROP COLUMN tax_id;
+ALTER TABLE payees ADD COLUMN pix_key TEXT NOT NULL DEFAULT '';
+CREATE INDEX IF NOT EXISTS idx_payees_pix_key ON payees (email, pix_key);
+
+UPDATE payees SET pix_key = COALESCE((
+  SELECT value FROM payee_aliases
+  WHERE payee_aliases.payee_id = payees.id AND kind = 'pix'
+  ORDER BY id
+  LIMIT 1
+), '');
+
+DROP INDEX IF EXISTS idx_payee_aliases_payee;
+DROP INDEX IF EXISTS idx_payee_aliases_value;
+DROP TABLE IF EXISTS payee_aliases;
+-- +goose StatementEnd
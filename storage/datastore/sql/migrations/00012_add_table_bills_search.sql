@@ -0,0 +1,44 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE VIRTUAL TABLE IF NOT EXISTS bills_search USING fts5(name, payee, tokenize = 'unicode61 remove_diacritics 2');
+
+INSERT INTO bills_search (rowid, name, payee)
+SELECT bills.id, bills.name, COALESCE(payees.name, '')
+FROM bills LEFT JOIN payees ON payees.id = bills.payee_id AND payees.deleted_at = 0
+WHERE bills.deleted_at = 0;
+
+CREATE TRIGGER IF NOT EXISTS bills_search_insert AFTER INSERT ON bills
+WHEN new.deleted_at = 0
+BEGIN
+  INSERT INTO bills_search (rowid, name, payee)
+  VALUES (new.id, new.name, COALESCE((SELECT name FROM payees WHERE id = new.payee_id AND deleted_at = 0), ''));
+END;
+
+CREATE TRIGGER IF NOT EXISTS bills_search_update AFTER UPDATE OF name, payee_id, deleted_at ON bills
+BEGIN
+  DELETE FROM bills_search WHERE rowid = old.id;
+  INSERT INTO bills_search (rowid, name, payee)
+  SELECT new.id, new.name, COALESCE((SELECT name FROM payees WHERE id = new.payee_id AND deleted_at = 0), '')
+  WHERE new.deleted_at = 0;
+END;
+
+CREATE TRIGGER IF NOT EXISTS bills_search_delete AFTER DELETE ON bills
+BEGIN
+  DELETE FROM bills_search WHERE rowid = old.id;
+END;
+
+CREATE TRIGGER IF NOT EXISTS payees_search_update AFTER UPDATE OF name, deleted_at ON payees
+BEGIN
+  UPDATE bills_search SET payee = CASE WHEN new.deleted_at = 0 THEN new.name ELSE '' END
+  WHERE rowid IN (SELECT id FROM bills WHERE payee_id = new.id AND deleted_at = 0);
+END;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TRIGGER IF EXISTS payees_search_update;
+DROP TRIGGER IF EXISTS bills_search_delete;
+DROP TRIGGER IF EXISTS bills_search_update;
+DROP TRIGGER IF EXISTS bills_search_insert;
+DROP TABLE IF EXISTS bills_search;
+-- +goose StatementEnd
@@ -0,0 +1,19 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS saved_searches (
+  id          INTEGER NOT NULL PRIMARY KEY AUTOINCREMENT,
+  email       TEXT    NOT NULL,
+  name        TEXT    NOT NULL,
+  query       TEXT    NOT NULL,
+  pinned      BOOLEAN NOT NULL DEFAULT FALSE,
+  created_at  INTEGER NOT NULL DEFAULT (unixepoch('subsecond') * 1000)
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_saved_searches_name ON saved_searches (email, name);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_saved_searches_name;
+DROP TABLE IF EXISTS saved_searches;
+-- +goose StatementEnd
@@ -0,0 +1,23 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS access_tokens (
+  id            INTEGER NOT NULL PRIMARY KEY AUTOINCREMENT,
+  email         TEXT    NOT NULL,
+  name          TEXT    NOT NULL,
+  hash          TEXT    NOT NULL,
+  scope         TEXT    NOT NULL,
+  expires_at    INTEGER NOT NULL,
+  last_used_at  INTEGER NOT NULL DEFAULT 0,
+  created_at    INTEGER NOT NULL DEFAULT (unixepoch('subsecond') * 1000)
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_access_tokens_hash ON access_tokens (hash);
+CREATE INDEX IF NOT EXISTS idx_access_tokens_email ON access_tokens (email);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_access_tokens_email;
+DROP INDEX IF EXISTS idx_access_tokens_hash;
+DROP TABLE IF EXISTS access_tokens;
+-- +goose StatementEnd
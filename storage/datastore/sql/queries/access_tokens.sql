@@ -0,0 +1,37 @@
+-- name: CreateAccessToken :one
+INSERT INTO access_tokens (email, name, hash, scope, expires_at)
+                   VALUES (?    , ?   , ?   , ?    , ?)
+RETURNING *;
+
+-- name: GetAccessTokenNotExpired :one
+SELECT * FROM access_tokens
+WHERE hash = ? AND expires_at > ?;
+
+-- name: ListAccessTokensByEmail :many
+SELECT * FROM access_tokens
+WHERE email = ?
+ORDER BY created_at DESC, id DESC;
+
+-- name: CountAccessTokensByEmail :one
+SELECT COUNT(*) FROM access_tokens
+WHERE email = ?;
+
+-- name: TouchAccessToken :exec
+UPDATE access_tokens SET last_used_at = ?
+WHERE id = ?;
+
+-- name: DeleteAccessToken :execrows
+DELETE FROM access_tokens
+WHERE id = ? AND email = ?;
+
+-- name: DeleteAccessTokensByEmail :exec
+DELETE FROM access_tokens
+WHERE email = ?;
+
+-- name: UpdateAccessTokensEmail :exec
+UPDATE access_tokens SET email = sqlc.arg(new_email)
+WHERE email = sqlc.arg(old_email);
+
+-- name: PurgeAccessTokensOfDeletedUsers :exec
+DELETE FROM access_tokens
+WHERE email IN (SELECT email FROM users WHERE deleted_at > 0 AND deleted_at <= ?);
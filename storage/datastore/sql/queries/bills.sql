@@ -0,0 +1,79 @@
+-- name: CreateBill :one
+INSERT INTO bills (email, name, amount, currency, due_on, remind_days, barcode, pix, payee_id)
+           VALUES (?    , ?   , ?     , ?       , ?     , ?          , ?      , ?  , ?)
+RETURNING *;
+
+-- name: ImportBill :exec
+INSERT INTO bills (email, name, amount, currency, due_on, remind_days, reminded_at, paid_at, created_at, barcode, pix, payee_id)
+           VALUES (?    , ?   , ?     , ?       , ?     , ?          , ?          , ?      , ?         , ?      , ?  , ?);
+
+-- name: GetBill :one
+SELECT * FROM bills
+WHERE id = ? AND email = ? AND deleted_at = 0;
+
+-- name: ListBillsByEmail :many
+SELECT * FROM bills
+WHERE email = ? AND deleted_at = 0
+ORDER BY paid_at > 0, due_on, id;
+
+-- name: ListBillsToRemind :many
+SELECT * FROM bills
+WHERE paid_at = 0 AND reminded_at = 0 AND deleted_at = 0 AND due_on <= ?
+  AND email IN (SELECT email FROM users WHERE deleted_at = 0 AND disabled_at = 0)
+ORDER BY due_on, id;
+
+-- name: SetBillReminded :exec
+UPDATE bills SET reminded_at = ?, updated_at = CAST(unixepoch('subsecond') * 1000 AS INTEGER)
+WHERE id = ?;
+
+-- name: SetBillPaid :execrows
+UPDATE bills SET paid_at = ?, updated_at = CAST(unixepoch('subsecond') * 1000 AS INTEGER)
+WHERE id = ? AND email = ? AND paid_at = 0 AND deleted_at = 0;
+
+-- name: DeleteBill :execrows
+UPDATE bills SET deleted_at = CAST(unixepoch('subsecond') * 1000 AS INTEGER)
+WHERE id = ? AND email = ? AND deleted_at = 0;
+
+-- name: UpdateBillsEmail :exec
+UPDATE bills SET email = sqlc.arg(new_email)
+WHERE email = sqlc.arg(old_email);
+
+-- name: PurgeBillsOfDeletedUsers :exec
+DELETE FROM bills
+WHERE email IN (SELECT email FROM users WHERE deleted_at > 0 AND deleted_at <= ?);
+
+-- name: ListBillsWithoutPayee :many
+SELECT * FROM bills
+WHERE email = ? AND payee_id = 0 AND deleted_at = 0
+ORDER BY id;
+
+-- name: SetBillPayee :exec
+UPDATE bills SET payee_id = ?, updated_at = CAST(unixepoch('subsecond') * 1000 AS INTEGER)
+WHERE id = ?;
+
+-- name: MoveBillsPayee :exec
+UPDATE bills SET payee_id = sqlc.arg(new_payee_id), updated_at = CAST(unixepoch('subsecond') * 1000 AS INTEGER)
+WHERE email = sqlc.arg(email) AND payee_id = sqlc.arg(old_payee_id);
+
+-- name: ListPaidBills :many
+SELECT * FROM bills
+WHERE email = sqlc.arg(email) AND currency = sqlc.arg(currency)
+  AND paid_at >= sqlc.arg(paid_from) AND paid_at < sqlc.arg(paid_until) AND deleted_at = 0
+ORDER BY paid_at, id;
+
+-- name: ListUnpaidBills :many
+SELECT * FROM bills
+WHERE email = sqlc.arg(email) AND currency = sqlc.arg(currency)
+  AND paid_at = 0 AND due_on <= sqlc.arg(due_until) AND deleted_at = 0
+ORDER BY due_on, id;
+
+-- name: SearchBills :many
+SELECT * FROM bills
+WHERE email = sqlc.arg(email) AND deleted_at = 0
+  AND (CAST(sqlc.arg(match) AS TEXT) = '' OR id IN (SELECT rowid FROM bills_search WHERE bills_search MATCH sqlc.arg(match)))
+  AND (CAST(sqlc.arg(currency) AS TEXT) = '' OR currency = sqlc.arg(currency))
+  AND amount >= sqlc.arg(min_amount) AND amount <= sqlc.arg(max_amount)
+  AND due_on >= sqlc.arg(due_from) AND due_on <= sqlc.arg(due_until)
+  AND paid_at >= sqlc.arg(paid_from) AND paid_at <= sqlc.arg(paid_until)
+ORDER BY due_on DESC, id DESC
+LIMIT sqlc.arg(max_results);
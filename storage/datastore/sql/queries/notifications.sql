@@ -0,0 +1,30 @@
+-- name: CreateNotification :exec
+INSERT INTO notifications (email, message, link)
+                   VALUES (?    , ?      , ?);
+
+-- name: ListNotifications :many
+SELECT * FROM notifications
+WHERE email = ?
+ORDER BY id DESC
+LIMIT ?;
+
+-- name: ListNotificationsByEmail :many
+SELECT * FROM notifications
+WHERE email = ?
+ORDER BY id;
+
+-- name: CountUnreadNotifications :one
+SELECT COUNT(*) FROM notifications
+WHERE email = ? AND read_at = 0;
+
+-- name: MarkNotificationsRead :exec
+UPDATE notifications SET read_at = ?
+WHERE email = ? AND read_at = 0;
+
+-- name: UpdateNotificationsEmail :exec
+UPDATE notifications SET email = sqlc.arg(new_email)
+WHERE email = sqlc.arg(old_email);
+
+-- name: PurgeNotificationsOfDeletedUsers :exec
+DELETE FROM notifications
+WHERE email IN (SELECT email FROM users WHERE deleted_at > 0 AND deleted_at <= ?);
@@ -0,0 +1,35 @@
+-- name: CreateOutboxMessage :exec
+INSERT INTO outbox (template, subject, recipient, data, next_attempt_at)
+            VALUES (?       , ?      , ?        , ?   , ?);
+
+-- name: ListPendingOutboxMessages :many
+SELECT * FROM outbox
+WHERE sent_at = 0 AND failed_at = 0 AND next_attempt_at <= ?
+ORDER BY id
+LIMIT ?;
+
+-- name: ListOutboxMessages :many
+SELECT * FROM outbox
+ORDER BY id DESC
+LIMIT ?;
+
+-- name: ListOutboxMessagesByRecipient :many
+SELECT * FROM outbox
+WHERE recipient = ?
+ORDER BY id;
+
+-- name: SetOutboxMessageSent :exec
+UPDATE outbox SET attempts = attempts + 1, last_error = '', sent_at = ?, updated_at = CAST(unixepoch('subsecond') * 1000 AS INTEGER)
+WHERE id = ?;
+
+-- name: SetOutboxMessageAttempt :exec
+UPDATE outbox SET attempts = ?, last_error = ?, next_attempt_at = ?, failed_at = ?, updated_at = CAST(unixepoch('subsecond') * 1000 AS INTEGER)
+WHERE id = ?;
+
+-- name: RetryOutboxMessage :execrows
+UPDATE outbox SET attempts = 0, last_error = '', next_attempt_at = ?, failed_at = 0, updated_at = CAST(unixepoch('subsecond') * 1000 AS INTEGER)
+WHERE id = ? AND sent_at = 0;
+
+-- name: PurgeOutboxMessages :exec
+DELETE FROM outbox
+WHERE (sent_at > 0 AND sent_at <= sqlc.arg(before)) OR (failed_at > 0 AND failed_at <= sqlc.arg(before));
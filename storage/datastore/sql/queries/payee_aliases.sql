@@ -0,0 +1,33 @@
+-- name: CreatePayeeAlias :execrows
+INSERT INTO payee_aliases (email, payee_id, kind, value)
+                   VALUES (?    , ?       , ?   , ?)
+ON CONFLICT DO NOTHING;
+
+-- name: GetPayeeAlias :one
+SELECT * FROM payee_aliases
+WHERE email = ? AND kind = ? AND value = ?;
+
+-- name: ListPayeeAliasesByEmail :many
+SELECT * FROM payee_aliases
+WHERE email = ?
+ORDER BY kind, value;
+
+-- name: DeletePayeeAlias :execrows
+DELETE FROM payee_aliases
+WHERE id = ? AND email = ?;
+
+-- name: DeletePayeeAliases :exec
+DELETE FROM payee_aliases
+WHERE payee_id = ? AND email = ?;
+
+-- name: MovePayeeAliases :exec
+UPDATE payee_aliases SET payee_id = sqlc.arg(new_payee_id)
+WHERE email = sqlc.arg(email) AND payee_id = sqlc.arg(old_payee_id);
+
+-- name: UpdatePayeeAliasesEmail :exec
+UPDATE payee_aliases SET email = sqlc.arg(new_email)
+WHERE email = sqlc.arg(old_email);
+
+-- name: PurgePayeeAliasesOfDeletedUsers :exec
+DELETE FROM payee_aliases
+WHERE email IN (SELECT email FROM users WHERE deleted_at > 0 AND deleted_at <= ?);
@@ -0,0 +1,34 @@
+-- name: CreatePayee :one
+INSERT INTO payees (email, name, city, tax_id)
+            VALUES (?    , ?   , ?   , ?)
+RETURNING *;
+
+-- name: ImportPayee :one
+INSERT INTO payees (email, name, city, tax_id, created_at)
+            VALUES (?    , ?   , ?   , ?     , ?)
+RETURNING *;
+
+-- name: GetPayee :one
+SELECT * FROM payees
+WHERE id = ? AND email = ? AND deleted_at = 0;
+
+-- name: ListPayeesByEmail :many
+SELECT * FROM payees
+WHERE email = ? AND deleted_at = 0
+ORDER BY name, id;
+
+-- name: UpdatePayee :execrows
+UPDATE payees SET name = ?, tax_id = ?, updated_at = CAST(unixepoch('subsecond') * 1000 AS INTEGER)
+WHERE id = ? AND email = ? AND deleted_at = 0;
+
+-- name: DeletePayee :execrows
+UPDATE payees SET deleted_at = CAST(unixepoch('subsecond') * 1000 AS INTEGER)
+WHERE id = ? AND email = ? AND deleted_at = 0;
+
+-- name: UpdatePayeesEmail :exec
+UPDATE payees SET email = sqlc.arg(new_email)
+WHERE email = sqlc.arg(old_email);
+
+-- name: PurgePayeesOfDeletedUsers :exec
+DELETE FROM payees
+WHERE email IN (SELECT email FROM users WHERE deleted_at > 0 AND deleted_at <= ?);
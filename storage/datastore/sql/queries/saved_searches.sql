@@ -0,0 +1,29 @@
+-- name: CreateSavedSearch :execrows
+INSERT INTO saved_searches (email, name, query, pinned)
+                    VALUES (?    , ?   , ?    , ?)
+ON CONFLICT DO NOTHING;
+
+-- name: ListSavedSearchesByEmail :many
+SELECT * FROM saved_searches
+WHERE email = ?
+ORDER BY name;
+
+-- name: CountSavedSearchesByEmail :one
+SELECT COUNT(*) FROM saved_searches
+WHERE email = ?;
+
+-- name: SetSavedSearchPinned :execrows
+UPDATE saved_searches SET pinned = ?
+WHERE id = ? AND email = ?;
+
+-- name: DeleteSavedSearch :execrows
+DELETE FROM saved_searches
+WHERE id = ? AND email = ?;
+
+-- name: UpdateSavedSearchesEmail :exec
+UPDATE saved_searches SET email = sqlc.arg(new_email)
+WHERE email = sqlc.arg(old_email);
+
+-- name: PurgeSavedSearchesOfDeletedUsers :exec
+DELETE FROM saved_searches
+WHERE email IN (SELECT email FROM users WHERE deleted_at > 0 AND deleted_at <= ?);
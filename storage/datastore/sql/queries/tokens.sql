@@ -1,6 +1,10 @@
 -- name: CreateToken :exec
-INSERT INTO tokens (token, type, email, expires_at)
-            VALUES (?    , ?   , ?    , ?);
+INSERT INTO tokens (token, type, email, new_email, expires_at)
+            VALUES (?    , ?   , ?    , ?        , ?);
+
+-- name: CountActiveTokensByEmail :one
+SELECT COUNT(*) FROM tokens
+WHERE email = ? AND expires_at >= ? AND deleted_at = 0;
 
 -- name: DeleteExpiredTokens :exec
 UPDATE tokens SET deleted_at = CAST(unixepoch('subsecond') * 1000 AS INTEGER)
@@ -14,10 +18,56 @@ WHERE email = ? AND type = 'SIGNUP';
 UPDATE tokens SET deleted_at = CAST(unixepoch('subsecond') * 1000 AS INTEGER)
 WHERE email = ? AND type = 'PASSWORD';
 
+-- name: DeleteEmailChangeTokensByEmail :exec
+UPDATE tokens SET deleted_at = CAST(unixepoch('subsecond') * 1000 AS INTEGER)
+WHERE email = ? AND type IN ('EMAIL_CHANGE', 'EMAIL_CANCEL');
+
+-- name: DeleteTokensByEmail :exec
+UPDATE tokens SET deleted_at = CAST(unixepoch('subsecond') * 1000 AS INTEGER)
+WHERE email = ? AND deleted_at = 0;
+
 -- name: GetSignupTokenNotExpired :one
 SELECT * FROM tokens
 WHERE token = ? AND type = 'SIGNUP' AND expires_at >= ? AND deleted_at = 0;
 
 -- name: GetPasswordTokenNotExpired :one
 SELECT * FROM tokens
-WHERE token = ? AND type = 'PASSWORD' AND expires_at >= ? AND deleted_at = 0;
\ No newline at end of file
+WHERE token = ? AND type = 'PASSWORD' AND expires_at >= ? AND deleted_at = 0;
+
+-- name: GetEmailChangeTokenNotExpired :one
+SELECT * FROM tokens
+WHERE token = ? AND type = 'EMAIL_CHANGE' AND expires_at >= ? AND deleted_at = 0;
+
+-- name: GetEmailCancelTokenNotExpired :one
+SELECT * FROM tokens
+WHERE token = ? AND type = 'EMAIL_CANCEL' AND expires_at >= ? AND deleted_at = 0;
+
+-- name: GetExportTokenNotExpired :one
+SELECT * FROM tokens
+WHERE token = ? AND type = 'EXPORT' AND email = ? AND expires_at >= ? AND deleted_at = 0;
+
+-- name: ListInviteTokensNotExpired :many
+SELECT * FROM tokens
+WHERE type = 'INVITE' AND expires_at >= ? AND deleted_at = 0
+ORDER BY expires_at;
+
+-- name: UseInviteToken :execrows
+UPDATE tokens SET deleted_at = CAST(unixepoch('subsecond') * 1000 AS INTEGER)
+WHERE token = ? AND type = 'INVITE' AND (email = '' OR email = ?) AND expires_at >= ? AND deleted_at = 0;
+
+-- name: DeleteInviteToken :exec
+UPDATE tokens SET deleted_at = CAST(unixepoch('subsecond') * 1000 AS INTEGER)
+WHERE token = ? AND type = 'INVITE';
+
+-- name: ListTokensByEmail :many
+SELECT * FROM tokens
+WHERE email = ?
+ORDER BY expires_at;
+
+-- name: PurgeTokensOfDeletedUsers :exec
+DELETE FROM tokens
+WHERE email IN (SELECT email FROM users WHERE deleted_at > 0 AND deleted_at <= ?);
+
+-- name: UpdateTokensEmail :exec
+UPDATE tokens SET email = sqlc.arg(new_email)
+WHERE email = sqlc.arg(old_email);
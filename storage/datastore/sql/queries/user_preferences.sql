@@ -0,0 +1,23 @@
+-- name: GetUserPreferences :one
+SELECT * FROM user_preferences
+WHERE email = ?;
+
+-- name: UpsertUserPreferences :one
+INSERT INTO user_preferences (email, locale, timezone, currency, first_day_of_week, date_format)
+                      VALUES (?    , ?     , ?       , ?       , ?                , ?)
+ON CONFLICT (email) DO UPDATE
+SET locale = excluded.locale,
+    timezone = excluded.timezone,
+    currency = excluded.currency,
+    first_day_of_week = excluded.first_day_of_week,
+    date_format = excluded.date_format,
+    updated_at = CAST(unixepoch('subsecond') * 1000 AS INTEGER)
+RETURNING *;
+
+-- name: UpdateUserPreferencesEmail :exec
+UPDATE user_preferences SET email = sqlc.arg(new_email)
+WHERE email = sqlc.arg(old_email);
+
+-- name: PurgeUserPreferencesOfDeletedUsers :exec
+DELETE FROM user_preferences
+WHERE email IN (SELECT email FROM users WHERE deleted_at > 0 AND deleted_at <= ?);
\ No newline at end of file
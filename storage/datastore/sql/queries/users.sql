@@ -6,10 +6,19 @@ INSERT INTO users (email, name, password, salt)
 UPDATE users SET updated_at = CAST(unixepoch('subsecond') * 1000 as int), deleted_at = CAST(unixepoch('subsecond') * 1000 AS INTEGER)
 WHERE email = ?;
 
+-- name: PurgeDeletedUsers :exec
+DELETE FROM users
+WHERE deleted_at > 0 AND deleted_at <= ?;
+
 -- name: UpdateUser :exec
 UPDATE users SET email = ?, name = ?, updated_at = CAST(unixepoch('subsecond') * 1000 AS INTEGER)
 WHERE email = ?;
 
+-- name: UpdateUserName :one
+UPDATE users SET name = ?, updated_at = CAST(unixepoch('subsecond') * 1000 AS INTEGER)
+WHERE email = ? AND deleted_at = 0
+RETURNING *;
+
 -- name: UpdateUserPassword :one
 UPDATE users SET password = ?, salt = ?, updated_at = CAST(unixepoch('subsecond') * 1000 AS INTEGER)
 WHERE email = ?
@@ -34,5 +43,13 @@ WHERE email = ? AND deleted_at > 0;
 
 -- name: GetAllUsers :many
 SELECT * FROM users
-WHERE email = ? AND deleted_at > 0
-ORDER BY name;
\ No newline at end of file
+WHERE deleted_at = 0 AND (email LIKE sqlc.arg(search) OR name LIKE sqlc.arg(search))
+ORDER BY name;
+
+-- name: SetUserIsAdmin :execrows
+UPDATE users SET is_admin = ?, updated_at = CAST(unixepoch('subsecond') * 1000 AS INTEGER)
+WHERE email = ? AND deleted_at = 0;
+
+-- name: SetUserDisabledAt :execrows
+UPDATE users SET disabled_at = ?, updated_at = CAST(unixepoch('subsecond') * 1000 AS INTEGER)
+WHERE email = ? AND deleted_at = 0;
\ No newline at end of file
@@ -9,15 +9,33 @@ import (
 	"context"
 )
 
+const countActiveTokensByEmail = `-- name: CountActiveTokensByEmail :one
+SELECT COUNT(*) FROM tokens
+WHERE email = ? AND expires_at >= ? AND deleted_at = 0
+`
+
+type CountActiveTokensByEmailParams struct {
+	Email     string
+	ExpiresAt int64
+}
+
+func (q *Queries) CountActiveTokensByEmail(ctx context.Context, arg CountActiveTokensByEmailParams) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countActiveTokensByEmail, arg.Email, arg.ExpiresAt)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
 const createToken = `-- name: CreateToken :exec
-INSERT INTO tokens (token, type, email, expires_at)
-            VALUES (?    , ?   , ?    , ?)
+INSERT INTO tokens (token, type, email, new_email, expires_at)
+            VALUES (?    , ?   , ?    , ?        , ?)
 `
 
 type CreateTokenParams struct {
 	Token     string
 	Type      string
 	Email     string
+	NewEmail  string
 	ExpiresAt int64
 }
 
@@ -26,11 +44,22 @@ func (q *Queries) CreateToken(ctx context.Context, arg CreateTokenParams) error
 		arg.Token,
 		arg.Type,
 		arg.Email,
+		arg.NewEmail,
 		arg.ExpiresAt,
 	)
 	return err
 }
 
+const deleteEmailChangeTokensByEmail = `-- name: DeleteEmailChangeTokensByEmail :exec
+UPDATE tokens SET deleted_at = CAST(unixepoch('subsecond') * 1000 AS INTEGER)
+WHERE email = ? AND type IN ('EMAIL_CHANGE', 'EMAIL_CANCEL')
+`
+
+func (q *Queries) DeleteEmailChangeTokensByEmail(ctx context.Context, email string) error {
+	_, err := q.db.ExecContext(ctx, deleteEmailChangeTokensByEmail, email)
+	return err
+}
+
 const deleteExpiredTokens = `-- name: DeleteExpiredTokens :exec
 UPDATE tokens SET deleted_at = CAST(unixepoch('subsecond') * 1000 AS INTEGER)
 WHERE expires_at <= ?
@@ -41,6 +70,16 @@ func (q *Queries) DeleteExpiredTokens(ctx context.Context, expiresAt int64) erro
 	return err
 }
 
+const deleteInviteToken = `-- name: DeleteInviteToken :exec
+UPDATE tokens SET deleted_at = CAST(unixepoch('subsecond') * 1000 AS INTEGER)
+WHERE token = ? AND type = 'INVITE'
+`
+
+func (q *Queries) DeleteInviteToken(ctx context.Context, token string) error {
+	_, err := q.db.ExecContext(ctx, deleteInviteToken, token)
+	return err
+}
+
 const deletePasswordTokensByEmail = `-- name: DeletePasswordTokensByEmail :exec
 UPDATE tokens SET deleted_at = CAST(unixepoch('subsecond') * 1000 AS INTEGER)
 WHERE email = ? AND type = 'PASSWORD'
@@ -61,8 +100,91 @@ func (q *Queries) DeleteSignupTokensByEmail(ctx context.Context, email string) e
 	return err
 }
 
+const deleteTokensByEmail = `-- name: DeleteTokensByEmail :exec
+UPDATE tokens SET deleted_at = CAST(unixepoch('subsecond') * 1000 AS INTEGER)
+WHERE email = ? AND deleted_at = 0
+`
+
+func (q *Queries) DeleteTokensByEmail(ctx context.Context, email string) error {
+	_, err := q.db.ExecContext(ctx, deleteTokensByEmail, email)
+	return err
+}
+
+const getEmailCancelTokenNotExpired = `-- name: GetEmailCancelTokenNotExpired :one
+SELECT token, type, email, expires_at, deleted_at, new_email FROM tokens
+WHERE token = ? AND type = 'EMAIL_CANCEL' AND expires_at >= ? AND deleted_at = 0
+`
+
+type GetEmailCancelTokenNotExpiredParams struct {
+	Token     string
+	ExpiresAt int64
+}
+
+func (q *Queries) GetEmailCancelTokenNotExpired(ctx context.Context, arg GetEmailCancelTokenNotExpiredParams) (Token, error) {
+	row := q.db.QueryRowContext(ctx, getEmailCancelTokenNotExpired, arg.Token, arg.ExpiresAt)
+	var i Token
+	err := row.Scan(
+		&i.Token,
+		&i.Type,
+		&i.Email,
+		&i.ExpiresAt,
+		&i.DeletedAt,
+		&i.NewEmail,
+	)
+	return i, err
+}
+
+const getEmailChangeTokenNotExpired = `-- name: GetEmailChangeTokenNotExpired :one
+SELECT token, type, email, expires_at, deleted_at, new_email FROM tokens
+WHERE token = ? AND type = 'EMAIL_CHANGE' AND expires_at >= ? AND deleted_at = 0
+`
+
+type GetEmailChangeTokenNotExpiredParams struct {
+	Token     string
+	ExpiresAt int64
+}
+
+func (q *Queries) GetEmailChangeTokenNotExpired(ctx context.Context, arg GetEmailChangeTokenNotExpiredParams) (Token, error) {
+	row := q.db.QueryRowContext(ctx, getEmailChangeTokenNotExpired, arg.Token, arg.ExpiresAt)
+	var i Token
+	err := row.Scan(
+		&i.Token,
+		&i.Type,
+		&i.Email,
+		&i.ExpiresAt,
+		&i.DeletedAt,
+		&i.NewEmail,
+	)
+	return i, err
+}
+
+const getExportTokenNotExpired = `-- name: GetExportTokenNotExpired :one
+SELECT token, type, email, expires_at, deleted_at, new_email FROM tokens
+WHERE token = ? AND type = 'EXPORT' AND email = ? AND expires_at >= ? AND deleted_at = 0
+`
+
+type GetExportTokenNotExpiredParams struct {
+	Token     string
+	Email     string
+	ExpiresAt int64
+}
+
+func (q *Queries) GetExportTokenNotExpired(ctx context.Context, arg GetExportTokenNotExpiredParams) (Token, error) {
+	row := q.db.QueryRowContext(ctx, getExportTokenNotExpired, arg.Token, arg.Email, arg.ExpiresAt)
+	var i Token
+	err := row.Scan(
+		&i.Token,
+		&i.Type,
+		&i.Email,
+		&i.ExpiresAt,
+		&i.DeletedAt,
+		&i.NewEmail,
+	)
+	return i, err
+}
+
 const getPasswordTokenNotExpired = `-- name: GetPasswordTokenNotExpired :one
-SELECT token, type, email, expires_at, deleted_at FROM tokens
+SELECT token, type, email, expires_at, deleted_at, new_email FROM tokens
 WHERE token = ? AND type = 'PASSWORD' AND expires_at >= ? AND deleted_at = 0
 `
 
@@ -80,12 +202,13 @@ func (q *Queries) GetPasswordTokenNotExpired(ctx context.Context, arg GetPasswor
 		&i.Email,
 		&i.ExpiresAt,
 		&i.DeletedAt,
+		&i.NewEmail,
 	)
 	return i, err
 }
 
 const getSignupTokenNotExpired = `-- name: GetSignupTokenNotExpired :one
-SELECT token, type, email, expires_at, deleted_at FROM tokens
+SELECT token, type, email, expires_at, deleted_at, new_email FROM tokens
 WHERE token = ? AND type = 'SIGNUP' AND expires_at >= ? AND deleted_at = 0
 `
 
@@ -103,6 +226,123 @@ func (q *Queries) GetSignupTokenNotExpired(ctx context.Context, arg GetSignupTok
 		&i.Email,
 		&i.ExpiresAt,
 		&i.DeletedAt,
+		&i.NewEmail,
 	)
 	return i, err
 }
+
+const listInviteTokensNotExpired = `-- name: ListInviteTokensNotExpired :many
+SELECT token, type, email, expires_at, deleted_at, new_email FROM tokens
+WHERE type = 'INVITE' AND expires_at >= ? AND deleted_at = 0
+ORDER BY expires_at
+`
+
+func (q *Queries) ListInviteTokensNotExpired(ctx context.Context, expiresAt int64) ([]Token, error) {
+	rows, err := q.db.QueryContext(ctx, listInviteTokensNotExpired, expiresAt)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Token
+	for rows.Next() {
+		var i Token
+		if err := rows.Scan(
+			&i.Token,
+			&i.Type,
+			&i.Email,
+			&i.ExpiresAt,
+			&i.DeletedAt,
+			&i.NewEmail,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listTokensByEmail = `-- name: ListTokensByEmail :many
+SELECT token, type, email, expires_at, deleted_at, new_email FROM tokens
+WHERE email = ?
+ORDER BY expires_at
+`
+
+func (q *Queries) ListTokensByEmail(ctx context.Context, email string) ([]Token, error) {
+	rows, err := q.db.QueryContext(ctx, listTokensByEmail, email)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Token
+	for rows.Next() {
+		var i Token
+		if err := rows.Scan(
+			&i.Token,
+			&i.Type,
+			&i.Email,
+			&i.ExpiresAt,
+			&i.DeletedAt,
+			&i.NewEmail,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const purgeTokensOfDeletedUsers = `-- name: PurgeTokensOfDeletedUsers :exec
+DELETE FROM tokens
+WHERE email IN (SELECT email FROM users WHERE deleted_at > 0 AND deleted_at <= ?)
+`
+
+func (q *Queries) PurgeTokensOfDeletedUsers(ctx context.Context, deletedAt int64) error {
+	_, err := q.db.ExecContext(ctx, purgeTokensOfDeletedUsers, deletedAt)
+	return err
+}
+
+const updateTokensEmail = `-- name: UpdateTokensEmail :exec
+UPDATE tokens SET email = ?1
+WHERE email = ?2
+`
+
+type UpdateTokensEmailParams struct {
+	NewEmail string
+	OldEmail string
+}
+
+func (q *Queries) UpdateTokensEmail(ctx context.Context, arg UpdateTokensEmailParams) error {
+	_, err := q.db.ExecContext(ctx, updateTokensEmail, arg.NewEmail, arg.OldEmail)
+	return err
+}
+
+const useInviteToken = `-- name: UseInviteToken :execrows
+UPDATE tokens SET deleted_at = CAST(unixepoch('subsecond') * 1000 AS INTEGER)
+WHERE token = ? AND type = 'INVITE' AND (email = '' OR email = ?) AND expires_at >= ? AND deleted_at = 0
+`
+
+type UseInviteTokenParams struct {
+	Token     string
+	Email     string
+	ExpiresAt int64
+}
+
+func (q *Queries) UseInviteToken(ctx context.Context, arg UseInviteTokenParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, useInviteToken, arg.Token, arg.Email, arg.ExpiresAt)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
@@ -42,13 +42,13 @@ func (q *Queries) DeleteUser(ctx context.Context, email string) error {
 }
 
 const getAllUsers = `-- name: GetAllUsers :many
-SELECT email, name, password, salt, created_at, updated_at, verified_at, deleted_at FROM users
-WHERE email = ? AND deleted_at > 0
+SELECT email, name, password, salt, created_at, updated_at, verified_at, deleted_at, is_admin, disabled_at FROM users
+WHERE deleted_at = 0 AND (email LIKE ?1 OR name LIKE ?1)
 ORDER BY name
 `
 
-func (q *Queries) GetAllUsers(ctx context.Context, email string) ([]User, error) {
-	rows, err := q.db.QueryContext(ctx, getAllUsers, email)
+func (q *Queries) GetAllUsers(ctx context.Context, search string) ([]User, error) {
+	rows, err := q.db.QueryContext(ctx, getAllUsers, search)
 	if err != nil {
 		return nil, err
 	}
@@ -65,6 +65,8 @@ func (q *Queries) GetAllUsers(ctx context.Context, email string) ([]User, error)
 			&i.UpdatedAt,
 			&i.VerifiedAt,
 			&i.DeletedAt,
+			&i.IsAdmin,
+			&i.DisabledAt,
 		); err != nil {
 			return nil, err
 		}
@@ -80,7 +82,7 @@ func (q *Queries) GetAllUsers(ctx context.Context, email string) ([]User, error)
 }
 
 const getUser = `-- name: GetUser :one
-SELECT  email, name, password, salt, created_at, updated_at, verified_at, deleted_at FROM users
+SELECT  email, name, password, salt, created_at, updated_at, verified_at, deleted_at, is_admin, disabled_at FROM users
 WHERE email = ? AND deleted_at = 0
 `
 
@@ -96,6 +98,8 @@ func (q *Queries) GetUser(ctx context.Context, email string) (User, error) {
 		&i.UpdatedAt,
 		&i.VerifiedAt,
 		&i.DeletedAt,
+		&i.IsAdmin,
+		&i.DisabledAt,
 	)
 	return i, err
 }
@@ -124,10 +128,56 @@ func (q *Queries) GetUserIsVerified(ctx context.Context, email string) (int64, e
 	return column_1, err
 }
 
+const purgeDeletedUsers = `-- name: PurgeDeletedUsers :exec
+DELETE FROM users
+WHERE deleted_at > 0 AND deleted_at <= ?
+`
+
+func (q *Queries) PurgeDeletedUsers(ctx context.Context, deletedAt int64) error {
+	_, err := q.db.ExecContext(ctx, purgeDeletedUsers, deletedAt)
+	return err
+}
+
+const setUserDisabledAt = `-- name: SetUserDisabledAt :execrows
+UPDATE users SET disabled_at = ?, updated_at = CAST(unixepoch('subsecond') * 1000 AS INTEGER)
+WHERE email = ? AND deleted_at = 0
+`
+
+type SetUserDisabledAtParams struct {
+	DisabledAt int64
+	Email      string
+}
+
+func (q *Queries) SetUserDisabledAt(ctx context.Context, arg SetUserDisabledAtParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, setUserDisabledAt, arg.DisabledAt, arg.Email)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const setUserIsAdmin = `-- name: SetUserIsAdmin :execrows
+UPDATE users SET is_admin = ?, updated_at = CAST(unixepoch('subsecond') * 1000 AS INTEGER)
+WHERE email = ? AND deleted_at = 0
+`
+
+type SetUserIsAdminParams struct {
+	IsAdmin bool
+	Email   string
+}
+
+func (q *Queries) SetUserIsAdmin(ctx context.Context, arg SetUserIsAdminParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, setUserIsAdmin, arg.IsAdmin, arg.Email)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
 const setUserIsVerified = `-- name: SetUserIsVerified :one
 UPDATE users SET verified_at = CAST(unixepoch('subsecond') * 1000 AS INTEGER), updated_at = CAST(unixepoch('subsecond') * 1000 AS INTEGER)
 WHERE email = ?
-RETURNING email, name, password, salt, created_at, updated_at, verified_at, deleted_at
+RETURNING email, name, password, salt, created_at, updated_at, verified_at, deleted_at, is_admin, disabled_at
 `
 
 func (q *Queries) SetUserIsVerified(ctx context.Context, email string) (User, error) {
@@ -142,6 +192,8 @@ func (q *Queries) SetUserIsVerified(ctx context.Context, email string) (User, er
 		&i.UpdatedAt,
 		&i.VerifiedAt,
 		&i.DeletedAt,
+		&i.IsAdmin,
+		&i.DisabledAt,
 	)
 	return i, err
 }
@@ -162,10 +214,39 @@ func (q *Queries) UpdateUser(ctx context.Context, arg UpdateUserParams) error {
 	return err
 }
 
+const updateUserName = `-- name: UpdateUserName :one
+UPDATE users SET name = ?, updated_at = CAST(unixepoch('subsecond') * 1000 AS INTEGER)
+WHERE email = ? AND deleted_at = 0
+RETURNING email, name, password, salt, created_at, updated_at, verified_at, deleted_at, is_admin, disabled_at
+`
+
+type UpdateUserNameParams struct {
+	Name  string
+	Email string
+}
+
+func (q *Queries) UpdateUserName(ctx context.Context, arg UpdateUserNameParams) (User, error) {
+	row := q.db.QueryRowContext(ctx, updateUserName, arg.Name, arg.Email)
+	var i User
+	err := row.Scan(
+		&i.Email,
+		&i.Name,
+		&i.Password,
+		&i.Salt,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.VerifiedAt,
+		&i.DeletedAt,
+		&i.IsAdmin,
+		&i.DisabledAt,
+	)
+	return i, err
+}
+
 const updateUserPassword = `-- name: UpdateUserPassword :one
 UPDATE users SET password = ?, salt = ?, updated_at = CAST(unixepoch('subsecond') * 1000 AS INTEGER)
 WHERE email = ?
-RETURNING email, name, password, salt, created_at, updated_at, verified_at, deleted_at
+RETURNING email, name, password, salt, created_at, updated_at, verified_at, deleted_at, is_admin, disabled_at
 `
 
 type UpdateUserPasswordParams struct {
@@ -186,6 +267,8 @@ func (q *Queries) UpdateUserPassword(ctx context.Context, arg UpdateUserPassword
 		&i.UpdatedAt,
 		&i.VerifiedAt,
 		&i.DeletedAt,
+		&i.IsAdmin,
+		&i.DisabledAt,
 	)
 	return i, err
 }